@@ -1,17 +1,26 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"io/fs"
+	"path/filepath"
 	"time"
 
 	"gorl/fw/core/assets"
+	"gorl/fw/core/clock"
 	"gorl/fw/core/gem"
+	inputevent "gorl/fw/core/input/input_event"
 	input "gorl/fw/core/input/input_handling"
 	"gorl/fw/core/logging"
 	"gorl/fw/core/render"
+	"gorl/fw/core/resources"
 	"gorl/fw/core/settings"
 	"gorl/fw/core/store"
 	"gorl/fw/modules/audio"
+	"gorl/fw/modules/event"
+	"gorl/fw/modules/scenes"
 	"gorl/fw/physics"
 	"gorl/game"
 
@@ -23,6 +32,13 @@ import (
 
 func main() {
 	// PRE-INIT
+	// input recording and replay, e.g. to reproduce bug reports
+	recordPath := flag.String("record", "", "record the input of the session to this file")
+	replayPath := flag.String("replay", "", "replay the input recorded in this file")
+	// event tracing, to see which events fired and which listeners ran
+	traceEventsPath := flag.String("trace-events", "", "trace the events of the session and dump them to this file")
+	flag.Parse()
+
 	go func() {
 		err := http.ListenAndServe("localhost:6969", nil)
 		if err != nil {
@@ -48,6 +64,19 @@ func main() {
 		logging.Warning("Settings loading unsuccessful, using fallback.")
 	}
 
+	// resources that are still tracked once everything is deinitialized were
+	// never released
+	defer resources.ReportLeaks()
+
+	if *traceEventsPath != "" {
+		event.EnableTracing(0)
+		defer func() {
+			if err := event.DumpTrace(*traceEventsPath); err != nil {
+				logging.Error("Failed to dump event trace: %v", err)
+			}
+		}()
+	}
+
 	// assets / packing
 	assets.UsePackfile()
 
@@ -112,10 +141,41 @@ func main() {
 	//scenes.RegisterScene("some_name", &uscenes.TemplateScene{})
 	//scenes.EnableScene("some_name")
 	//scenes.DisableScene("some_name")
+	//scenes.PushScene("some_name", scenes.Fade(0.5))
 
 	//rl.DisableCursor()
+
+	// recording and replay start before the game is initialized, so the
+	// random numbers it uses are reproduced as well.
+	if *replayPath != "" {
+		recording, err := input.LoadRecording(*replayPath)
+		if err != nil {
+			logging.Error("Failed to load input recording: %v", err)
+		} else {
+			input.StartReplay(recording)
+			logging.Info("Replaying input from %v.", *replayPath)
+		}
+	} else if *recordPath != "" {
+		input.StartRecording()
+		logging.Info("Recording input to %v.", *recordPath)
+		defer func() {
+			if err := input.SaveRecording(*recordPath, input.StopRecording()); err != nil {
+				logging.Error("Failed to save input recording: %v", err)
+			}
+		}()
+	}
+
 	game.Init()
 
+	// input bindings, loaded after the game registered its actions and
+	// contexts. The file is created when the player changes the bindings.
+	bindingsPath := filepath.Join(filepath.Dir(settings_path), inputevent.DefaultBindingsPath)
+	if err := inputevent.LoadBindings(bindingsPath); err == nil {
+		logging.Info("Input bindings loaded.")
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		logging.Warning("Failed to load input bindings, using defaults: %v", err)
+	}
+
 	// GAME LOOP
 	//rl.SetExitKey(rl.KeyEnd) // Set a key to exit the game
 	shouldExit := false
@@ -135,21 +195,36 @@ func main() {
 	// ==============================
 	for !shouldExit {
 		frameStart = time.Now()
+		clock.Tick()
+
+		// events posted by background work since the last frame, e.g. by
+		// asset loading, are delivered before the entities are updated.
+		event.DeliverQueued()
+		// scene transitions and loading
+		scenes.Update()
 
 		rl.BeginTextureMode(debugTexture)
 		rl.ClearBackground(rl.Blank)
-		shouldFixedUpdate := physics.Update()
-		drawables, inputReceivers := gem.Traverse(shouldFixedUpdate)
+		// run as many fixed steps as fit into the elapsed time, so physics and
+		// FixedUpdate keep up with real time at any frame rate.
+		for clock.StepFixed() {
+			physics.Step()
+			gem.FixedTraverse()
+		}
+		drawables, inputReceivers := gem.Traverse(false)
 		rl.EndTextureMode()
 
 		rl.BeginDrawing()
 
-		render.Draw(drawables)
+		drawn := render.Draw(drawables)
 
 		// input is processed at the end of the frame, because here we know in
 		// what order the entities were drawn, and can be sure whatever the
 		// user clicked was really visible at the front.
-		input.HandleInputEvents(inputReceivers)
+		input.HandleInputEvents(inputReceivers, drawn)
+
+		// apply the structural changes to the gem graph made during the frame
+		gem.Sync()
 
 		// Draw Debug Info
 		DrawDebugInfo(frameTime)
@@ -182,4 +257,7 @@ func DrawDebugInfo(frameTime time.Duration) {
 	//)
 	//gem.DebugDrawEntities(rl.NewVector2(10, 50), 12)
 	gem.DebugDrawHierarchy(rl.NewVector2(10, 50), 8)
+	if event.IsTracing() {
+		event.DebugDrawTrace(rl.NewVector2(float32(rl.GetScreenWidth())/2, 10), 8, 20)
+	}
 }
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	input "gorl/fw/core/input/input_handling"
+	"gorl/fw/core/logging"
+	"gorl/fw/core/settings"
+	"gorl/fw/headless"
+	"gorl/game"
+)
+
+// headless runs the game logic without a window, GPU or audio device, e.g. on
+// a server or in CI.
+func main() {
+	frames := flag.Int("frames", 600, "number of frames to run, 0 runs until the game quits")
+	frameTime := flag.Float64("frame-time", 1.0/60.0, "synthetic duration of a frame in seconds")
+	replayPath := flag.String("replay", "", "replay the input recorded in this file, e.g. with the game's -record flag")
+	flag.Parse()
+
+	// settings
+	settings_path := "settings.json"
+	err := settings.LoadSettings(settings_path)
+	if err != nil {
+		fmt.Println("Error loading settings:", err)
+		fmt.Println("Using fallback settings.")
+		settings.UseFallbackSettings()
+	}
+
+	// logging
+	logging.Init(settings.CurrentSettings().LogPath)
+	logging.Info("Logging initialized")
+
+	config := headless.Config{
+		Frames:    *frames,
+		FrameTime: float32(*frameTime),
+		Init:      game.Init,
+	}
+	if *replayPath != "" {
+		recording, err := input.LoadRecording(*replayPath)
+		if err != nil {
+			logging.Error("Failed to load input recording: %v", err)
+			return
+		}
+		config.Replay = recording
+		// run as long as the replay, unless the frames were set explicitly
+		frameFlagSet := false
+		flag.Visit(func(f *flag.Flag) { frameFlagSet = frameFlagSet || f.Name == "frames" })
+		if !frameFlagSet {
+			config.Frames = 0
+		}
+	}
+
+	result := headless.Run(config)
+	fmt.Printf("ran %d frames (%.2fs simulated, %d physics steps)\n",
+		result.Frames, result.Time, result.PhysicsSteps)
+}
@@ -3,12 +3,11 @@
 package animation
 
 import (
+	"gorl/fw/core/clock"
 	"gorl/fw/core/logging"
+	gmath "gorl/fw/core/math"
 	"math"
-	"math/rand"
 	"sort"
-
-	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
 // ============================================================================
@@ -73,7 +72,7 @@ func (a *Animation[T]) Play(loop bool, random_time_offset bool) {
 	a.isPlaying = true
 
 	if random_time_offset {
-		offs := rand.Float32() * a.duration
+		offs := gmath.RandRange(0, a.duration)
 		a.playTime += offs
 	}
 }
@@ -130,7 +129,7 @@ func (a *Animation[T]) Update() {
 	}
 
 	if a.isReversed {
-		a.playTime -= rl.GetFrameTime()
+		a.playTime -= clock.DeltaTime()
 		if a.playTime < 0 {
 			if a.isLooping {
 				a.playTime = a.duration
@@ -140,7 +139,7 @@ func (a *Animation[T]) Update() {
 			}
 		}
 	} else {
-		a.playTime += rl.GetFrameTime()
+		a.playTime += clock.DeltaTime()
 		if a.playTime > a.duration {
 			if a.isLooping {
 				a.playTime = 0
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"gorl/fw/core/logging"
+	"gorl/fw/core/resources"
 	"io"
 	"os"
 	"path/filepath"
@@ -37,70 +38,184 @@ func LoadFile(path string) (io.ReadCloser, error) {
 	return os.Open(path)
 }
 
-// LoadTexture loads a Texture2D.
-func LoadTexture(path string) (rl.Texture2D, error) {
-
-	// If using packfile, load from packfile.
+// ReadFile reads the whole file, either from the packfile or from disk.
+// Can be used as a drop-in replacement for os.ReadFile. Unlike the other
+// functions of the package, it is safe to call from any goroutine.
+func ReadFile(path string) ([]byte, error) {
 	if usingPackfile {
-		data, err := packfile.GetAssetBytes(path)
-		if err != nil {
-			return rl.Texture2D{}, err
-		}
+		return packfile.GetAssetBytes(path)
+	}
+	return os.ReadFile(path)
+}
 
-		image := rl.LoadImageFromMemory(".png", data, int32(len(data)))
-		if image == nil {
-			return rl.Texture2D{}, errors.New("failed to load image")
-		}
+// LoadTexture loads a Texture2D through the asset cache, so a file that is
+// already loaded is not uploaded again, see Acquire. The texture is released
+// with the current resource scope, or by UnloadTexture, and unloaded once
+// nothing references it anymore.
+func LoadTexture(path string) (rl.Texture2D, error) {
+	return loadCached(path, textureLoader, func(tex rl.Texture2D) any { return textureKey(tex.ID) })
+}
 
-		tex := rl.LoadTextureFromImage(image)
-		if tex.ID == 0 {
-			return rl.Texture2D{}, errors.New("failed to load texture")
-		}
-		return tex, nil
+// LoadTextureFromMemory loads a Texture2D from the data of a png file, e.g.
+// read by ReadFile. Unlike LoadTexture, it creates a new texture on every
+// call. The texture is tracked by the current resource scope.
+func LoadTextureFromMemory(data []byte) (rl.Texture2D, error) {
+	tex, err := loadTextureFromMemory(".png", data)
+	return trackTexture("", tex, err)
+}
+
+// loadTextureFromMemory loads a Texture2D from the data of an image file of
+// the given type, e.g. ".png".
+func loadTextureFromMemory(fileType string, data []byte) (rl.Texture2D, error) {
+	image := rl.LoadImageFromMemory(fileType, data, int32(len(data)))
+	if image == nil {
+		return rl.Texture2D{}, errors.New("failed to load image")
 	}
 
-	// Otherwise, load from disk using native raylib.
-	tex := rl.LoadTexture(path)
+	// the image is only needed until the texture is uploaded
+	tex := rl.LoadTextureFromImage(image)
+	rl.UnloadImage(image)
 	if tex.ID == 0 {
 		return rl.Texture2D{}, errors.New("failed to load texture")
 	}
 	return tex, nil
 }
 
-// LoadSound loads a Sound.
+// UnloadTexture releases a Texture2D loaded by LoadTexture, or unloads one
+// loaded by LoadTextureFromMemory, and stops tracking it.
+func UnloadTexture(tex rl.Texture2D) {
+	if unloadCached(textureKey(tex.ID)) {
+		return
+	}
+	resources.Forget(textureKey(tex.ID))
+	rl.UnloadTexture(tex)
+}
+
+// LoadSound loads a Sound through the asset cache, like LoadTexture. The
+// sound is released with the current resource scope, or by UnloadSound.
 func LoadSound(path string) (rl.Sound, error) {
-	if usingPackfile {
-		data, err := packfile.GetAssetBytes(path)
-		if err != nil {
-			return rl.Sound{}, err
-		}
-		ext := filepath.Ext(path)
-		wave := rl.LoadWaveFromMemory(ext, data, int32(len(data)))
-		if wave == (rl.Wave{}) {
-			return rl.Sound{}, errors.New("failed to load wave")
-		}
-		sound := rl.LoadSoundFromWave(wave)
-		if sound.Stream == (rl.AudioStream{}) {
-			return rl.Sound{}, errors.New("failed to load sound")
-		}
-		return sound, nil
+	return loadCached(path, soundLoader, func(sound rl.Sound) any { return soundKey{sound.Stream.Buffer} })
+}
+
+// LoadSoundFromMemory loads a Sound from the data of a file, e.g. read by
+// ReadFile. The path is only used to determine the file type, and to name the
+// sound in leak reports. Unlike LoadSound, it creates a new sound on every
+// call. The sound is tracked by the current resource scope.
+func LoadSoundFromMemory(path string, data []byte) (rl.Sound, error) {
+	sound, err := loadSoundFromMemory(filepath.Ext(path), data)
+	return trackSound(path, sound, err)
+}
+
+// loadSoundFromMemory loads a Sound from the data of a file of the given
+// type, e.g. ".wav".
+func loadSoundFromMemory(fileType string, data []byte) (rl.Sound, error) {
+	wave := rl.LoadWaveFromMemory(fileType, data, int32(len(data)))
+	if wave == (rl.Wave{}) {
+		return rl.Sound{}, errors.New("failed to load wave")
+	}
+	sound := rl.LoadSoundFromWave(wave)
+	if sound.Stream == (rl.AudioStream{}) {
+		return rl.Sound{}, errors.New("failed to load sound")
+	}
+	return sound, nil
+}
+
+// UnloadSound releases a Sound loaded by LoadSound, or unloads one loaded by
+// LoadSoundFromMemory, and stops tracking it.
+func UnloadSound(sound rl.Sound) {
+	if unloadCached(soundKey{sound.Stream.Buffer}) {
+		return
 	}
-	return rl.LoadSound(path), nil
+	resources.Forget(soundKey{sound.Stream.Buffer})
+	rl.UnloadSound(sound)
 }
 
-// LoadMusicStream loads a MusicStream.
+// LoadMusicStream loads a MusicStream through the asset cache, like
+// LoadTexture. Loading the same file twice returns the same stream, which
+// shares its playback position. The stream does not loop. It is released with
+// the current resource scope, or by UnloadMusicStream.
 func LoadMusicStream(path string) (rl.Music, error) {
-	if usingPackfile {
-		data, err := packfile.GetAssetBytes(path)
-		if err != nil {
-			return rl.Music{}, err
-		}
-		ext := filepath.Ext(path)
-		stream := rl.LoadMusicStreamFromMemory(ext, data, int32(len(data)))
-		if stream == (rl.Music{}) {
-			return rl.Music{}, errors.New("failed to load music stream")
+	return loadCached(path, musicLoader, func(music rl.Music) any { return musicKey{music.Stream.Buffer} })
+}
+
+// UnloadMusicStream releases a MusicStream loaded by LoadMusicStream.
+func UnloadMusicStream(music rl.Music) {
+	unloadCached(musicKey{music.Stream.Buffer})
+}
+
+// textureKey, soundKey and musicKey identify the loaded assets in the
+// resource scopes and in the loaded handles.
+type textureKey uint32
+type soundKey struct{ buffer *rl.AudioBuffer }
+type musicKey struct{ buffer *rl.AudioBuffer }
+
+// trackTexture tracks the texture by the current resource scope, unless
+// loading it failed.
+func trackTexture(path string, tex rl.Texture2D, err error) (rl.Texture2D, error) {
+	if err == nil && tex.ID != 0 {
+		resources.Track("texture", path, textureKey(tex.ID), func() { rl.UnloadTexture(tex) })
+	}
+	return tex, err
+}
+
+// trackSound tracks the sound by the current resource scope, unless loading
+// it failed.
+func trackSound(path string, sound rl.Sound, err error) (rl.Sound, error) {
+	if err == nil && sound.Stream.Buffer != nil {
+		resources.Track("sound", path, soundKey{sound.Stream.Buffer}, func() { rl.UnloadSound(sound) })
+	}
+	return sound, err
+}
+
+// loadedHandles holds the handles acquired by LoadTexture, LoadSound and
+// LoadMusicStream by the key of their asset, so UnloadTexture, UnloadSound and
+// UnloadMusicStream can release one of them.
+var loadedHandles = map[any][]releasable{}
+
+// releasable is a handle to a cached asset of any type.
+type releasable interface {
+	Release()
+}
+
+// loadCached acquires a handle to the asset at the path from the cache, and
+// returns the asset. The handle is remembered by the key of the asset, until
+// it is released by unloadCached or its resource scope.
+func loadCached[T any](path string, loader *Loader[T], keyOf func(asset T) any) (T, error) {
+	handle, err := acquireLoaded(path, loader)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	asset := handle.Get()
+	key := keyOf(asset)
+	loadedHandles[key] = append(loadedHandles[key], handle)
+	handle.onRelease = func() { forgetLoaded(key, handle) }
+	return asset, nil
+}
+
+// unloadCached releases the last handle acquired by loadCached for the asset
+// with the key. Returns false if there is none.
+func unloadCached(key any) bool {
+	handles := loadedHandles[key]
+	if len(handles) == 0 {
+		return false
+	}
+	handles[len(handles)-1].Release()
+	return true
+}
+
+// forgetLoaded removes the released handle from the loaded handles.
+func forgetLoaded(key any, handle releasable) {
+	handles := loadedHandles[key]
+	for i, h := range handles {
+		if h == handle {
+			handles = append(handles[:i:i], handles[i+1:]...)
+			break
 		}
-		return stream, nil
 	}
-	return rl.LoadMusicStream(path), nil
+	if len(handles) == 0 {
+		delete(loadedHandles, key)
+	} else {
+		loadedHandles[key] = handles
+	}
 }
@@ -0,0 +1,263 @@
+package assets
+
+import (
+	"gorl/fw/core/logging"
+	"gorl/fw/core/resources"
+	"reflect"
+	"sort"
+)
+
+// Loader creates the assets of a type for the asset cache, see Acquire. The
+// loaders of textures, sounds and music are used by AcquireTexture,
+// AcquireSound and AcquireMusic, other types of assets can be cached with a
+// loader of their own.
+type Loader[T any] struct {
+	// Kind names the type of the asset, e.g. "texture". Assets are cached by
+	// their kind, path and Go type.
+	Kind string
+	// Load creates the asset from the data of its file.
+	Load func(path string, data []byte) (T, error)
+	// Unload releases the asset once it is not referenced anymore.
+	Unload func(asset T)
+	// Size returns an estimate of the memory the asset uses in bytes.
+	Size func(asset T, data []byte) int64
+	// Placeholder creates the asset that is used in place of files that are
+	// missing or fail to load. It is created once per kind and Go type, and
+	// never unloaded.
+	Placeholder func() T
+	// KeepData keeps the data of the file in memory as long as the asset is
+	// loaded, for assets that are streamed from it, like music.
+	KeepData bool
+}
+
+// Handle is a reference to a cached asset, see Acquire. The asset is loaded
+// when the first handle to it is acquired, and unloaded when the last one is
+// released, so entities that use the same file share one asset.
+//
+// A handle is released with Release, or when the resource scope it was
+// acquired in is closed, e.g. when the scene that acquired it is disabled.
+type Handle[T any] struct {
+	entry    *cacheEntry
+	released bool
+	// onRelease is called once the handle is released, see loadCached.
+	onRelease func()
+}
+
+// cacheKey identifies a cached asset. The type of the asset is part of the
+// key, so loaders of different types never share an asset, even if they use
+// the same kind.
+type cacheKey struct {
+	kind      string
+	path      string
+	assetType reflect.Type
+}
+
+// cacheEntry is a cached asset and the number of handles that reference it.
+type cacheEntry struct {
+	key         cacheKey
+	asset       any
+	data        []byte
+	references  int
+	size        int64
+	placeholder bool
+	unload      func()
+}
+
+// cache holds the assets that are referenced by handles. placeholders holds
+// the placeholder asset of each kind and type, keyed without a path.
+var cache = map[cacheKey]*cacheEntry{}
+var placeholders = map[cacheKey]any{}
+
+// Acquire returns a handle to the asset at the path, and loads the asset
+// with the loader if it is not cached yet. If the file is missing or fails to
+// load, the error is logged and the handle refers to the placeholder of the
+// loader instead, so it can still be used.
+//
+// The handle is tracked by the current resource scope, see resources.Scope.
+func Acquire[T any](path string, loader *Loader[T]) *Handle[T] {
+	key := cacheKey{kind: loader.Kind, path: path, assetType: reflect.TypeFor[T]()}
+	entry, ok := cache[key]
+	if !ok {
+		var err error
+		entry, err = loadEntry(key, loader)
+		if err != nil {
+			logging.Error("Failed to load %v \"%v\", using a placeholder: %v", key.kind, key.path, err)
+			entry = &cacheEntry{key: key, asset: placeholderOf(loader), placeholder: true}
+		}
+		cache[key] = entry
+	}
+	return newHandle[T](entry)
+}
+
+// acquireLoaded returns a handle to the asset at the path like Acquire, but
+// returns the error instead of a placeholder if the file is missing or fails
+// to load. A cached placeholder is replaced if the file loads now.
+func acquireLoaded[T any](path string, loader *Loader[T]) (*Handle[T], error) {
+	key := cacheKey{kind: loader.Kind, path: path, assetType: reflect.TypeFor[T]()}
+	entry, ok := cache[key]
+	if !ok || entry.placeholder {
+		var err error
+		entry, err = loadEntry(key, loader)
+		if err != nil {
+			return nil, err
+		}
+		cache[key] = entry
+	}
+	return newHandle[T](entry), nil
+}
+
+// newHandle returns a new handle to the entry, tracked by the current
+// resource scope.
+func newHandle[T any](entry *cacheEntry) *Handle[T] {
+	entry.references++
+	handle := &Handle[T]{entry: entry}
+	resources.Track(entry.key.kind+" handle", entry.key.path, handle, handle.release)
+	return handle
+}
+
+// loadEntry loads the asset at the path.
+func loadEntry[T any](key cacheKey, loader *Loader[T]) (*cacheEntry, error) {
+	data, err := ReadFile(key.path)
+	if err != nil {
+		return nil, err
+	}
+	asset, err := loader.Load(key.path, data)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &cacheEntry{key: key, asset: asset}
+	entry.unload = func() { loader.Unload(asset) }
+	if loader.Size != nil {
+		entry.size = loader.Size(asset, data)
+	}
+	if loader.KeepData {
+		entry.data = data
+	}
+	return entry, nil
+}
+
+// placeholderOf returns the placeholder of the loader, and creates it the
+// first time.
+func placeholderOf[T any](loader *Loader[T]) any {
+	key := cacheKey{kind: loader.Kind, assetType: reflect.TypeFor[T]()}
+	placeholder, ok := placeholders[key]
+	if !ok {
+		placeholder = loader.Placeholder()
+		placeholders[key] = placeholder
+	}
+	return placeholder
+}
+
+// Get returns the asset. After the handle is released, the error is logged
+// and the zero value is returned.
+func (h *Handle[T]) Get() T {
+	if h.released {
+		logging.Error("Tried to use %v \"%v\" after its handle was released.", h.entry.key.kind, h.entry.key.path)
+		var zero T
+		return zero
+	}
+	return h.entry.asset.(T)
+}
+
+// Path returns the path of the asset.
+func (h *Handle[T]) Path() string {
+	return h.entry.key.path
+}
+
+// IsPlaceholder returns true if the file of the asset could not be loaded, and
+// the handle refers to the placeholder instead.
+func (h *Handle[T]) IsPlaceholder() bool {
+	return h.entry.placeholder
+}
+
+// IsReleased returns true once the handle is released.
+func (h *Handle[T]) IsReleased() bool {
+	return h.released
+}
+
+// Clone returns a new handle to the same asset, tracked by the current
+// resource scope. The asset stays loaded until both handles are released.
+// A released handle can't be cloned, the error is logged and nil returned.
+func (h *Handle[T]) Clone() *Handle[T] {
+	if h.released {
+		logging.Error("Tried to clone the handle of %v \"%v\" after it was released.", h.entry.key.kind, h.entry.key.path)
+		return nil
+	}
+	return newHandle[T](h.entry)
+}
+
+// Release releases the handle. The asset is unloaded if no other handle
+// references it. Releasing a handle twice does nothing.
+func (h *Handle[T]) Release() {
+	if h.released {
+		return
+	}
+	resources.Forget(h)
+	h.release()
+}
+
+// release releases the handle without untracking it.
+func (h *Handle[T]) release() {
+	h.released = true
+	if h.onRelease != nil {
+		h.onRelease()
+	}
+	entry := h.entry
+	entry.references--
+	if entry.references > 0 {
+		return
+	}
+
+	// the entry may have been replaced in the meantime, e.g. after the
+	// placeholder of a missing file was released and acquired again.
+	if cache[entry.key] == entry {
+		delete(cache, entry.key)
+	}
+	if entry.unload != nil {
+		entry.unload()
+	}
+}
+
+// AssetStats describes a cached asset, see Stats.
+type AssetStats struct {
+	Kind string
+	Path string
+	// References is the number of handles to the asset.
+	References int
+	// Size is an estimate of the memory the asset uses in bytes. Placeholders
+	// have no size.
+	Size        int64
+	Placeholder bool
+}
+
+// CacheStats describes the asset cache, see Stats.
+type CacheStats struct {
+	// Assets are the cached assets, sorted by kind and path.
+	Assets []AssetStats
+	// Size is an estimate of the memory all cached assets use in bytes.
+	Size int64
+}
+
+// Stats returns the assets in the cache and the memory they use.
+func Stats() CacheStats {
+	stats := CacheStats{Assets: make([]AssetStats, 0, len(cache))}
+	for _, entry := range cache {
+		size := entry.size + int64(len(entry.data))
+		stats.Assets = append(stats.Assets, AssetStats{
+			Kind:        entry.key.kind,
+			Path:        entry.key.path,
+			References:  entry.references,
+			Size:        size,
+			Placeholder: entry.placeholder,
+		})
+		stats.Size += size
+	}
+	sort.Slice(stats.Assets, func(i, j int) bool {
+		if stats.Assets[i].Kind != stats.Assets[j].Kind {
+			return stats.Assets[i].Kind < stats.Assets[j].Kind
+		}
+		return stats.Assets[i].Path < stats.Assets[j].Path
+	})
+	return stats
+}
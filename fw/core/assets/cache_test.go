@@ -0,0 +1,166 @@
+package assets
+
+import (
+	"errors"
+	"gorl/fw/core/resources"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testLoader loads the text of files, and counts the loaded assets.
+func testLoader(loaded *int) *Loader[string] {
+	return &Loader[string]{
+		Kind: "text",
+		Load: func(path string, data []byte) (string, error) {
+			if len(data) == 0 {
+				return "", errors.New("empty file")
+			}
+			*loaded++
+			return string(data), nil
+		},
+		Unload: func(asset string) { *loaded-- },
+		Size: func(asset string, data []byte) int64 {
+			return int64(len(asset))
+		},
+		Placeholder: func() string { return "missing" },
+	}
+}
+
+func TestCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.txt")
+	os.WriteFile(path, []byte("hello"), 0644)
+	loaded := 0
+	loader := testLoader(&loaded)
+
+	// the asset is loaded once and shared
+	first := Acquire(path, loader)
+	second := Acquire(path, loader)
+	clone := first.Clone()
+	if loaded != 1 || first.Get() != "hello" || second.Get() != "hello" || clone.Get() != "hello" {
+		t.Fatalf("expected one shared asset, loaded %v", loaded)
+	}
+	stats := Stats()
+	if len(stats.Assets) != 1 || stats.Assets[0].References != 3 || stats.Size != 5 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+
+	// it is unloaded with the last handle
+	first.Release()
+	first.Release()
+	second.Release()
+	if loaded != 1 {
+		t.Fatalf("the asset was unloaded while referenced")
+	}
+	clone.Release()
+	if loaded != 0 || len(Stats().Assets) != 0 {
+		t.Fatalf("expected the asset to be unloaded, loaded %v", loaded)
+	}
+	if clone.Clone() != nil || loaded != 0 {
+		t.Fatalf("a released handle must not be cloned")
+	}
+}
+
+func TestCacheTypes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.txt")
+	os.WriteFile(path, []byte("hello"), 0644)
+	loaded := 0
+	bytesLoader := &Loader[[]byte]{
+		Kind:        "text",
+		Load:        func(path string, data []byte) ([]byte, error) { return data, nil },
+		Unload:      func(asset []byte) {},
+		Placeholder: func() []byte { return nil },
+	}
+
+	// loaders of the same kind but different types don't share assets
+	text := Acquire(path, testLoader(&loaded))
+	data := Acquire(path, bytesLoader)
+	if text.Get() != "hello" || string(data.Get()) != "hello" || len(Stats().Assets) != 2 {
+		t.Fatalf("expected two assets, got %+v", Stats())
+	}
+	text.Release()
+	data.Release()
+
+	// neither do their placeholders
+	missingText := Acquire("does/not/exist.txt", testLoader(&loaded))
+	missingData := Acquire("does/not/exist.txt", bytesLoader)
+	if missingText.Get() != "missing" || missingData.Get() != nil {
+		t.Errorf("unexpected placeholders")
+	}
+	missingText.Release()
+	missingData.Release()
+}
+
+func TestCachePlaceholder(t *testing.T) {
+	empty := filepath.Join(t.TempDir(), "empty.txt")
+	os.WriteFile(empty, nil, 0644)
+	loaded := 0
+	loader := testLoader(&loaded)
+
+	missing := Acquire("does/not/exist.txt", loader)
+	broken := Acquire(empty, loader)
+	if missing.Get() != "missing" || !missing.IsPlaceholder() || !broken.IsPlaceholder() {
+		t.Fatalf("expected placeholders, got %q", missing.Get())
+	}
+	missing.Release()
+	broken.Release()
+	if len(Stats().Assets) != 0 {
+		t.Fatalf("placeholders were not released")
+	}
+}
+
+func TestCacheScope(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.txt")
+	os.WriteFile(path, []byte("hello"), 0644)
+	loaded := 0
+	loader := testLoader(&loaded)
+
+	scope := resources.NewScope("level", nil)
+	previous := resources.SetCurrent(scope)
+	handle := Acquire(path, loader)
+	resources.SetCurrent(previous)
+
+	scope.Close()
+	if !handle.IsReleased() || loaded != 0 {
+		t.Fatalf("expected the handle to be released with its scope")
+	}
+}
+
+func TestLoadCached(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.txt")
+	os.WriteFile(path, []byte("hello"), 0644)
+	loaded := 0
+	loader := testLoader(&loaded)
+	keyOf := func(asset string) any { return asset }
+
+	// loading a file twice shares the asset, unloading releases one reference
+	scope := resources.NewScope("level", nil)
+	previous := resources.SetCurrent(scope)
+	first, err := loadCached(path, loader, keyOf)
+	second, _ := loadCached(path, loader, keyOf)
+	resources.SetCurrent(previous)
+	if err != nil || first != "hello" || second != "hello" || loaded != 1 {
+		t.Fatalf("expected one shared asset, loaded %v, error %v", loaded, err)
+	}
+	if !unloadCached("hello") || loaded != 1 {
+		t.Fatalf("the asset was unloaded while referenced")
+	}
+
+	// the scope releases the other one
+	scope.Close()
+	if loaded != 0 || len(loadedHandles) != 0 || unloadCached("hello") {
+		t.Fatalf("expected the asset to be unloaded with its scope, loaded %v", loaded)
+	}
+
+	// missing files fail instead of using a placeholder
+	if _, err := loadCached("does/not/exist.txt", loader, keyOf); err == nil || len(Stats().Assets) != 0 {
+		t.Errorf("expected an error and no cached asset")
+	}
+}
+
+func TestSilentWav(t *testing.T) {
+	wav := silentWav(22050, 100)
+	if len(wav) != 44+200 || string(wav[:4]) != "RIFF" || string(wav[36:40]) != "data" {
+		t.Errorf("unexpected wav header: %q", wav[:44])
+	}
+}
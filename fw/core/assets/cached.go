@@ -0,0 +1,109 @@
+package assets
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"path/filepath"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// AcquireTexture returns a handle to the texture at the path, see Acquire.
+// Missing textures are replaced by a magenta checkerboard.
+func AcquireTexture(path string) *Handle[rl.Texture2D] {
+	return Acquire(path, textureLoader)
+}
+
+// AcquireSound returns a handle to the sound at the path, see Acquire.
+// Missing sounds are replaced by a short silence.
+func AcquireSound(path string) *Handle[rl.Sound] {
+	return Acquire(path, soundLoader)
+}
+
+// AcquireMusic returns a handle to the music stream at the path, see Acquire.
+// Missing music is replaced by a short silence.
+func AcquireMusic(path string) *Handle[rl.Music] {
+	return Acquire(path, musicLoader)
+}
+
+var textureLoader = &Loader[rl.Texture2D]{
+	Kind: "texture",
+	Load: func(path string, data []byte) (rl.Texture2D, error) {
+		return loadTextureFromMemory(filepath.Ext(path), data)
+	},
+	Unload: rl.UnloadTexture,
+	Size: func(tex rl.Texture2D, data []byte) int64 {
+		return int64(rl.GetPixelDataSize(tex.Width, tex.Height, int32(tex.Format)))
+	},
+	Placeholder: func() rl.Texture2D {
+		image := rl.GenImageChecked(64, 64, 8, 8, rl.Magenta, rl.Black)
+		tex := rl.LoadTextureFromImage(image)
+		rl.UnloadImage(image)
+		return tex
+	},
+}
+
+var soundLoader = &Loader[rl.Sound]{
+	Kind: "sound",
+	Load: func(path string, data []byte) (rl.Sound, error) {
+		return loadSoundFromMemory(filepath.Ext(path), data)
+	},
+	Unload: rl.UnloadSound,
+	Size: func(sound rl.Sound, data []byte) int64 {
+		return int64(sound.FrameCount) * int64(sound.Stream.Channels) * int64(sound.Stream.SampleSize) / 8
+	},
+	Placeholder: func() rl.Sound {
+		sound, _ := loadSoundFromMemory(".wav", silence)
+		return sound
+	},
+}
+
+var musicLoader = &Loader[rl.Music]{
+	Kind: "music",
+	Load: func(path string, data []byte) (rl.Music, error) {
+		music := rl.LoadMusicStreamFromMemory(filepath.Ext(path), data, int32(len(data)))
+		if music.Stream.Buffer == nil {
+			return rl.Music{}, errors.New("failed to load music stream")
+		}
+		music.Looping = false
+		return music, nil
+	},
+	Unload: rl.UnloadMusicStream,
+	// the music is streamed from the data of the file, which is its size
+	KeepData: true,
+	Placeholder: func() rl.Music {
+		return rl.LoadMusicStreamFromMemory(".wav", silence, int32(len(silence)))
+	},
+}
+
+// silence is a wav file of a tenth of a second of silence, the placeholder of
+// sounds and music. It is kept in memory, as music is streamed from it.
+var silence = silentWav(22050, 2205)
+
+// silentWav returns a mono 16 bit wav file of the given number of silent
+// samples.
+func silentWav(sampleRate, samples uint32) []byte {
+	const channels, bitsPerSample = 1, 16
+	dataSize := samples * channels * bitsPerSample / 8
+
+	buffer := &bytes.Buffer{}
+	buffer.WriteString("RIFF")
+	binary.Write(buffer, binary.LittleEndian, 36+dataSize)
+	buffer.WriteString("WAVEfmt ")
+	for _, value := range []any{
+		uint32(16),            // size of the format chunk
+		uint16(1),             // PCM
+		uint16(channels),      // channels
+		sampleRate,            // sample rate
+		sampleRate * 2,        // byte rate
+		uint16(2),             // block align
+		uint16(bitsPerSample), // bits per sample
+	} {
+		binary.Write(buffer, binary.LittleEndian, value)
+	}
+	buffer.WriteString("data")
+	binary.Write(buffer, binary.LittleEndian, dataSize)
+	buffer.Write(make([]byte, dataSize))
+	return buffer.Bytes()
+}
@@ -0,0 +1,256 @@
+package clock
+
+import (
+	"math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// ============================================================================
+// The clock package is the single source of time for the framework.
+// ----------------------------------------------------------------------------
+//
+//		The clock is advanced once per frame using Tick() (or Advance(dt)),
+//		and everything that depends on time reads DeltaTime(), TotalTime(),
+//		etc. instead of calling rl.GetFrameTime() directly. This allows
+//		slow motion, pausing, and deterministic tests.
+//
+//		The clock also accumulates time for fixed steps. After advancing the
+//		clock, the main loop runs one fixed step (physics, FixedUpdate) for
+//		every call to StepFixed() that returns true:
+//
+//			clock.Tick()
+//			for clock.StepFixed() {
+//				physics.Step()
+//				gem.FixedTraverse()
+//			}
+//
+//		Alpha() then tells how far the clock is between the last and the
+//		next fixed step, to interpolate what is drawn.
+//
+// ============================================================================
+
+// Source provides the duration of the last frame in seconds.
+type Source func() float32
+
+// RaylibSource reads the frame time from raylib. This is the default source.
+var RaylibSource Source = rl.GetFrameTime
+
+// FixedSource returns a Source that reports the same frame time every frame,
+// e.g. for deterministic tests.
+func FixedSource(frameTime float32) Source {
+	return func() float32 {
+		return frameTime
+	}
+}
+
+type clock struct {
+	source Source
+
+	// timeScale is the global time scale, scopeScale is the time scale of the
+	// Gem subtree that is currently processed.
+	timeScale  float32
+	scopeScale float32
+
+	unscaledDelta float32
+	delta         float32
+	fixedDelta    float32
+
+	// fixedAccumulator is the scaled time that was not yet consumed by fixed
+	// steps, fixedSteps is the number of fixed steps taken this frame.
+	fixedAccumulator float64
+	fixedSteps       int
+	maxFixedSteps    int
+	droppedTime      float64
+
+	unscaledTotal float64
+	total         float64
+	frame         uint64
+}
+
+// clockInstance is the global clock.
+var clockInstance = newClock()
+
+func newClock() clock {
+	return clock{
+		source:        RaylibSource,
+		timeScale:     1,
+		scopeScale:    1,
+		fixedDelta:    1.0 / 60.0,
+		maxFixedSteps: 8,
+	}
+}
+
+// Reset resets the clock to its initial state: no time has passed, the time
+// scale is 1 and the raylib source is used.
+func Reset() {
+	clockInstance = newClock()
+}
+
+// SetSource sets the source the clock reads the frame time from in Tick().
+func SetSource(source Source) {
+	clockInstance.source = source
+}
+
+// GetSource returns the source the clock reads the frame time from.
+func GetSource() Source {
+	return clockInstance.source
+}
+
+// Tick advances the clock by the frame time reported by its source.
+// This should be called once at the start of every frame.
+func Tick() {
+	Advance(clockInstance.source())
+}
+
+// Advance advances the clock by the given (unscaled) frame time in seconds.
+func Advance(frameTime float32) {
+	if frameTime < 0 {
+		frameTime = 0
+	}
+	clockInstance.unscaledDelta = frameTime
+	clockInstance.delta = frameTime * clockInstance.timeScale
+	clockInstance.unscaledTotal += float64(frameTime)
+	clockInstance.total += float64(clockInstance.delta)
+	clockInstance.fixedAccumulator += float64(clockInstance.delta)
+	clockInstance.fixedSteps = 0
+	clockInstance.frame++
+}
+
+// StepFixed consumes one fixed step from the accumulated time, and returns
+// true if a fixed step should be run. It should be called in a loop after
+// advancing the clock, running one fixed step per iteration.
+//
+// At most GetMaxFixedSteps() steps are run per frame. If the game falls
+// further behind (e.g. because a fixed step takes longer than the fixed
+// delta time), the remaining time is dropped instead of being caught up
+// on, so the game slows down rather than freezing.
+func StepFixed() bool {
+	c := &clockInstance
+	fixedDelta := float64(c.fixedDelta)
+	if fixedDelta <= 0 || c.fixedAccumulator < fixedDelta {
+		return false
+	}
+	if c.fixedSteps >= c.maxFixedSteps {
+		// keep the fraction of a step, so Alpha() stays continuous.
+		remainder := math.Mod(c.fixedAccumulator, fixedDelta)
+		c.droppedTime += c.fixedAccumulator - remainder
+		c.fixedAccumulator = remainder
+		return false
+	}
+	c.fixedAccumulator -= fixedDelta
+	c.fixedSteps++
+	return true
+}
+
+// Alpha returns how far the clock is between the last fixed step and the
+// next one, in the range [0, 1). Use it to interpolate between the state of
+// the previous and the current fixed step when drawing, e.g.:
+//
+//	pos := rl.Vector2Lerp(e.previousPosition, e.GetPosition(), clock.Alpha())
+func Alpha() float32 {
+	if clockInstance.fixedDelta <= 0 {
+		return 0
+	}
+	alpha := float32(clockInstance.fixedAccumulator / float64(clockInstance.fixedDelta))
+	if alpha >= 1 {
+		// only happens between Advance() and the fixed steps of a frame.
+		return 1
+	}
+	return alpha
+}
+
+// FixedStepCount returns the number of fixed steps that were run in the
+// current frame.
+func FixedStepCount() int {
+	return clockInstance.fixedSteps
+}
+
+// SetMaxFixedSteps sets the maximum number of fixed steps per frame.
+// The default is 8.
+func SetMaxFixedSteps(steps int) {
+	if steps < 1 {
+		steps = 1
+	}
+	clockInstance.maxFixedSteps = steps
+}
+
+// GetMaxFixedSteps returns the maximum number of fixed steps per frame.
+func GetMaxFixedSteps() int {
+	return clockInstance.maxFixedSteps
+}
+
+// DroppedTime returns the total time in seconds that was dropped because more
+// than GetMaxFixedSteps() fixed steps would have been needed in a frame.
+func DroppedTime() float64 {
+	return clockInstance.droppedTime
+}
+
+// DeltaTime returns the duration of the last frame in seconds, scaled by the
+// global time scale and the time scale of the Gem subtree that is currently
+// processed (see SetScopeScale).
+func DeltaTime() float32 {
+	return clockInstance.delta * clockInstance.scopeScale
+}
+
+// UnscaledDeltaTime returns the real duration of the last frame in seconds,
+// ignoring all time scales. Use this for things that should not slow down
+// with the game, like UI animations or music fades.
+func UnscaledDeltaTime() float32 {
+	return clockInstance.unscaledDelta
+}
+
+// FixedDeltaTime returns the duration of a fixed update step in seconds.
+func FixedDeltaTime() float32 {
+	return clockInstance.fixedDelta
+}
+
+// SetFixedDeltaTime sets the duration of a fixed update step in seconds.
+// This is set by physics.InitPhysics.
+func SetFixedDeltaTime(fixedDelta float32) {
+	clockInstance.fixedDelta = fixedDelta
+}
+
+// TotalTime returns the time in seconds that passed since the clock started,
+// scaled by the global time scale. Subtree time scales are not applied.
+func TotalTime() float64 {
+	return clockInstance.total
+}
+
+// UnscaledTotalTime returns the real time in seconds that passed since the
+// clock started.
+func UnscaledTotalTime() float64 {
+	return clockInstance.unscaledTotal
+}
+
+// FrameCount returns the number of frames the clock was advanced.
+func FrameCount() uint64 {
+	return clockInstance.frame
+}
+
+// SetTimeScale sets the global time scale. 1 is normal speed, 0.5 is slow
+// motion at half speed and 0 stops time. Takes effect on the next frame.
+func SetTimeScale(scale float32) {
+	if scale < 0 {
+		scale = 0
+	}
+	clockInstance.timeScale = scale
+}
+
+// GetTimeScale returns the global time scale.
+func GetTimeScale() float32 {
+	return clockInstance.timeScale
+}
+
+// SetScopeScale sets the time scale of the Gem subtree that is currently
+// processed. This is managed by the Gem during traversal, based on the time
+// scales of the entities, and should generally not be called directly.
+func SetScopeScale(scale float32) {
+	clockInstance.scopeScale = scale
+}
+
+// GetScopeScale returns the time scale of the Gem subtree that is currently
+// processed.
+func GetScopeScale() float32 {
+	return clockInstance.scopeScale
+}
@@ -0,0 +1,103 @@
+package clock
+
+import (
+	"testing"
+)
+
+// TestAdvance tests that delta and total time follow the advanced frame time
+func TestAdvance(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	Advance(0.5)
+	Advance(0.25)
+	if DeltaTime() != 0.25 || UnscaledDeltaTime() != 0.25 {
+		t.Errorf("unexpected delta time: %v, unscaled %v", DeltaTime(), UnscaledDeltaTime())
+	}
+	if TotalTime() != 0.75 || FrameCount() != 2 {
+		t.Errorf("unexpected total time %v after %d frames", TotalTime(), FrameCount())
+	}
+}
+
+// TestTimeScale tests the global and the scope time scale
+func TestTimeScale(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	SetTimeScale(0.5)
+	Advance(1)
+	if DeltaTime() != 0.5 || UnscaledDeltaTime() != 1 {
+		t.Errorf("global time scale not applied: %v, unscaled %v", DeltaTime(), UnscaledDeltaTime())
+	}
+	if TotalTime() != 0.5 || UnscaledTotalTime() != 1 {
+		t.Errorf("global time scale not applied to total time: %v", TotalTime())
+	}
+
+	SetScopeScale(0.5)
+	if DeltaTime() != 0.25 {
+		t.Errorf("scope time scale not applied: %v", DeltaTime())
+	}
+	if TotalTime() != 0.5 {
+		t.Errorf("scope time scale must not affect total time: %v", TotalTime())
+	}
+
+	SetTimeScale(-1)
+	if GetTimeScale() != 0 {
+		t.Errorf("negative time scale was not clamped: %v", GetTimeScale())
+	}
+}
+
+// TestFixedSource tests ticking the clock with a fixed source
+func TestFixedSource(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	SetSource(FixedSource(0.125))
+	for i := 0; i < 8; i++ {
+		Tick()
+	}
+	if TotalTime() != 1 || DeltaTime() != 0.125 {
+		t.Errorf("unexpected time after 8 fixed ticks: total %v, delta %v", TotalTime(), DeltaTime())
+	}
+}
+
+// TestStepFixed tests that fixed steps catch up with the elapsed time, and that
+// the number of steps per frame is clamped
+func TestStepFixed(t *testing.T) {
+	Reset()
+	defer Reset()
+	SetFixedDeltaTime(0.25)
+
+	countSteps := func() int {
+		steps := 0
+		for StepFixed() {
+			steps++
+		}
+		return steps
+	}
+
+	Advance(0.625)
+	if steps := countSteps(); steps != 2 || FixedStepCount() != 2 {
+		t.Errorf("expected 2 fixed steps, got %d", steps)
+	}
+	if Alpha() != 0.5 {
+		t.Errorf("expected alpha 0.5, got %v", Alpha())
+	}
+
+	Advance(0.125)
+	if steps := countSteps(); steps != 1 || Alpha() != 0 {
+		t.Errorf("expected 1 fixed step and alpha 0, got %d and %v", steps, Alpha())
+	}
+
+	SetMaxFixedSteps(3)
+	Advance(2.1)
+	if steps := countSteps(); steps != 3 {
+		t.Errorf("expected the steps to be clamped to 3, got %d", steps)
+	}
+	if DroppedTime() != 1.25 {
+		t.Errorf("expected 1.25s of dropped time, got %v", DroppedTime())
+	}
+	if alpha := Alpha(); alpha < 0.39 || alpha > 0.41 {
+		t.Errorf("the fraction of a step must be kept after clamping, got alpha %v", alpha)
+	}
+}
@@ -0,0 +1,58 @@
+package datastructures
+
+// RingBuffer holds the last items pushed to it, up to its capacity. Pushing
+// to a full buffer overwrites the oldest item.
+type RingBuffer[T any] struct {
+	items []T
+	start int
+	count int
+}
+
+// NewRingBuffer creates a new empty ring buffer with the given capacity,
+// which must be at least 1.
+func NewRingBuffer[T any](capacity int) *RingBuffer[T] {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &RingBuffer[T]{items: make([]T, capacity)}
+}
+
+// Push adds an item to the buffer, overwriting the oldest item if it is full.
+func (buffer *RingBuffer[T]) Push(item T) {
+	if buffer.count < len(buffer.items) {
+		buffer.items[(buffer.start+buffer.count)%len(buffer.items)] = item
+		buffer.count++
+		return
+	}
+	buffer.items[buffer.start] = item
+	buffer.start = (buffer.start + 1) % len(buffer.items)
+}
+
+// Items returns a copy of the items in the buffer, oldest first.
+func (buffer *RingBuffer[T]) Items() []T {
+	items := make([]T, buffer.count)
+	for i := range items {
+		items[i] = buffer.items[(buffer.start+i)%len(buffer.items)]
+	}
+	return items
+}
+
+// Len returns the number of items in the buffer.
+func (buffer *RingBuffer[T]) Len() int {
+	return buffer.count
+}
+
+// Cap returns the capacity of the buffer.
+func (buffer *RingBuffer[T]) Cap() int {
+	return len(buffer.items)
+}
+
+// Clear removes all items from the buffer.
+func (buffer *RingBuffer[T]) Clear() {
+	var zero T
+	for i := range buffer.items {
+		buffer.items[i] = zero
+	}
+	buffer.start = 0
+	buffer.count = 0
+}
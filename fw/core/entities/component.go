@@ -0,0 +1,107 @@
+package entities
+
+// This checks at compile time if the interface is implemented
+var _ IComponent = (*Component)(nil)
+
+// IComponent is an interface that every component should implement.
+// Components are small pieces of behaviour that can be attached to any
+// entity, as an alternative to embedding the behaviour into a new entity type.
+//
+// The lifecycle of a component is driven by the Gem: Init is called once the
+// owning entity is in the Gem graph (or right after it was added), Update and
+// FixedUpdate are called after the ones of the owner, and Deinit is called
+// when the component or its owner is removed.
+type IComponent interface {
+	// Lifecycle methods
+	Init()
+	Deinit()
+
+	// Per-frame methods
+	Update()
+	FixedUpdate()
+
+	// GetOwner returns the entity the component is attached to, or nil if the
+	// component is not initialized.
+	GetOwner() IEntity
+	// SetOwner is called by the framework when the component is initialized
+	// or deinitialized. Should not be called directly.
+	SetOwner(owner IEntity)
+}
+
+// Base implementation of IComponent. Should be embedded in every custom
+// component.
+type Component struct {
+	owner IEntity
+}
+
+func (c *Component) Init()        {} // Should be overridden by the custom component.
+func (c *Component) Deinit()      {} // Should be overridden by the custom component.
+func (c *Component) Update()      {} // Should be overridden by the custom component.
+func (c *Component) FixedUpdate() {} // Should be overridden by the custom component.
+
+// GetOwner returns the entity the component is attached to.
+func (c *Component) GetOwner() IEntity {
+	return c.owner
+}
+
+// SetOwner sets the entity the component is attached to.
+func (c *Component) SetOwner(owner IEntity) {
+	c.owner = owner
+}
+
+// AddComponent attaches a component to the entity.
+// The component is initialized by the Gem, see IComponent.
+func (ent *Entity) AddComponent(component IComponent) {
+	ent.components = append(ent.components, component)
+}
+
+// RemoveComponent detaches a component from the entity, calling its Deinit()
+// if it was initialized.
+func (ent *Entity) RemoveComponent(component IComponent) {
+	for i, c := range ent.components {
+		if c == component {
+			ent.components = append(ent.components[:i], ent.components[i+1:]...)
+			if c.GetOwner() != nil {
+				c.Deinit()
+				c.SetOwner(nil)
+			}
+			return
+		}
+	}
+}
+
+// GetComponents returns all components attached to the entity.
+// The returned slice should not be modified.
+func (ent *Entity) GetComponents() []IComponent {
+	return ent.components
+}
+
+// GetComponent returns the first component of type T attached to the entity.
+// T may be a concrete component type or an interface.
+// The second return value is false if no such component is attached.
+//
+// Example:
+//
+//	if health, ok := entities.GetComponent[*HealthComponent](enemy); ok {
+//		health.Damage(10)
+//	}
+func GetComponent[T any](entity IEntity) (T, bool) {
+	for _, c := range entity.GetComponents() {
+		if typed, ok := c.(T); ok {
+			return typed, true
+		}
+	}
+	return *new(T), false
+}
+
+// GetAllComponents returns all components of type T attached to the entity.
+// T may be a concrete component type or an interface.
+func GetAllComponents[T any](entity IEntity) []T {
+	result := []T{}
+	for _, c := range entity.GetComponents() {
+		if typed, ok := c.(T); ok {
+			result = append(result, typed)
+		}
+	}
+	return result
+}
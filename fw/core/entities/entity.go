@@ -3,6 +3,7 @@ package entities
 import (
 	input "gorl/fw/core/input/input_event"
 	"gorl/fw/core/math"
+	"sort"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
@@ -12,12 +13,18 @@ var _ IEntity = (*Entity)(nil)
 
 // Base implementation of IEntity. Should be embedded in every custom entity.
 type Entity struct {
-	Name string
+	// name is the name of the entity, see SetName.
+	name string
 
-	enabled bool // If false, the entity will not be updated or drawn.
-	visible bool // If false, the entity will not be drawn.
+	enabled bool // If false, the entity and its children will not be updated or drawn.
+	visible bool // If false, the entity will not be drawn. See visibilityMode.
+
+	// visibilityMode determines if the entity is hidden when its parent is.
+	visibilityMode VisibilityMode
 
 	// Transform2D is a struct that holds the position, rotation and scale of the entity.
+	// This is the local transform, relative to the parent in the Gem graph.
+	// See gem.GetGlobalTransform for the absolute transform.
 	transform math.Transform2D
 
 	// DrawIndex is used to determine the order in which entities are drawn.
@@ -26,20 +33,56 @@ type Entity struct {
 
 	// LayerFlags is a bit flag that determines which layers the entity belongs to.
 	// Cameras can selectively render entities based on their layer flags.
-	// (Layer flags are not automatically inherited by children.)
+	// (Layer flags are not inherited by children, unless configured by layerMode.)
 	layerFlags math.BitFlag
+
+	// layerMode determines how the layer flags of the entity are combined
+	// with the effective layer flags of its parent.
+	layerMode LayerMode
+
+	// prefab is the name of the prefab this entity was instantiated from, or
+	// empty if it was not created from a prefab.
+	prefab string
+
+	// components are the components attached to the entity.
+	components []IComponent
+
+	// processMode determines if the entity is updated while the game is paused.
+	processMode ProcessMode
+
+	// timeScale scales the passing of time for the entity and its children,
+	// see clock.DeltaTime.
+	timeScale float32
+
+	// tags is the set of tags of the entity, used to find groups of entities
+	// in the Gem graph, e.g. "enemy".
+	tags map[string]bool
+
+	// observer is notified when the name or tags of the entity change, see
+	// SetMetadataObserver.
+	observer MetadataObserver
+}
+
+// MetadataObserver is notified when the name or the tags of an entity are
+// changed through SetName, AddTag or RemoveTag. The Gem observes the entities
+// in its graph to keep its query index up to date.
+type MetadataObserver interface {
+	NameChanged(oldName, newName string)
+	TagAdded(tag string)
+	TagRemoved(tag string)
 }
 
 // NewEntity creates a new base implementation of IEntity.
 // This should be called by the constructor of a custom entity.
 func NewEntity(name string, position rl.Vector2, rotation float32, scale rl.Vector2) *Entity {
 	return &Entity{
-		Name:       name,
+		name:       name,
 		enabled:    true,
 		visible:    true,
 		transform:  math.NewTransform2D(position, rotation, scale),
 		drawIndex:  0,
 		layerFlags: math.Flag0,
+		timeScale:  1,
 	}
 }
 
@@ -49,9 +92,14 @@ func (ent *Entity) Update()      {} // Should be overridden by the custom entity
 func (ent *Entity) FixedUpdate() {} // Should be overridden by the custom entity.
 func (ent *Entity) Draw()        {} // Should be overridden by the custom entity.
 
+func (ent *Entity) EnteredTree()                            {} // Can be overridden by the custom entity.
+func (ent *Entity) Ready()                                  {} // Can be overridden by the custom entity.
+func (ent *Entity) ExitingTree()                            {} // Can be overridden by the custom entity.
+func (ent *Entity) Reparented(oldParent, newParent IEntity) {} // Can be overridden by the custom entity.
+
 // String returns the name of the entity.
 func (ent *Entity) String() string {
-	return ent.Name
+	return ent.name
 }
 
 // IsEnabled returns true if the entity is enabled.
@@ -114,7 +162,7 @@ func (ent *Entity) GetTransform() *math.Transform2D {
 // SetTransform overwrites the transform of the entity.
 // This includes the position, rotation and scale.
 func (ent *Entity) SetTransform(newTransform math.Transform2D) {
-	ent.transform = newTransform
+	ent.transform.Set(newTransform)
 }
 
 // OnInputEvent is called when an input event is received.
@@ -138,7 +186,62 @@ func (ent *Entity) SetDrawIndex(index int32) {
 
 // GetName returns the name of the entity.
 func (ent *Entity) GetName() string {
-	return ent.Name
+	return ent.name
+}
+
+// SetName sets the name of the entity. The Gem's queries see the change right
+// away.
+func (ent *Entity) SetName(name string) {
+	oldName := ent.name
+	ent.name = name
+	if ent.observer != nil && oldName != name {
+		ent.observer.NameChanged(oldName, name)
+	}
+}
+
+// AddTag adds a tag to the entity.
+func (ent *Entity) AddTag(tag string) {
+	if ent.tags == nil {
+		ent.tags = make(map[string]bool)
+	}
+	if !ent.tags[tag] {
+		ent.tags[tag] = true
+		if ent.observer != nil {
+			ent.observer.TagAdded(tag)
+		}
+	}
+}
+
+// RemoveTag removes a tag from the entity.
+func (ent *Entity) RemoveTag(tag string) {
+	if ent.tags[tag] {
+		delete(ent.tags, tag)
+		if ent.observer != nil {
+			ent.observer.TagRemoved(tag)
+		}
+	}
+}
+
+// SetMetadataObserver sets the observer that is notified when the name or tags
+// of the entity change. It is called by the Gem when the entity is added to or
+// removed from the graph. Should not be called directly.
+func (ent *Entity) SetMetadataObserver(observer MetadataObserver) {
+	ent.observer = observer
+}
+
+// HasTag returns true if the entity has the given tag.
+func (ent *Entity) HasTag(tag string) bool {
+	return ent.tags[tag]
+}
+
+// GetTags returns the tags of the entity, sorted alphabetically.
+func (ent *Entity) GetTags() []string {
+	tags := make([]string, 0, len(ent.tags))
+	for tag := range ent.tags {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
 }
 
 // GetLayerFlags returns the layer flags of the entity.
@@ -150,3 +253,65 @@ func (ent *Entity) GetLayerFlags() math.BitFlag {
 func (ent *Entity) SetLayerFlags(flags math.BitFlag) {
 	ent.layerFlags = flags
 }
+
+// GetVisibilityMode returns the visibility mode of the entity.
+func (ent *Entity) GetVisibilityMode() VisibilityMode {
+	return ent.visibilityMode
+}
+
+// SetVisibilityMode sets the visibility mode of the entity, which determines
+// if it is hidden when its parent is. See VisibilityMode.
+func (ent *Entity) SetVisibilityMode(mode VisibilityMode) {
+	ent.visibilityMode = mode
+}
+
+// GetLayerMode returns the layer mode of the entity.
+func (ent *Entity) GetLayerMode() LayerMode {
+	return ent.layerMode
+}
+
+// SetLayerMode sets the layer mode of the entity, which determines how its
+// layer flags are combined with the ones of its parent. See LayerMode.
+func (ent *Entity) SetLayerMode(mode LayerMode) {
+	ent.layerMode = mode
+}
+
+// GetProcessMode returns the process mode of the entity.
+func (ent *Entity) GetProcessMode() ProcessMode {
+	return ent.processMode
+}
+
+// SetProcessMode sets the process mode of the entity, which determines if it
+// is updated while the game is paused. See ProcessMode.
+func (ent *Entity) SetProcessMode(mode ProcessMode) {
+	ent.processMode = mode
+}
+
+// GetTimeScale returns the time scale of the entity.
+func (ent *Entity) GetTimeScale() float32 {
+	return ent.timeScale
+}
+
+// SetTimeScale sets the time scale of the entity and its children. It is
+// multiplied with the time scales of the parents and the global time scale,
+// and applies to clock.DeltaTime during the entity's updates. 1 is normal
+// speed, 0 stops time for the subtree.
+func (ent *Entity) SetTimeScale(scale float32) {
+	if scale < 0 {
+		scale = 0
+	}
+	ent.timeScale = scale
+}
+
+// GetPrefab returns the name of the prefab the entity was instantiated from.
+// Returns an empty string if the entity was not created from a prefab.
+func (ent *Entity) GetPrefab() string {
+	return ent.prefab
+}
+
+// SetPrefab sets the name of the prefab the entity was instantiated from.
+// This is set by the prefab system and should generally not be called
+// directly.
+func (ent *Entity) SetPrefab(name string) {
+	ent.prefab = name
+}
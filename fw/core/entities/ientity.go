@@ -1,6 +1,7 @@
 package entities
 
 import (
+	inputevent "gorl/fw/core/input/input_event"
 	input "gorl/fw/core/input/input_handling"
 	"gorl/fw/core/math"
 
@@ -10,9 +11,24 @@ import (
 // IEntity is an interface that every entity in the game should implement
 type IEntity interface {
 	// Lifecycle methods
+	// Init is called when the entity is added to the Gem graph, before its
+	// children are added. Deinit is called when it is removed, after its
+	// children were removed.
 	Init()
 	Deinit()
 
+	// Lifecycle notifications, see the gem package for details.
+	// EnteredTree is called right after Init, once the entity's components are
+	// initialized. Ready is called once the whole subtree added in the same
+	// frame is attached, children before their parents. ExitingTree is called
+	// before the entity or an ancestor is removed, parents before their
+	// children, while the subtree is still intact. Reparented is called after
+	// the entity was moved to a new parent.
+	EnteredTree()
+	Ready()
+	ExitingTree()
+	Reparented(oldParent, newParent IEntity)
+
 	// Per-frame methods
 	Update()
 	FixedUpdate()
@@ -38,7 +54,34 @@ type IEntity interface {
 	IsEnabled() bool
 	IsVisible() bool
 	GetLayerFlags() math.BitFlag
+	GetVisibilityMode() VisibilityMode
+	GetLayerMode() LayerMode
+
+	// Processing
+	GetProcessMode() ProcessMode
+	GetTimeScale() float32
+
+	// Components
+	AddComponent(component IComponent)
+	RemoveComponent(component IComponent)
+	GetComponents() []IComponent
 
 	// Other
 	GetName() string
+	HasTag(tag string) bool
+	GetTags() []string
+}
+
+// IPointerTarget can be implemented by an entity to receive pointer events,
+// e.g. to make a world object clickable. The pointer is hit-tested against the
+// bounds of the entity as seen by the camera it was drawn with, so this works
+// with zoom and split-screen. Only the front-most entity under the pointer
+// receives pointer events.
+type IPointerTarget interface {
+	// GetBounds returns the bounds of the entity in its local space, e.g.
+	// rl.NewRectangle(-8, -8, 16, 16) for a 16x16 sprite centered on the
+	// position of the entity. Rotation and scale of the entity and its
+	// ancestors are applied.
+	GetBounds() rl.Rectangle
+	OnPointerEvent(event *inputevent.PointerEvent)
 }
@@ -0,0 +1,54 @@
+package entities
+
+import "gorl/fw/core/math"
+
+// VisibilityMode determines how the visibility of an entity depends on the
+// visibility of its parent.
+type VisibilityMode int
+
+const (
+	// VisibilityModeInherit hides the entity if its parent is hidden. This is
+	// the default.
+	VisibilityModeInherit VisibilityMode = iota
+	// VisibilityModeOverride only uses the entity's own visible state, so the
+	// entity can be shown while its parent is hidden.
+	VisibilityModeOverride
+)
+
+// ResolveVisibility returns the effective visibility of an entity with the
+// given visible state and mode, whose parent has the given effective
+// visibility.
+func (mode VisibilityMode) ResolveVisibility(visible, parentVisible bool) bool {
+	if mode == VisibilityModeOverride {
+		return visible
+	}
+	return visible && parentVisible
+}
+
+// LayerMode determines how the layer flags of an entity are combined with the
+// effective layer flags of its parent.
+type LayerMode int
+
+const (
+	// LayerModeOverride only uses the entity's own layer flags. This is the
+	// default.
+	LayerModeOverride LayerMode = iota
+	// LayerModeInherit uses the effective layer flags of the parent, ignoring
+	// the entity's own flags.
+	LayerModeInherit
+	// LayerModeAdd combines the entity's own flags with the effective flags of
+	// the parent.
+	LayerModeAdd
+)
+
+// ResolveLayerFlags returns the effective layer flags of an entity with the
+// given flags and mode, whose parent has the given effective flags.
+func (mode LayerMode) ResolveLayerFlags(flags, parentFlags math.BitFlag) math.BitFlag {
+	switch mode {
+	case LayerModeInherit:
+		return parentFlags
+	case LayerModeAdd:
+		return flags | parentFlags
+	}
+	return flags
+}
@@ -0,0 +1,40 @@
+package entities
+
+// ProcessMode determines if an entity is updated while the game is paused
+// (see gem.SetPaused). An entity that is not processed is still drawn, but
+// receives no Update, FixedUpdate or input events. The same applies to its
+// components.
+type ProcessMode int
+
+const (
+	// ProcessModeInherit uses the process mode of the parent. This is the
+	// default. Entities directly below the root inherit ProcessModePausable.
+	ProcessModeInherit ProcessMode = iota
+	// ProcessModePausable processes the entity only while the game is not
+	// paused.
+	ProcessModePausable
+	// ProcessModeAlways processes the entity regardless of the pause state.
+	ProcessModeAlways
+	// ProcessModeWhenPaused processes the entity only while the game is
+	// paused, e.g. for pause menus.
+	ProcessModeWhenPaused
+	// ProcessModeDisabled never processes the entity.
+	ProcessModeDisabled
+)
+
+// String returns the name of the process mode.
+func (mode ProcessMode) String() string {
+	switch mode {
+	case ProcessModeInherit:
+		return "Inherit"
+	case ProcessModePausable:
+		return "Pausable"
+	case ProcessModeAlways:
+		return "Always"
+	case ProcessModeWhenPaused:
+		return "WhenPaused"
+	case ProcessModeDisabled:
+		return "Disabled"
+	}
+	return "Unknown"
+}
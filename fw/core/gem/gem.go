@@ -3,6 +3,8 @@ package gem
 import (
 	"gorl/fw/core/entities"
 	"gorl/fw/core/logging"
+	"gorl/fw/core/math"
+	"gorl/fw/core/resources"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
@@ -11,6 +13,21 @@ import (
 type gem struct {
 	root    *gemNode
 	nodeMap map[entities.IEntity]*gemNode
+	index   *queryIndex
+
+	// locked is true while a frame is in progress, from the start of Traverse
+	// until Sync. Structural changes made while locked are queued.
+	locked bool
+	queue  []command
+
+	// pendingReady holds the nodes that were added since the last Sync and
+	// still have to receive Ready().
+	pendingReady []*gemNode
+
+	// paused is the global pause state, see SetPaused.
+	paused bool
+	// frozen holds the frozen entities, see SetFrozen.
+	frozen map[entities.IEntity]bool
 }
 
 // gemNode represents a node in the Gem graph.
@@ -19,6 +36,22 @@ type gemNode struct {
 	entity   entities.IEntity
 	parent   *gemNode
 	children []*gemNode
+	// childIndex is the index of the node in the children of its parent. It
+	// orders the results of the queries, see graphPosition.
+	childIndex int
+
+	// worldMatrix caches the absolute transformation matrix of the node. It
+	// is recalculated on demand if worldDirty is set, see worldMatrixOf.
+	worldMatrix math.Matrix3
+	worldDirty  bool
+
+	// removed is set as soon as the removal of the node is requested, so it
+	// stops receiving updates, draw calls and input in the same frame.
+	removed bool
+
+	// scope is the resource scope of the subtree, if it has its own, see
+	// NewResourceScope.
+	scope *resources.Scope
 }
 
 const DefaultLayer = 0
@@ -37,6 +70,8 @@ func Init() {
 			children: make([]*gemNode, 0),
 		},
 		nodeMap: make(map[entities.IEntity]*gemNode),
+		index:   newQueryIndex(),
+		frozen:  make(map[entities.IEntity]bool),
 	}
 	// self-map the root entity
 	gemInstance.nodeMap[gemInstance.root.entity] = gemInstance.root
@@ -48,13 +83,28 @@ func GetRoot() entities.IEntity {
 }
 
 // Deinit deinitializes the global Gem graph, calling Deinit on all entities.
+// Changes that are still queued are discarded.
 func Deinit() {
-	// FIXME: causes segfault, fix
+	gemInstance.locked = false
+	gemInstance.queue = nil
+	gemInstance.pendingReady = nil
 	Remove(gemInstance.root.entity)
 }
 
 // Append adds a entities.IEntity to the Gem graph, as a child of the given parent.
+// If called during a frame, the entity is added at the next Sync.
 func Append(parent, entity entities.IEntity) {
+	if gemInstance.locked {
+		gemInstance.queue = append(gemInstance.queue, command{kind: commandAppend, entity: entity, parent: parent, index: -1})
+		return
+	}
+	appendNow(parent, entity, -1, nil)
+}
+
+// appendNow adds the entity to the graph immediately, at the given index in
+// the parent's children. A negative index appends at the end. If scope is not
+// nil, it becomes the resource scope of the entity before it is initialized.
+func appendNow(parent, entity entities.IEntity, index int, scope *resources.Scope) {
 	parentNode, ok := gemInstance.nodeMap[parent]
 	if !ok {
 		logging.Error("Parent not found in graph, can't add child")
@@ -62,49 +112,148 @@ func Append(parent, entity entities.IEntity) {
 	}
 
 	node := &gemNode{
-		entity:   entity,
-		parent:   parentNode,
-		children: make([]*gemNode, 0),
+		entity:     entity,
+		parent:     parentNode,
+		children:   make([]*gemNode, 0),
+		worldDirty: true,
+		scope:      scope,
 	}
-	parentNode.children = append(parentNode.children, node)
+	entity.GetTransform().SetOnChange(func() { invalidateWorldMatrix(node, false) })
+	insertNode(parentNode, node, index)
 	gemInstance.nodeMap[entity] = node
+	gemInstance.index.add(node)
 
-	entity.Init()
+	withScope(node, func() {
+		entity.Init()
+		initComponents(entity)
+		entity.EnteredTree()
+	})
+	gemInstance.pendingReady = append(gemInstance.pendingReady, node)
 }
 
 // Remove removes a entities.IEntity from the graph.
 // All children of the removed entity are also removed.
+// If called during a frame, the entity and its children stop receiving
+// updates, draw calls and input immediately, and are removed at the next Sync.
 func Remove(entity entities.IEntity) {
+	if gemInstance.locked {
+		QueueFree(entity)
+		return
+	}
+	removeNow(entity)
+}
+
+// QueueFree marks a entities.IEntity and its children for removal. They stop
+// receiving updates, draw calls and input immediately, and are removed from
+// the graph at the next Sync. Calling QueueFree multiple times is safe.
+func QueueFree(entity entities.IEntity) {
+	if node, ok := gemInstance.nodeMap[entity]; ok {
+		if node.removed {
+			return
+		}
+		markRemoved(node)
+	}
+	gemInstance.queue = append(gemInstance.queue, command{kind: commandRemove, entity: entity})
+}
+
+// IsQueuedForRemoval returns true if the entity was marked for removal by
+// QueueFree or by Remove during a frame, and is not removed yet.
+func IsQueuedForRemoval(entity entities.IEntity) bool {
+	node, ok := gemInstance.nodeMap[entity]
+	return ok && node.removed
+}
+
+// markRemoved marks the node and its subtree as removed.
+func markRemoved(node *gemNode) {
+	node.removed = true
+	for _, child := range node.children {
+		markRemoved(child)
+	}
+}
+
+// removeNow removes the entity from the graph immediately.
+func removeNow(entity entities.IEntity) {
 	node, ok := gemInstance.nodeMap[entity]
 	if !ok {
 		logging.Error("entity not found in graph, can't remove")
 		return
 	}
 
+	// notify the whole subtree while it is still intact, then remove it.
+	notifyExitingTree(node)
+	removeNode(node)
+}
+
+// notifyExitingTree calls ExitingTree on the node and its subtree, parents
+// before their children.
+func notifyExitingTree(node *gemNode) {
+	node.entity.ExitingTree()
+	for _, child := range append([]*gemNode(nil), node.children...) {
+		notifyExitingTree(child)
+	}
+}
+
+// removeNode removes the node and its subtree from the graph, calling Deinit
+// on all entities, children before their parents.
+func removeNode(node *gemNode) {
+	entity := node.entity
+
 	// recursively remove all children. This will call Deinit on all children.
-	for _, child := range node.children {
-		Remove(child.entity)
+	// Removing a child modifies node.children, so we iterate over a copy.
+	children := append([]*gemNode(nil), node.children...)
+	for i := len(children) - 1; i >= 0; i-- {
+		removeNode(children[i])
 	}
 
 	// remove the node from the parent's children
 	if node != gemInstance.root {
-		parent := node.parent
-		for i, child := range parent.children {
-			if child == node {
-				parent.children = append(parent.children[:i], parent.children[i+1:]...)
-				break
-			}
-		}
+		detachNode(node)
 	}
 
-	// remove the node from the node map
+	// remove the node from the node map and the query index
 	delete(gemInstance.nodeMap, entity)
+	delete(gemInstance.frozen, entity)
+	gemInstance.index.remove(node)
 
-	entity.Deinit()
+	entity.GetTransform().SetOnChange(nil)
+	withScope(node, func() {
+		deinitComponents(entity)
+		entity.Deinit()
+	})
+
+	// the resources of the subtree are released last, so Deinit can still
+	// use them.
+	if node.scope != nil {
+		node.scope.Close()
+	}
 }
 
 // ReParent changes the parent of a entities.IEntity.
+// The local transform of the entity is kept, so it moves along with the new
+// parent. Use ReParentKeepTransform to keep its absolute transform instead.
+// If called during a frame, the change is applied at the next Sync.
 func ReParent(entity, newParent entities.IEntity) {
+	if gemInstance.locked {
+		gemInstance.queue = append(gemInstance.queue, command{kind: commandReParent, entity: entity, parent: newParent})
+		return
+	}
+	reParentNow(entity, newParent, false)
+}
+
+// ReParentKeepTransform changes the parent of a entities.IEntity, adjusting
+// its local transform so its absolute transform stays the same, i.e. the
+// entity does not visibly jump.
+// If called during a frame, the change is applied at the next Sync.
+func ReParentKeepTransform(entity, newParent entities.IEntity) {
+	if gemInstance.locked {
+		gemInstance.queue = append(gemInstance.queue, command{kind: commandReParent, entity: entity, parent: newParent, keepTransform: true})
+		return
+	}
+	reParentNow(entity, newParent, true)
+}
+
+// reParentNow changes the parent of the entity immediately.
+func reParentNow(entity, newParent entities.IEntity, keepTransform bool) {
 	node, ok := gemInstance.nodeMap[entity]
 	if !ok {
 		logging.Error("entity not found in graph, can't reparent")
@@ -117,18 +266,31 @@ func ReParent(entity, newParent entities.IEntity) {
 		return
 	}
 
-	// remove the node from the old parent's children
-	oldParent := node.parent
-	for i, child := range oldParent.children {
-		if child == node {
-			oldParent.children = append(oldParent.children[:i], oldParent.children[i+1:]...)
-			break
+	// an entity can't become a child of itself or its own descendants
+	for n := newParentNode; n != nil; n = n.parent {
+		if n == node {
+			logging.Error("New parent is a descendant of the entity, can't reparent")
+			return
 		}
 	}
 
-	// add the node to the new parent's children
-	newParentNode.children = append(newParentNode.children, node)
+	var absolute math.Matrix3
+	if keepTransform {
+		absolute = worldMatrixOf(node)
+	}
+
+	oldParentNode := node.parent
+	detachNode(node)
+	insertNode(newParentNode, node, -1)
 	node.parent = newParentNode
+	invalidateWorldMatrix(node, true)
+
+	if keepTransform {
+		local := worldMatrixOf(newParentNode).Inverse().Multiply(absolute)
+		entity.SetTransform(math.NewTransform2DFromMatrix3(local))
+	}
+
+	entity.Reparented(oldParentNode.entity, newParent)
 }
 
 // GetChildren returns the children of a entities.IEntity.
@@ -156,3 +318,43 @@ func GetParent(entity entities.IEntity) entities.IEntity {
 
 	return node.parent.entity
 }
+
+// initComponents initializes all components of the entity that are not
+// initialized yet.
+func initComponents(entity entities.IEntity) {
+	for _, component := range componentsOf(entity) {
+		if component.GetOwner() == nil {
+			component.SetOwner(entity)
+			component.Init()
+		}
+	}
+}
+
+// deinitComponents deinitializes all initialized components of the entity.
+// The components stay attached, so they are initialized again if the entity
+// is added to the graph again.
+func deinitComponents(entity entities.IEntity) {
+	for _, component := range componentsOf(entity) {
+		if component.GetOwner() != nil {
+			component.Deinit()
+			component.SetOwner(nil)
+		}
+	}
+}
+
+// componentsOf returns a copy of the components of the entity, so components
+// can add or remove components, including themselves, while they are iterated.
+func componentsOf(entity entities.IEntity) []entities.IComponent {
+	return append([]entities.IComponent(nil), entity.GetComponents()...)
+}
+
+// updateComponents calls fn for every initialized component of the entity.
+// Components that are removed during the loop are skipped once removed,
+// components that are added are initialized and updated in the next frame.
+func updateComponents(entity entities.IEntity, fn func(component entities.IComponent)) {
+	for _, component := range componentsOf(entity) {
+		if component.GetOwner() != nil {
+			fn(component)
+		}
+	}
+}
@@ -0,0 +1,735 @@
+package gem
+
+import (
+	"gorl/fw/core/clock"
+	"gorl/fw/core/entities"
+	inputevent "gorl/fw/core/input/input_event"
+	input "gorl/fw/core/input/input_handling"
+	"gorl/fw/core/math"
+	"gorl/fw/core/render"
+	"gorl/fw/core/resources"
+	"reflect"
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+func newTestEntity(name string) *entities.Entity {
+	return entities.NewEntity(name, rl.Vector2Zero(), 0, rl.Vector2One())
+}
+
+type countingComponent struct {
+	entities.Component
+	inits, deinits, updates, fixedUpdates int
+}
+
+func (c *countingComponent) Init()        { c.inits++ }
+func (c *countingComponent) Deinit()      { c.deinits++ }
+func (c *countingComponent) Update()      { c.updates++ }
+func (c *countingComponent) FixedUpdate() { c.fixedUpdates++ }
+
+func TestComponentLifecycle(t *testing.T) {
+	Init()
+	entity := newTestEntity("owner")
+	early := &countingComponent{}
+	entity.AddComponent(early)
+	Append(GetRoot(), entity)
+
+	if early.inits != 1 || early.GetOwner() != entity {
+		t.Fatalf("component was not initialized on append")
+	}
+
+	late := &countingComponent{}
+	entity.AddComponent(late)
+	Traverse(true)
+	if late.inits != 1 || late.updates != 1 || late.fixedUpdates != 1 {
+		t.Fatalf("late component was not initialized and updated: %+v", late)
+	}
+	Traverse(false)
+	Sync()
+	if early.updates != 2 || early.fixedUpdates != 1 {
+		t.Fatalf("unexpected update counts: %+v", early)
+	}
+
+	if c, ok := entities.GetComponent[*countingComponent](entity); !ok || c != early {
+		t.Fatalf("GetComponent did not return the first component")
+	}
+	if n := len(entities.GetAllComponents[*countingComponent](entity)); n != 2 {
+		t.Fatalf("GetAllComponents returned %d components, want 2", n)
+	}
+
+	entity.RemoveComponent(late)
+	if late.deinits != 1 || late.GetOwner() != nil {
+		t.Fatalf("removed component was not deinitialized")
+	}
+
+	Remove(entity)
+	if early.deinits != 1 || early.GetOwner() != nil {
+		t.Fatalf("component was not deinitialized with its owner")
+	}
+}
+
+// removingComponent removes itself from its owner in its first Update.
+type removingComponent struct {
+	countingComponent
+}
+
+func (c *removingComponent) Update() {
+	c.updates++
+	c.GetOwner().RemoveComponent(c)
+}
+
+func TestComponentRemovesItself(t *testing.T) {
+	Init()
+	entity := newTestEntity("owner")
+	first := &countingComponent{}
+	removing := &removingComponent{}
+	next := &countingComponent{}
+	last := &countingComponent{}
+	for _, component := range []entities.IComponent{first, removing, next, last} {
+		entity.AddComponent(component)
+	}
+	Append(GetRoot(), entity)
+
+	Traverse(false)
+	Sync()
+	if first.updates != 1 || removing.updates != 1 || next.updates != 1 || last.updates != 1 {
+		t.Errorf("expected every component to be updated once, got %v %v %v %v",
+			first.updates, removing.updates, next.updates, last.updates)
+	}
+	if removing.deinits != 1 || len(entity.GetComponents()) != 3 {
+		t.Errorf("the component was not removed")
+	}
+	Remove(entity)
+}
+
+type taggedEntity struct {
+	*entities.Entity
+}
+
+func TestQueries(t *testing.T) {
+	Init()
+	sceneRoot := newTestEntity("scene_root")
+	level := newTestEntity("level")
+	player := newTestEntity("player")
+	enemy := &taggedEntity{newTestEntity("enemy")}
+	enemy.AddTag("enemy")
+	Append(GetRoot(), sceneRoot)
+	Append(sceneRoot, level)
+	Append(level, player)
+	Append(level, enemy)
+
+	if FindByName("player") != player {
+		t.Errorf("FindByName did not find the player")
+	}
+	if FindByPath("scene_root/level/player") != player {
+		t.Errorf("FindByPath did not find the player")
+	}
+	if FindRelative(player, "../enemy") != enemy {
+		t.Errorf("FindRelative did not resolve the parent")
+	}
+	if FindByPath("scene_root/missing") != nil {
+		t.Errorf("FindByPath found a missing entity")
+	}
+	if found := FindByTag("enemy"); len(found) != 1 || found[0] != enemy {
+		t.Errorf("FindByTag returned %v", found)
+	}
+	if found := FindDescendants[*taggedEntity](sceneRoot); len(found) != 1 || found[0] != enemy {
+		t.Errorf("FindDescendants returned %v", found)
+	}
+
+	// renames and tag changes are picked up by the index
+	player.SetName("hero")
+	player.AddTag("enemy")
+	if FindByName("player") != nil || FindByName("hero") != player {
+		t.Errorf("index did not pick up the rename")
+	}
+	if found := FindByTag("enemy"); len(found) != 2 || found[0] != player || found[1] != enemy {
+		t.Errorf("index did not pick up the new tag in graph order, got %v", found)
+	}
+
+	// ambiguous names resolve to the first entity in graph order
+	enemy.SetName("hero")
+	if FindByName("hero") != player || len(FindAllByName("hero")) != 2 {
+		t.Errorf("FindByName must return the first entity in graph order")
+	}
+	MoveChild(enemy, 0)
+	if found := FindAllByName("hero"); FindByName("hero") != enemy || found[0] != enemy || found[1] != player {
+		t.Errorf("the order did not follow the move, got %v", found)
+	}
+	ReParent(player, sceneRoot)
+	MoveChild(player, 0)
+	if found := FindByTag("enemy"); FindByName("hero") != player || found[0] != player || found[1] != enemy {
+		t.Errorf("the order did not follow the reparent, got %v", found)
+	}
+	ReParent(player, level)
+
+	Remove(level)
+	if FindByName("hero") != nil || len(FindByTag("enemy")) != 0 {
+		t.Errorf("removed entities are still indexed")
+	}
+	player.SetName("ghost")
+	if FindByName("ghost") != nil {
+		t.Errorf("renaming a removed entity must not index it")
+	}
+}
+
+type spawningEntity struct {
+	*entities.Entity
+	spawn   entities.IEntity
+	remove  entities.IEntity
+	updates int
+}
+
+func (e *spawningEntity) Update() {
+	e.updates++
+	if e.spawn != nil {
+		Append(e, e.spawn)
+		e.spawn = nil
+	}
+	if e.remove != nil {
+		Remove(e.remove)
+		e.remove = nil
+	}
+}
+
+func TestDeferredChanges(t *testing.T) {
+	Init()
+	spawner := &spawningEntity{Entity: newTestEntity("spawner")}
+	victim := &spawningEntity{Entity: newTestEntity("victim")}
+	victimChild := &spawningEntity{Entity: newTestEntity("victim_child")}
+	spawned := &spawningEntity{Entity: newTestEntity("spawned")}
+	Append(GetRoot(), spawner)
+	Append(GetRoot(), victim)
+	Append(victim, victimChild)
+
+	spawner.spawn = spawned
+	spawner.remove = victim
+	drawables, receivers := Traverse(false)
+
+	if victim.updates != 0 || victimChild.updates != 0 {
+		t.Errorf("entities removed during the frame were still updated")
+	}
+	if spawned.updates != 0 || len(GetChildren(spawner)) != 0 {
+		t.Errorf("entity appended during the frame was added immediately")
+	}
+	if !IsQueuedForRemoval(victim) || !IsQueuedForRemoval(victimChild) {
+		t.Errorf("removed entities are not marked for removal")
+	}
+	for _, d := range drawables {
+		if d.(WrappedEntity).IEntity == victim && d.ShouldDraw(math.Flag0) {
+			t.Errorf("entity removed during the frame would still be drawn")
+		}
+	}
+	if len(receivers) != 2 { // root and spawner
+		t.Errorf("expected 2 input receivers, got %d", len(receivers))
+	}
+
+	Sync()
+	if len(GetChildren(spawner)) != 1 || len(GetChildren(GetRoot())) != 1 {
+		t.Errorf("queued changes were not applied on Sync")
+	}
+	if FindByName("victim_child") != nil {
+		t.Errorf("children of removed entity are still in the graph")
+	}
+
+	Traverse(false)
+	Sync()
+	if spawned.updates != 1 {
+		t.Errorf("spawned entity was not updated in the next frame")
+	}
+}
+
+func TestQueueFreeAndDeinit(t *testing.T) {
+	Init()
+	parent := newTestEntity("parent")
+	Append(GetRoot(), parent)
+	for i := 0; i < 5; i++ {
+		Append(parent, newTestEntity("child"))
+	}
+
+	QueueFree(parent)
+	QueueFree(parent)
+	if len(GetChildren(GetRoot())) != 1 {
+		t.Errorf("QueueFree removed the entity immediately")
+	}
+	Sync()
+	if len(GetChildren(GetRoot())) != 0 || len(FindAllByName("child")) != 0 {
+		t.Errorf("QueueFree did not remove the subtree on Sync")
+	}
+
+	parent = newTestEntity("parent")
+	Append(GetRoot(), parent)
+	for i := 0; i < 5; i++ {
+		Append(parent, newTestEntity("child"))
+	}
+	Deinit()
+	if len(gemInstance.nodeMap) != 0 {
+		t.Errorf("Deinit left %d nodes behind", len(gemInstance.nodeMap))
+	}
+}
+
+func TestSiblingOrdering(t *testing.T) {
+	Init()
+	parent := newTestEntity("parent")
+	a, b, c := newTestEntity("a"), newTestEntity("b"), newTestEntity("c")
+	Append(GetRoot(), parent)
+	Append(parent, a)
+	Append(parent, b)
+	InsertChildAt(parent, c, 0)
+
+	names := func() string {
+		result := ""
+		for _, child := range GetChildren(parent) {
+			result += child.GetName()
+		}
+		return result
+	}
+	if names() != "cab" {
+		t.Errorf("InsertChildAt: got order %q, want %q", names(), "cab")
+	}
+
+	MoveChild(c, 99)
+	if names() != "abc" || GetIndexInParent(c) != 2 {
+		t.Errorf("MoveChild: got order %q, want %q", names(), "abc")
+	}
+
+	// moves made during a frame are applied on Sync
+	Traverse(false)
+	MoveChild(b, 0)
+	if names() != "abc" {
+		t.Errorf("MoveChild during a frame was applied immediately")
+	}
+	Sync()
+	if names() != "bac" {
+		t.Errorf("MoveChild on Sync: got order %q, want %q", names(), "bac")
+	}
+}
+
+func TestReParentKeepTransform(t *testing.T) {
+	Init()
+	oldParent := entities.NewEntity("old", rl.NewVector2(100, 0), 90, rl.NewVector2(2, 2))
+	newParent := entities.NewEntity("new", rl.NewVector2(-50, 20), 0, rl.NewVector2(0.5, 1))
+	child := entities.NewEntity("child", rl.NewVector2(10, 0), 0, rl.Vector2One())
+	Append(GetRoot(), oldParent)
+	Append(GetRoot(), newParent)
+	Append(oldParent, child)
+
+	// rotated by 90 degrees and scaled by 2, the child is 20 units below the parent
+	beforeTransform := GetAbsoluteTransform(child)
+	before := beforeTransform.GetPosition()
+	if rl.Vector2Distance(before, rl.NewVector2(100, 20)) > 0.01 {
+		t.Fatalf("unexpected absolute position %v", before)
+	}
+
+	ReParentKeepTransform(child, newParent)
+	after := GetAbsoluteTransform(child)
+	if rl.Vector2Distance(before, after.GetPosition()) > 0.01 {
+		t.Errorf("absolute position changed from %v to %v", before, after.GetPosition())
+	}
+	if GetParent(child) != newParent {
+		t.Errorf("entity was not reparented")
+	}
+
+	ReParent(newParent, child)
+	if GetParent(newParent) != GetRoot() {
+		t.Errorf("entity was reparented to its own descendant")
+	}
+}
+
+type lifecycleEntity struct {
+	*entities.Entity
+	log                   *[]string
+	updates, fixedUpdates int
+}
+
+func newLifecycleEntity(name string, log *[]string) *lifecycleEntity {
+	return &lifecycleEntity{Entity: newTestEntity(name), log: log}
+}
+
+func (e *lifecycleEntity) record(event string) { *e.log = append(*e.log, e.GetName()+":"+event) }
+func (e *lifecycleEntity) Init()               { e.record("init") }
+func (e *lifecycleEntity) EnteredTree()        { e.record("entered") }
+func (e *lifecycleEntity) Ready()              { e.record("ready") }
+func (e *lifecycleEntity) ExitingTree()        { e.record("exiting") }
+func (e *lifecycleEntity) Deinit()             { e.record("deinit") }
+func (e *lifecycleEntity) Update()             { e.updates++ }
+func (e *lifecycleEntity) FixedUpdate()        { e.fixedUpdates++ }
+func (e *lifecycleEntity) Reparented(_, _ entities.IEntity) {
+	e.record("reparented")
+}
+
+func TestLifecycleNotifications(t *testing.T) {
+	Init()
+	log := []string{}
+	parent := newLifecycleEntity("parent", &log)
+	child := newLifecycleEntity("child", &log)
+	Append(GetRoot(), parent)
+	Append(parent, child)
+	Sync()
+
+	want := []string{
+		"parent:init", "parent:entered",
+		"child:init", "child:entered",
+		"child:ready", "parent:ready",
+	}
+	if !reflect.DeepEqual(log, want) {
+		t.Errorf("append: got %v, want %v", log, want)
+	}
+
+	log = log[:0]
+	ReParent(child, GetRoot())
+	ReParent(child, parent)
+	Remove(parent)
+	want = []string{
+		"child:reparented", "child:reparented",
+		"parent:exiting", "child:exiting",
+		"child:deinit", "parent:deinit",
+	}
+	if !reflect.DeepEqual(log, want) {
+		t.Errorf("remove: got %v, want %v", log, want)
+	}
+}
+
+func TestPause(t *testing.T) {
+	Init()
+	log := []string{}
+	game := newLifecycleEntity("game", &log)
+	player := newLifecycleEntity("player", &log)
+	menu := newLifecycleEntity("menu", &log)
+	hud := newLifecycleEntity("hud", &log)
+	menu.SetProcessMode(entities.ProcessModeWhenPaused)
+	hud.SetProcessMode(entities.ProcessModeAlways)
+	Append(GetRoot(), game)
+	Append(game, player)
+	Append(GetRoot(), menu)
+	Append(GetRoot(), hud)
+
+	Traverse(false)
+	Sync()
+	SetPaused(true)
+	drawables, receivers := Traverse(false)
+	Sync()
+
+	if game.updates != 1 || player.updates != 1 {
+		t.Errorf("pausable entities were updated while paused")
+	}
+	if menu.updates != 1 || hud.updates != 2 {
+		t.Errorf("unexpected updates: menu %d, hud %d", menu.updates, hud.updates)
+	}
+	if len(drawables) != 5 {
+		t.Errorf("paused entities must still be drawn, got %d drawables", len(drawables))
+	}
+	if len(receivers) != 2 { // menu and hud
+		t.Errorf("paused entities must not receive input, got %d receivers", len(receivers))
+	}
+}
+
+func TestFrozen(t *testing.T) {
+	Init()
+	log := []string{}
+	scene := newLifecycleEntity("scene", &log)
+	hud := newLifecycleEntity("hud", &log)
+	hud.SetProcessMode(entities.ProcessModeAlways)
+	Append(GetRoot(), scene)
+	Append(scene, hud)
+
+	// a frozen subtree is not processed, whatever its process modes
+	SetFrozen(scene, true)
+	drawables, receivers := Traverse(false)
+	Sync()
+	FixedTraverse()
+	Sync()
+	if scene.updates != 0 || hud.updates != 0 || hud.fixedUpdates != 0 || len(drawables) != 3 {
+		t.Errorf("frozen entities must be drawn only, got %d updates", hud.updates)
+	}
+	if len(receivers) != 1 { // the root
+		t.Errorf("frozen entities must not receive input, got %d receivers", len(receivers))
+	}
+
+	SetFrozen(scene, false)
+	Traverse(false)
+	Sync()
+	if hud.updates != 1 || IsFrozen(scene) {
+		t.Errorf("unfrozen entities must be updated")
+	}
+}
+
+type drawRecorder struct {
+	*entities.Entity
+	drawnAt rl.Vector2
+}
+
+func (e *drawRecorder) Draw() { e.drawnAt = e.GetPosition() }
+
+func TestGlobalTransforms(t *testing.T) {
+	Init()
+	parent := entities.NewEntity("parent", rl.NewVector2(10, 0), 0, rl.NewVector2(2, 2))
+	child := &drawRecorder{Entity: entities.NewEntity("child", rl.NewVector2(5, 5), 0, rl.Vector2One())}
+	Append(GetRoot(), parent)
+	Append(parent, child)
+
+	near := func(a, b rl.Vector2) bool { return rl.Vector2Distance(a, b) < 0.01 }
+	if got := GetGlobalPosition(child); !near(got, rl.NewVector2(20, 10)) {
+		t.Errorf("global position: got %v", got)
+	}
+
+	// moving the parent invalidates the cached matrix of the child
+	parent.SetPosition(rl.NewVector2(0, 0))
+	if got := GetGlobalPosition(child); !near(got, rl.NewVector2(10, 10)) {
+		t.Errorf("global position after parent moved: got %v", got)
+	}
+	parent.SetTransform(math.NewTransform2D(rl.NewVector2(1, 1), 0, rl.Vector2One()))
+	if got := GetGlobalPosition(child); !near(got, rl.NewVector2(6, 6)) {
+		t.Errorf("global position after SetTransform: got %v", got)
+	}
+
+	SetGlobalPosition(child, rl.NewVector2(100, 50))
+	if got := GetGlobalPosition(child); !near(got, rl.NewVector2(100, 50)) {
+		t.Errorf("SetGlobalPosition: got %v", got)
+	}
+	if got := ToLocal(parent, rl.NewVector2(100, 50)); !near(got, child.GetPosition()) {
+		t.Errorf("ToLocal: got %v, want %v", got, child.GetPosition())
+	}
+	if got := ToGlobal(parent, child.GetPosition()); !near(got, rl.NewVector2(100, 50)) {
+		t.Errorf("ToGlobal: got %v", got)
+	}
+
+	// entities see their local transform while drawing
+	drawables, _ := Traverse(false)
+	Sync()
+	for _, d := range drawables {
+		d.Draw()
+	}
+	if child.drawnAt != child.GetPosition() {
+		t.Errorf("the transform was swapped during Draw: got %v, want %v", child.drawnAt, child.GetPosition())
+	}
+}
+
+func TestVisibilityAndLayerInheritance(t *testing.T) {
+	Init()
+	parent := newTestEntity("parent")
+	child := newTestEntity("child")
+	overriding := newTestEntity("overriding")
+	inheriting := newTestEntity("inheriting")
+	adding := newTestEntity("adding")
+	parent.SetVisible(false)
+	parent.SetLayerFlags(math.Flag1)
+	overriding.SetVisibilityMode(entities.VisibilityModeOverride)
+	inheriting.SetLayerMode(entities.LayerModeInherit)
+	adding.SetLayerMode(entities.LayerModeAdd)
+	adding.SetLayerFlags(math.Flag2)
+	Append(GetRoot(), parent)
+	for _, e := range []entities.IEntity{child, overriding, inheriting, adding} {
+		Append(parent, e)
+	}
+
+	if IsVisibleInTree(child) || !IsVisibleInTree(overriding) {
+		t.Errorf("visibility was not inherited correctly")
+	}
+	if GetEffectiveLayerFlags(child) != math.Flag0 ||
+		GetEffectiveLayerFlags(inheriting) != math.Flag1 ||
+		GetEffectiveLayerFlags(adding) != math.Flag1|math.Flag2 {
+		t.Errorf("layer flags were not inherited correctly")
+	}
+
+	parent.SetEnabled(false)
+	if IsEnabledInTree(child) {
+		t.Errorf("child of a disabled entity is enabled in tree")
+	}
+	parent.SetEnabled(true)
+
+	drawables, _ := Traverse(false)
+	Sync()
+	drawn := map[entities.IEntity]bool{}
+	for _, d := range drawables {
+		if d.ShouldDraw(math.Flag0 | math.Flag1 | math.Flag2) {
+			drawn[d.(WrappedEntity).IEntity] = true
+		}
+	}
+	if drawn[parent] || drawn[child] || !drawn[overriding] {
+		t.Errorf("hidden parent must hide its children, drawn: %v", drawn)
+	}
+}
+
+type deltaRecorder struct {
+	*entities.Entity
+	delta float32
+}
+
+func (e *deltaRecorder) Update() { e.delta = clock.DeltaTime() }
+
+func TestTimeScale(t *testing.T) {
+	Init()
+	clock.Reset()
+	defer clock.Reset()
+	parent := &deltaRecorder{Entity: newTestEntity("parent")}
+	child := &deltaRecorder{Entity: newTestEntity("child")}
+	sibling := &deltaRecorder{Entity: newTestEntity("sibling")}
+	parent.SetTimeScale(0.5)
+	child.SetTimeScale(0.5)
+	Append(GetRoot(), parent)
+	Append(parent, child)
+	Append(GetRoot(), sibling)
+
+	clock.SetTimeScale(0.5)
+	clock.Advance(1)
+	Traverse(false)
+	Sync()
+
+	if parent.delta != 0.25 || child.delta != 0.125 || sibling.delta != 0.5 {
+		t.Errorf("unexpected scaled delta times: parent %v, child %v, sibling %v",
+			parent.delta, child.delta, sibling.delta)
+	}
+	if clock.GetScopeScale() != 1 {
+		t.Errorf("scope time scale was not reset after the traversal")
+	}
+}
+
+func TestFixedTraverse(t *testing.T) {
+	Init()
+	log := []string{}
+	game := newLifecycleEntity("game", &log)
+	hud := newLifecycleEntity("hud", &log)
+	hud.SetProcessMode(entities.ProcessModeAlways)
+	component := &countingComponent{}
+	game.AddComponent(component)
+	Append(GetRoot(), game)
+	Append(GetRoot(), hud)
+
+	FixedTraverse()
+	FixedTraverse()
+	SetPaused(true)
+	FixedTraverse()
+	Sync()
+	SetPaused(false)
+
+	if game.fixedUpdates != 2 || component.fixedUpdates != 2 {
+		t.Errorf("expected 2 fixed updates, got %d (component %d)", game.fixedUpdates, component.fixedUpdates)
+	}
+	if hud.fixedUpdates != 3 || hud.updates != 0 {
+		t.Errorf("expected 3 fixed updates and no updates, got %d and %d", hud.fixedUpdates, hud.updates)
+	}
+}
+
+// pointerTarget records the pointer events and the world position of the
+// cursor in the input events it receives.
+type pointerTarget struct {
+	*entities.Entity
+	pointerEvents []inputevent.PointerEventType
+	worldPosition rl.Vector2
+}
+
+func (e *pointerTarget) GetBounds() rl.Rectangle { return rl.NewRectangle(-5, -5, 10, 10) }
+func (e *pointerTarget) OnPointerEvent(event *inputevent.PointerEvent) {
+	e.pointerEvents = append(e.pointerEvents, event.Type)
+}
+func (e *pointerTarget) OnInputEvent(event *inputevent.InputEvent) bool {
+	e.worldPosition, _ = event.GetWorldMousePosition()
+	return true
+}
+
+func TestPointerEvents(t *testing.T) {
+	Init()
+	render.InitHeadless()
+	defer render.Deinit()
+	// the camera renders at half the size it is displayed at, in the right
+	// half of the screen
+	camera := render.NewCamera(rl.Vector2Zero(), rl.Vector2Zero(), rl.NewVector2(100, 100), rl.NewVector2(200, 200), rl.NewVector2(200, 0), math.Flag0)
+	defer camera.Destroy()
+
+	fake := input.NewFakeBackend()
+	input.SetBackend(fake)
+	defer input.SetBackend(input.RaylibBackend{})
+
+	// the bounds are scaled to (10, 10) - (30, 30) in the world
+	target := &pointerTarget{Entity: entities.NewEntity("target", rl.NewVector2(20, 20), 0, rl.NewVector2(2, 2))}
+	Append(GetRoot(), target)
+
+	frame := func(screenPosition rl.Vector2, click bool) {
+		fake.SetMousePosition(screenPosition)
+		fake.SetMouseButton(rl.MouseButtonLeft, click)
+		drawables, inputReceivers := Traverse(false)
+		input.HandleInputEvents(inputReceivers, render.Draw(drawables))
+		Sync()
+		fake.NextFrame()
+	}
+
+	// world (29, 29) is displayed at screen (258, 58)
+	frame(rl.NewVector2(258, 58), true)
+	frame(rl.NewVector2(258, 58), false)
+	if target.worldPosition != rl.NewVector2(29, 29) {
+		t.Errorf("expected the cursor at world (29, 29), got %v", target.worldPosition)
+	}
+	// world (31, 31) is outside the bounds
+	frame(rl.NewVector2(262, 62), false)
+
+	want := []inputevent.PointerEventType{
+		inputevent.PointerEnter, inputevent.PointerHover, inputevent.PointerPressed,
+		inputevent.PointerHover, inputevent.PointerReleased, inputevent.PointerClick,
+		inputevent.PointerExit,
+	}
+	if !reflect.DeepEqual(target.pointerEvents, want) {
+		t.Errorf("unexpected pointer events: got %v, want %v", target.pointerEvents, want)
+	}
+
+	// an entity with a zero scale can't be hit, not even at its origin
+	target.SetScale(rl.Vector2Zero())
+	wrapped := WrappedEntity{IEntity: target, node: gemInstance.nodeMap[target]}
+	if wrapped.HitTest(rl.NewVector2(0, 0)) || wrapped.HitTest(rl.NewVector2(20, 20)) {
+		t.Errorf("an entity with a zero scale must not be hit")
+	}
+}
+
+// scopedEntity tracks a resource in Init and in every Update.
+type scopedEntity struct {
+	*entities.Entity
+	released *int
+}
+
+func (e *scopedEntity) track() {
+	resources.Track("test", e.GetName(), new(int), func() { *e.released++ })
+}
+
+func (e *scopedEntity) Init()   { e.track() }
+func (e *scopedEntity) Update() { e.track() }
+
+func TestResourceScope(t *testing.T) {
+	Init()
+	released := 0
+	level := newTestEntity("level")
+	Append(GetRoot(), level)
+	scope := NewResourceScope(level)
+	child := &scopedEntity{Entity: newTestEntity("child"), released: &released}
+	Append(level, child)
+
+	if GetResourceScope(child) != scope || GetResourceScope(GetRoot()) != resources.Global() {
+		t.Fatalf("unexpected resource scopes")
+	}
+
+	Traverse(false)
+	Sync()
+	if len(scope.Resources()) != 2 || resources.Current() != resources.Global() {
+		t.Fatalf("expected Init and Update to track by the scope, got %v", scope.Resources())
+	}
+
+	Remove(level)
+	if released != 2 || !scope.IsClosed() {
+		t.Fatalf("expected the resources to be released on removal, released %v", released)
+	}
+}
+
+func TestAppendWithScope(t *testing.T) {
+	Init()
+	released := 0
+	entity := &scopedEntity{Entity: newTestEntity("entity"), released: &released}
+	scope := resources.NewScope("entity", nil)
+	AppendWithScope(GetRoot(), entity, scope)
+	if len(scope.Resources()) != 1 {
+		t.Fatalf("expected Init to track by the scope, got %v", scope.Resources())
+	}
+	Remove(entity)
+	if released != 1 || !scope.IsClosed() {
+		t.Fatalf("expected the scope to be closed on removal")
+	}
+}
@@ -0,0 +1,67 @@
+package gem
+
+import (
+	"gorl/fw/core/entities"
+	"gorl/fw/core/math"
+)
+
+// Enabled state, visibility and layer flags are inherited through the Gem
+// graph. Traverse computes the effective values for all entities it visits,
+// the functions below compute them for a single entity.
+
+// IsEnabledInTree returns true if the entity and all of its ancestors are
+// enabled, i.e. if the entity is updated and drawn. Returns false if the entity
+// is not in the graph or was removed during the frame.
+func IsEnabledInTree(entity entities.IEntity) bool {
+	node, ok := gemInstance.nodeMap[entity]
+	if !ok || node.removed {
+		return false
+	}
+	for ; node != nil; node = node.parent {
+		if !node.entity.IsEnabled() {
+			return false
+		}
+	}
+	return true
+}
+
+// IsVisibleInTree returns the effective visibility of the entity, taking the
+// visibility of its ancestors and the entities.VisibilityMode into account.
+// Disabled entities are not drawn regardless of their visibility, see
+// IsEnabledInTree.
+func IsVisibleInTree(entity entities.IEntity) bool {
+	node, ok := gemInstance.nodeMap[entity]
+	if !ok {
+		return false
+	}
+	return effectiveVisibility(node)
+}
+
+// GetEffectiveLayerFlags returns the layer flags the entity is drawn with,
+// taking the layer flags of its ancestors and the entities.LayerMode into
+// account.
+func GetEffectiveLayerFlags(entity entities.IEntity) math.BitFlag {
+	node, ok := gemInstance.nodeMap[entity]
+	if !ok {
+		return 0
+	}
+	return effectiveLayerFlags(node)
+}
+
+// effectiveVisibility returns the effective visibility of the node.
+func effectiveVisibility(node *gemNode) bool {
+	parentVisible := true
+	if node.parent != nil {
+		parentVisible = effectiveVisibility(node.parent)
+	}
+	return node.entity.GetVisibilityMode().ResolveVisibility(node.entity.IsVisible(), parentVisible)
+}
+
+// effectiveLayerFlags returns the effective layer flags of the node.
+func effectiveLayerFlags(node *gemNode) math.BitFlag {
+	var parentFlags math.BitFlag
+	if node.parent != nil {
+		parentFlags = effectiveLayerFlags(node.parent)
+	}
+	return node.entity.GetLayerMode().ResolveLayerFlags(node.entity.GetLayerFlags(), parentFlags)
+}
@@ -0,0 +1,91 @@
+package gem
+
+import (
+	"gorl/fw/core/entities"
+	"gorl/fw/core/logging"
+)
+
+// The order of the children of a node determines the traversal order, and
+// with that the order of updates and the default draw order.
+
+// InsertChildAt adds a entities.IEntity to the Gem graph, as a child of the
+// given parent at the given index. The index is clamped to the valid range,
+// so InsertChildAt(parent, entity, 0) makes the entity the first child.
+// If called during a frame, the entity is added at the next Sync.
+func InsertChildAt(parent, entity entities.IEntity, index int) {
+	if index < 0 {
+		index = 0
+	}
+	if gemInstance.locked {
+		gemInstance.queue = append(gemInstance.queue, command{kind: commandAppend, entity: entity, parent: parent, index: index})
+		return
+	}
+	appendNow(parent, entity, index, nil)
+}
+
+// MoveChild moves a entities.IEntity to the given index among its siblings,
+// without removing it from the graph. The index is clamped to the valid range.
+// If called during a frame, the change is applied at the next Sync.
+func MoveChild(entity entities.IEntity, index int) {
+	if index < 0 {
+		index = 0
+	}
+	if gemInstance.locked {
+		gemInstance.queue = append(gemInstance.queue, command{kind: commandMove, entity: entity, index: index})
+		return
+	}
+	moveChildNow(entity, index)
+}
+
+// moveChildNow moves the entity among its siblings immediately.
+func moveChildNow(entity entities.IEntity, index int) {
+	node, ok := gemInstance.nodeMap[entity]
+	if !ok || node.parent == nil {
+		logging.Error("entity not found in graph, can't move")
+		return
+	}
+	detachNode(node)
+	insertNode(node.parent, node, index)
+}
+
+// GetIndexInParent returns the index of a entities.IEntity among the children
+// of its parent, or -1 if the entity is not in the graph or is the root.
+func GetIndexInParent(entity entities.IEntity) int {
+	node, ok := gemInstance.nodeMap[entity]
+	if !ok || node.parent == nil {
+		return -1
+	}
+	return node.childIndex
+}
+
+// insertNode inserts the node into the children of parent at the given index.
+// A negative or too large index appends at the end.
+func insertNode(parent, node *gemNode, index int) {
+	if index < 0 || index > len(parent.children) {
+		index = len(parent.children)
+	}
+	parent.children = append(parent.children, nil)
+	copy(parent.children[index+1:], parent.children[index:])
+	parent.children[index] = node
+	renumberChildren(parent, index)
+}
+
+// detachNode removes the node from the children of its parent. The parent
+// pointer of the node is left untouched.
+func detachNode(node *gemNode) {
+	parent := node.parent
+	index := node.childIndex
+	if index >= len(parent.children) || parent.children[index] != node {
+		return
+	}
+	parent.children = append(parent.children[:index], parent.children[index+1:]...)
+	renumberChildren(parent, index)
+}
+
+// renumberChildren updates the child index of the children of parent, from
+// the given index on.
+func renumberChildren(parent *gemNode, from int) {
+	for i := from; i < len(parent.children); i++ {
+		parent.children[i].childIndex = i
+	}
+}
@@ -0,0 +1,56 @@
+package gem
+
+import "gorl/fw/core/entities"
+
+// SetPaused sets the global pause state. While paused, only entities with
+// entities.ProcessModeAlways or entities.ProcessModeWhenPaused (or inheriting
+// one of them) are updated and receive input. All entities are still drawn.
+func SetPaused(paused bool) {
+	gemInstance.paused = paused
+}
+
+// IsPaused returns the global pause state.
+func IsPaused() bool {
+	return gemInstance.paused
+}
+
+// SetFrozen freezes or unfreezes the entity and its subtree. Frozen entities
+// are still drawn, but are not updated and receive no input, regardless of
+// their process modes and the pause state, e.g. a scene below a pause menu.
+func SetFrozen(entity entities.IEntity, frozen bool) {
+	if frozen {
+		gemInstance.frozen[entity] = true
+	} else {
+		delete(gemInstance.frozen, entity)
+	}
+}
+
+// IsFrozen returns true if the entity itself was frozen with SetFrozen. It
+// returns false for the entities in a frozen subtree.
+func IsFrozen(entity entities.IEntity) bool {
+	return gemInstance.frozen[entity]
+}
+
+// resolveProcessMode returns the effective process mode of an entity with the
+// given mode, whose parent has the given effective mode.
+func resolveProcessMode(mode, parentMode entities.ProcessMode) entities.ProcessMode {
+	if mode == entities.ProcessModeInherit {
+		return parentMode
+	}
+	return mode
+}
+
+// shouldProcess returns true if an entity with the given effective process
+// mode should be processed in the current pause state.
+func shouldProcess(mode entities.ProcessMode) bool {
+	switch mode {
+	case entities.ProcessModeAlways:
+		return true
+	case entities.ProcessModeWhenPaused:
+		return gemInstance.paused
+	case entities.ProcessModeDisabled:
+		return false
+	default:
+		return !gemInstance.paused
+	}
+}
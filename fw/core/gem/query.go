@@ -0,0 +1,253 @@
+package gem
+
+import (
+	"gorl/fw/core/entities"
+	"sort"
+	"strings"
+)
+
+// queryIndex keeps track of the entities in the Gem graph by name and by tag,
+// so lookups don't have to walk the whole graph.
+// Appending and removing entities updates the index directly. Changes to the
+// name or tags of an entity are reported by the entity itself, see
+// entities.MetadataObserver, and update the index right away.
+type queryIndex struct {
+	byName map[string]map[*gemNode]bool
+	byTag  map[string]map[*gemNode]bool
+}
+
+// metadataObservable is implemented by every entity embedding
+// entities.Entity.
+type metadataObservable interface {
+	SetMetadataObserver(observer entities.MetadataObserver)
+}
+
+// newQueryIndex creates an empty query index.
+func newQueryIndex() *queryIndex {
+	return &queryIndex{
+		byName: make(map[string]map[*gemNode]bool),
+		byTag:  make(map[string]map[*gemNode]bool),
+	}
+}
+
+// add adds a node to the index, and observes the name and tags of its entity.
+func (idx *queryIndex) add(node *gemNode) {
+	addToSet(idx.byName, node.entity.GetName(), node)
+	for _, tag := range node.entity.GetTags() {
+		addToSet(idx.byTag, tag, node)
+	}
+	if observable, ok := node.entity.(metadataObservable); ok {
+		observable.SetMetadataObserver(node)
+	}
+}
+
+// remove removes a node from the index.
+func (idx *queryIndex) remove(node *gemNode) {
+	if observable, ok := node.entity.(metadataObservable); ok {
+		observable.SetMetadataObserver(nil)
+	}
+	removeFromSet(idx.byName, node.entity.GetName(), node)
+	for _, tag := range node.entity.GetTags() {
+		removeFromSet(idx.byTag, tag, node)
+	}
+}
+
+// NameChanged moves the node to its new name in the query index, see
+// entities.MetadataObserver.
+func (node *gemNode) NameChanged(oldName, newName string) {
+	if gemInstance.nodeMap[node.entity] != node {
+		return
+	}
+	removeFromSet(gemInstance.index.byName, oldName, node)
+	addToSet(gemInstance.index.byName, newName, node)
+}
+
+// TagAdded adds the node to the tag in the query index, see
+// entities.MetadataObserver.
+func (node *gemNode) TagAdded(tag string) {
+	if gemInstance.nodeMap[node.entity] != node {
+		return
+	}
+	addToSet(gemInstance.index.byTag, tag, node)
+}
+
+// TagRemoved removes the node from the tag in the query index, see
+// entities.MetadataObserver.
+func (node *gemNode) TagRemoved(tag string) {
+	if gemInstance.nodeMap[node.entity] != node {
+		return
+	}
+	removeFromSet(gemInstance.index.byTag, tag, node)
+}
+
+func addToSet(sets map[string]map[*gemNode]bool, key string, node *gemNode) {
+	set, ok := sets[key]
+	if !ok {
+		set = make(map[*gemNode]bool)
+		sets[key] = set
+	}
+	set[node] = true
+}
+
+func removeFromSet(sets map[string]map[*gemNode]bool, key string, node *gemNode) {
+	set, ok := sets[key]
+	if !ok {
+		return
+	}
+	delete(set, node)
+	if len(set) == 0 {
+		delete(sets, key)
+	}
+}
+
+// entitiesOf returns the entities of all nodes in the set, in graph order.
+func entitiesOf(set map[*gemNode]bool) []entities.IEntity {
+	result := make([]entities.IEntity, 0, len(set))
+	for _, node := range inGraphOrder(set) {
+		result = append(result, node.entity)
+	}
+	return result
+}
+
+// inGraphOrder returns the nodes in the set in depth first order, the order
+// the graph is traversed in. Only the nodes of the set are sorted, by their
+// position in the graph, see graphPosition.
+func inGraphOrder(set map[*gemNode]bool) []*gemNode {
+	nodes := make([]*gemNode, 0, len(set))
+	for node := range set {
+		nodes = append(nodes, node)
+	}
+	if len(nodes) > 1 {
+		positions := make(map[*gemNode][]int, len(nodes))
+		for _, node := range nodes {
+			positions[node] = graphPosition(node)
+		}
+		sort.Slice(nodes, func(i, j int) bool {
+			return comparePositions(positions[nodes[i]], positions[nodes[j]]) < 0
+		})
+	}
+	return nodes
+}
+
+// graphPosition returns the child indices on the path from the root to the
+// node. Comparing the positions of two nodes yields their order in the graph.
+func graphPosition(node *gemNode) []int {
+	depth := 0
+	for n := node; n.parent != nil; n = n.parent {
+		depth++
+	}
+	position := make([]int, depth)
+	for n := node; n.parent != nil; n = n.parent {
+		depth--
+		position[depth] = n.childIndex
+	}
+	return position
+}
+
+// comparePositions compares two graph positions, returning a negative number
+// if a comes first in graph order, a positive number if b does, and 0 if they
+// are equal. Ancestors come before their descendants.
+func comparePositions(a, b []int) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] - b[i]
+		}
+	}
+	return len(a) - len(b)
+}
+
+// FindByName returns the entity with the given name, or nil if there is none.
+// If several entities share the name, the first one in graph order is
+// returned; use FindByPath to address an entity unambiguously.
+func FindByName(name string) entities.IEntity {
+	var first *gemNode
+	var firstPosition []int
+	for node := range gemInstance.index.byName[name] {
+		position := graphPosition(node)
+		if first == nil || comparePositions(position, firstPosition) < 0 {
+			first, firstPosition = node, position
+		}
+	}
+	if first == nil {
+		return nil
+	}
+	return first.entity
+}
+
+// FindAllByName returns all entities with the given name, in graph order.
+func FindAllByName(name string) []entities.IEntity {
+	return entitiesOf(gemInstance.index.byName[name])
+}
+
+// FindByTag returns all entities with the given tag, in graph order.
+func FindByTag(tag string) []entities.IEntity {
+	return entitiesOf(gemInstance.index.byTag[tag])
+}
+
+// FindByPath returns the entity at the given path relative to the root of the
+// Gem graph, e.g. "scene_root/level/player". Returns nil if the path does not
+// exist. See FindRelative for the path syntax.
+func FindByPath(path string) entities.IEntity {
+	return FindRelative(gemInstance.root.entity, path)
+}
+
+// FindRelative returns the entity at the given path relative to the given
+// entity, or nil if the path does not exist.
+// A path consists of entity names separated by "/". ".." refers to the parent.
+// If siblings share a name, the first one in child order is used.
+func FindRelative(entity entities.IEntity, path string) entities.IEntity {
+	node, ok := gemInstance.nodeMap[entity]
+	if !ok {
+		return nil
+	}
+
+	for _, name := range strings.Split(path, "/") {
+		switch name {
+		case "", ".":
+			continue
+		case "..":
+			node = node.parent
+		default:
+			var next *gemNode
+			for _, child := range node.children {
+				if child.entity.GetName() == name {
+					next = child
+					break
+				}
+			}
+			node = next
+		}
+		if node == nil {
+			return nil
+		}
+	}
+	return node.entity
+}
+
+// FindDescendants returns all descendants of the given entity that are of
+// type T, in depth first order. T may be a concrete entity type or an
+// interface.
+//
+// Example:
+//
+//	enemies := gem.FindDescendants[*EnemyEntity](gem.GetRoot())
+//	damageables := gem.FindDescendants[Damageable](levelRoot)
+func FindDescendants[T any](entity entities.IEntity) []T {
+	result := []T{}
+	node, ok := gemInstance.nodeMap[entity]
+	if !ok {
+		return result
+	}
+
+	var walk func(node *gemNode)
+	walk = func(node *gemNode) {
+		for _, child := range node.children {
+			if typed, ok := child.entity.(T); ok {
+				result = append(result, typed)
+			}
+			walk(child)
+		}
+	}
+	walk(node)
+	return result
+}
@@ -0,0 +1,87 @@
+package gem
+
+import (
+	"gorl/fw/core/entities"
+	"gorl/fw/core/logging"
+	"gorl/fw/core/resources"
+)
+
+// NewResourceScope gives the entity a resource scope of its own, below the
+// scope of its parent, and returns it. The resources created by the entity
+// and its subtree, e.g. in Init() or Update(), are tracked by the scope and
+// released when the entity is removed from the graph. See resources.Scope.
+//
+// If called during a frame, the scope is attached at the next Sync.
+func NewResourceScope(entity entities.IEntity) *resources.Scope {
+	parent := resources.Global()
+	if node, ok := gemInstance.nodeMap[entity]; ok && node.parent != nil {
+		parent = scopeOf(node.parent)
+	}
+	scope := resources.NewScope(entity.GetName(), parent)
+	SetResourceScope(entity, scope)
+	return scope
+}
+
+// AppendWithScope adds the entity to the graph like Append, with the given
+// resource scope attached to it, see SetResourceScope. The scope is attached
+// before the entity is initialized, so the resources it creates in Init() and
+// EnteredTree() are tracked by the scope.
+func AppendWithScope(parent, entity entities.IEntity, scope *resources.Scope) {
+	if gemInstance.locked {
+		gemInstance.queue = append(gemInstance.queue, command{kind: commandAppend, entity: entity, parent: parent, index: -1, scope: scope})
+		return
+	}
+	appendNow(parent, entity, -1, scope)
+}
+
+// SetResourceScope attaches an existing resource scope to the entity, see
+// NewResourceScope. The scope is closed when the entity is removed from the
+// graph. A nil scope detaches the scope of the entity without closing it.
+//
+// If called during a frame, the scope is attached at the next Sync.
+func SetResourceScope(entity entities.IEntity, scope *resources.Scope) {
+	if gemInstance.locked {
+		gemInstance.queue = append(gemInstance.queue, command{kind: commandSetScope, entity: entity, scope: scope})
+		return
+	}
+	setResourceScopeNow(entity, scope)
+}
+
+// setResourceScopeNow attaches the scope to the entity immediately.
+func setResourceScopeNow(entity entities.IEntity, scope *resources.Scope) {
+	node, ok := gemInstance.nodeMap[entity]
+	if !ok {
+		logging.Error("entity not found in graph, can't set resource scope")
+		return
+	}
+	node.scope = scope
+}
+
+// GetResourceScope returns the resource scope the resources created by the
+// entity are tracked by: its own scope, the scope of its closest ancestor that
+// has one, or the global scope.
+func GetResourceScope(entity entities.IEntity) *resources.Scope {
+	node, ok := gemInstance.nodeMap[entity]
+	if !ok {
+		return resources.Global()
+	}
+	return scopeOf(node)
+}
+
+// scopeOf returns the resource scope of the node, see GetResourceScope.
+func scopeOf(node *gemNode) *resources.Scope {
+	for n := node; n != nil; n = n.parent {
+		if n.scope != nil {
+			return n.scope
+		}
+	}
+	return resources.Global()
+}
+
+// withScope calls fn with the resource scope of the node as the current
+// scope.
+func withScope(node *gemNode, fn func()) {
+	previous := resources.SetCurrent(scopeOf(node))
+	defer resources.SetCurrent(previous)
+	fn()
+}
@@ -0,0 +1,101 @@
+package gem
+
+import (
+	"gorl/fw/core/entities"
+	"gorl/fw/core/resources"
+	"sort"
+)
+
+// commandKind is the kind of a queued structural change.
+type commandKind int
+
+const (
+	commandAppend commandKind = iota
+	commandRemove
+	commandReParent
+	commandMove
+	commandSetScope
+)
+
+// command is a structural change to the Gem graph that was requested during a
+// frame and is applied at the next Sync.
+type command struct {
+	kind   commandKind
+	entity entities.IEntity
+	parent entities.IEntity // the parent for commandAppend, the new parent for commandReParent
+	index  int              // the child index for commandAppend and commandMove, -1 appends
+
+	keepTransform bool // for commandReParent, see ReParentKeepTransform
+
+	scope *resources.Scope // for commandSetScope and commandAppend, see AppendWithScope
+}
+
+// Sync applies all structural changes (Append, Remove, QueueFree, ReParent,
+// MoveChild, ...) that were queued during the frame, in the order they were
+// requested, and ends the frame.
+//
+// Afterwards, Ready is called on all entities added since the last Sync, so an
+// entity can rely on the children added in the same frame being present.
+//
+// A frame starts with Traverse, so Sync should be called once per frame after
+// input handling. Traverse also applies pending changes before it starts, so
+// changes are never lost if Sync is not called.
+func Sync() {
+	gemInstance.locked = false
+
+	// applying a command or calling Ready can queue new commands, e.g.
+	// through QueueFree in Deinit(), so we loop until nothing is left.
+	for len(gemInstance.queue) > 0 || len(gemInstance.pendingReady) > 0 {
+		queue := gemInstance.queue
+		gemInstance.queue = nil
+		for _, cmd := range queue {
+			switch cmd.kind {
+			case commandAppend:
+				appendNow(cmd.parent, cmd.entity, cmd.index, cmd.scope)
+			case commandRemove:
+				// the entity may already be gone, e.g. if its parent was
+				// removed first.
+				if _, ok := gemInstance.nodeMap[cmd.entity]; ok {
+					removeNow(cmd.entity)
+				}
+			case commandReParent:
+				reParentNow(cmd.entity, cmd.parent, cmd.keepTransform)
+			case commandMove:
+				moveChildNow(cmd.entity, cmd.index)
+			case commandSetScope:
+				setResourceScopeNow(cmd.entity, cmd.scope)
+			}
+		}
+
+		notifyReady()
+	}
+}
+
+// notifyReady calls Ready on all entities added since the last call that are
+// still in the graph, children before their parents.
+func notifyReady() {
+	pending := gemInstance.pendingReady
+	gemInstance.pendingReady = nil
+
+	depths := make(map[*gemNode]int, len(pending))
+	for _, node := range pending {
+		for n := node.parent; n != nil; n = n.parent {
+			depths[node]++
+		}
+	}
+	sort.SliceStable(pending, func(i, j int) bool {
+		return depths[pending[i]] > depths[pending[j]]
+	})
+
+	for _, node := range pending {
+		if current, ok := gemInstance.nodeMap[node.entity]; ok && current == node && !node.removed {
+			withScope(node, node.entity.Ready)
+		}
+	}
+}
+
+// IsLocked returns true while a frame is in progress, i.e. between Traverse
+// and Sync. Structural changes made while locked are queued.
+func IsLocked() bool {
+	return gemInstance.locked
+}
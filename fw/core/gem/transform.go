@@ -0,0 +1,96 @@
+package gem
+
+import (
+	"gorl/fw/core/entities"
+	"gorl/fw/core/logging"
+	"gorl/fw/core/math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// The absolute (global) transformation matrix of every node is cached. Changing
+// the transform of an entity marks its node and all descendants as dirty, and
+// the matrices are recalculated the next time they are requested.
+
+// GetGlobalTransform returns the absolute transform of the entity, i.e. its
+// transform relative to the root of the Gem graph.
+func GetGlobalTransform(entity entities.IEntity) math.Transform2D {
+	node, ok := gemInstance.nodeMap[entity]
+	if !ok {
+		logging.Error("Tried to get global transform for entity not existent in gem.")
+		return math.Transform2DZero()
+	}
+	return math.NewTransform2DFromMatrix3(worldMatrixOf(node))
+}
+
+// GetAbsoluteTransform returns the absolute transform of the entity.
+// Deprecated: use GetGlobalTransform.
+func GetAbsoluteTransform(entity entities.IEntity) math.Transform2D {
+	return GetGlobalTransform(entity)
+}
+
+// GetGlobalPosition returns the absolute position of the entity.
+func GetGlobalPosition(entity entities.IEntity) rl.Vector2 {
+	return ToGlobal(entity, rl.Vector2Zero())
+}
+
+// SetGlobalPosition moves the entity to the given absolute position, by
+// adjusting its local position.
+func SetGlobalPosition(entity entities.IEntity, position rl.Vector2) {
+	node, ok := gemInstance.nodeMap[entity]
+	if !ok {
+		logging.Error("Tried to set global position for entity not existent in gem.")
+		return
+	}
+	entity.SetPosition(worldMatrixOf(node.parent).Inverse().MultiplyV(position))
+}
+
+// ToGlobal converts a point in the local space of the entity to a point in
+// global space.
+func ToGlobal(entity entities.IEntity, point rl.Vector2) rl.Vector2 {
+	node, ok := gemInstance.nodeMap[entity]
+	if !ok {
+		logging.Error("Tried to convert point for entity not existent in gem.")
+		return point
+	}
+	return worldMatrixOf(node).MultiplyV(point)
+}
+
+// ToLocal converts a point in global space to a point in the local space of
+// the entity.
+func ToLocal(entity entities.IEntity, point rl.Vector2) rl.Vector2 {
+	node, ok := gemInstance.nodeMap[entity]
+	if !ok {
+		logging.Error("Tried to convert point for entity not existent in gem.")
+		return point
+	}
+	return worldMatrixOf(node).Inverse().MultiplyV(point)
+}
+
+// worldMatrixOf returns the absolute transformation matrix of the node,
+// recalculating it if necessary. The root's transform is not applied.
+func worldMatrixOf(node *gemNode) math.Matrix3 {
+	if node == nil || node == gemInstance.root {
+		return math.Matrix3Identity()
+	}
+	if node.worldDirty {
+		// the parent's matrix is applied after the child's: M_parent * M_child
+		node.worldMatrix = worldMatrixOf(node.parent).Multiply(node.entity.GetTransform().GenerateMatrix())
+		node.worldDirty = false
+	}
+	return node.worldMatrix
+}
+
+// invalidateWorldMatrix marks the cached matrix of the node and all of its
+// descendants as outdated.
+// A dirty node never has clean descendants, so the walk stops at nodes that
+// are already dirty, unless force is set (e.g. after a reparent).
+func invalidateWorldMatrix(node *gemNode, force bool) {
+	if node.worldDirty && !force {
+		return
+	}
+	node.worldDirty = true
+	for _, child := range node.children {
+		invalidateWorldMatrix(child, force)
+	}
+}
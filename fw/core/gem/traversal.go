@@ -1,44 +1,56 @@
 package gem
 
 import (
+	"gorl/fw/core/clock"
 	"gorl/fw/core/datastructures"
 	"gorl/fw/core/entities"
-	input "gorl/fw/core/input/input_handling"
-	"gorl/fw/core/logging"
 	"gorl/fw/core/math"
+	input "gorl/fw/core/input/input_handling"
 	"gorl/fw/core/render"
+	"gorl/fw/core/resources"
 )
 
-// GetAbsoluteTransform returns the absolute transform of the entity.
-func GetAbsoluteTransform(entity entities.IEntity) math.Transform2D {
-	entityNode, ok := gemInstance.nodeMap[entity]
-	if !ok {
-		logging.Error("Tried to get absolute transform for entity not existent in gem.")
-		return math.Transform2DZero()
-	}
-
-	// step through parents until we arrive at the root.
-	transformMat3 := entity.GetTransform().GenerateMatrix()
-	for entityNode.parent != gemInstance.root && entityNode.parent != nil { // we do a nil check just for good measure, normally it should stop at the root.
-		parentMat3 := entityNode.parent.entity.GetTransform().GenerateMatrix()
-		transformMat3 = transformMat3.Multiply(parentMat3)
-		entityNode = entityNode.parent
-	}
-
-	return math.NewTransform2DFromMatrix3(transformMat3)
-}
-
 // Traverse traverses through the entity graph, updating the entities.
 // In the process, it produces a list of DrawableEntity objects.
+//
+// Traverse starts a frame: pending structural changes are applied first, and
+// changes made from now on are queued until Sync is called.
+//
+// If withFixedUpdate is true, FixedUpdate is called right after Update, at
+// most once per frame. The main loop instead calls FixedTraverse for every
+// fixed step and passes false here.
 func Traverse(withFixedUpdate bool) ([]render.Drawable, []input.InputReceiver) {
+	Sync()
+	gemInstance.locked = true
 
 	root := gemInstance.root
 
 	nodeStack := datastructures.NewStack[*gemNode](len(gemInstance.nodeMap))
 	nodeStack.Push(root)
 
-	transformStack := datastructures.NewStack[math.Matrix3](len(gemInstance.nodeMap))
-	transformStack.Push(math.Matrix3Identity())
+	// the effective process mode of the parent, see ProcessMode
+	processModeStack := datastructures.NewStack[entities.ProcessMode](len(gemInstance.nodeMap))
+	processModeStack.Push(entities.ProcessModePausable)
+
+	// the effective visibility and layer flags of the parent
+	visibleStack := datastructures.NewStack[bool](len(gemInstance.nodeMap))
+	visibleStack.Push(true)
+	layerFlagsStack := datastructures.NewStack[math.BitFlag](len(gemInstance.nodeMap))
+	layerFlagsStack.Push(0)
+
+	// the effective time scale of the parent
+	timeScaleStack := datastructures.NewStack[float32](len(gemInstance.nodeMap))
+	timeScaleStack.Push(1)
+	defer clock.SetScopeScale(1)
+
+	// whether the parent is frozen, see SetFrozen
+	frozenStack := datastructures.NewStack[bool](len(gemInstance.nodeMap))
+	frozenStack.Push(false)
+
+	// the resource scope of the parent, see NewResourceScope
+	scopeStack := datastructures.NewStack[*resources.Scope](len(gemInstance.nodeMap))
+	scopeStack.Push(resources.Global())
+	defer resources.SetCurrent(resources.Current())
 
 	drawables := make([]render.Drawable, 0, len(gemInstance.nodeMap)/2)
 	inputReceivers := make([]input.InputReceiver, 0, len(gemInstance.nodeMap)/2)
@@ -46,39 +58,111 @@ func Traverse(withFixedUpdate bool) ([]render.Drawable, []input.InputReceiver) {
 	for !nodeStack.IsEmpty() {
 
 		node, _ := nodeStack.Pop()
-		tMat3, _ := transformStack.Pop()
-
-		// if the entity is not enabled, skip it and its children
-		if !node.entity.IsEnabled() {
+		parentProcessMode, _ := processModeStack.Pop()
+		parentVisible, _ := visibleStack.Pop()
+		parentLayerFlags, _ := layerFlagsStack.Pop()
+		parentTimeScale, _ := timeScaleStack.Pop()
+		scope, _ := scopeStack.Pop()
+		parentFrozen, _ := frozenStack.Pop()
+
+		// if the entity is not enabled or was removed during this frame, skip
+		// it and its children
+		if !node.entity.IsEnabled() || node.removed {
 			continue
 		}
 
-		// add the entity to the input receivers
-		if node.entity.IsEnabled() {
-			inputReceivers = append(inputReceivers, node.entity)
+		// entities that are not processed (e.g. because the game is paused or
+		// they are frozen) are still drawn, but receive no updates and no input.
+		processMode := resolveProcessMode(node.entity.GetProcessMode(), parentProcessMode)
+		frozen := parentFrozen || gemInstance.frozen[node.entity]
+		processed := !frozen && shouldProcess(processMode)
+		timeScale := parentTimeScale * node.entity.GetTimeScale()
+		if node.scope != nil {
+			scope = node.scope
+		}
+		if processed {
+			clock.SetScopeScale(timeScale)
+			resources.SetCurrent(scope)
+
+			// Update the entity and its components. Components added since
+			// the last frame are initialized first.
+			initComponents(node.entity)
+			node.entity.Update()
+			updateComponents(node.entity, entities.IComponent.Update)
+			if withFixedUpdate {
+				node.entity.FixedUpdate()
+				updateComponents(node.entity, entities.IComponent.FixedUpdate)
+			}
 		}
 
-		// Update the entity
-		node.entity.Update()
-		if withFixedUpdate {
-			node.entity.FixedUpdate()
+		// the effective values are computed after the update, so changes the
+		// entity makes to itself apply in the same frame.
+		visible := node.entity.GetVisibilityMode().ResolveVisibility(node.entity.IsVisible(), parentVisible)
+		layerFlags := node.entity.GetLayerMode().ResolveLayerFlags(node.entity.GetLayerFlags(), parentLayerFlags)
+
+		wrapped := WrappedEntity{
+			IEntity:    node.entity,
+			node:       node,
+			visible:    visible,
+			layerFlags: layerFlags,
 		}
 
-		drawables = append(drawables, WrappedEntity{
-			IEntity:      node.entity,
-			absTransform: math.NewTransform2DFromMatrix3(tMat3),
-		})
+		// add the entity to the input receivers
+		if processed {
+			inputReceivers = append(inputReceivers, wrapped)
+		}
+		drawables = append(drawables, wrapped)
 
 		for i := len(node.children) - 1; i >= 0; i-- {
 			child := node.children[i]
 			nodeStack.Push(child)
-			transformStack.Push( // we push M_child * M_stack
-				child.entity.
-					GetTransform().
-					GenerateMatrix().
-					Multiply(tMat3))
+			processModeStack.Push(processMode)
+			visibleStack.Push(visible)
+			layerFlagsStack.Push(layerFlags)
+			timeScaleStack.Push(timeScale)
+			scopeStack.Push(scope)
+			frozenStack.Push(frozen)
 		}
 	}
 
 	return drawables, inputReceivers
 }
+
+// FixedTraverse traverses through the entity graph, calling FixedUpdate on the
+// entities and their components. It should be called once per fixed step, see
+// clock.StepFixed.
+//
+// Like Traverse, FixedTraverse applies pending structural changes first, and
+// changes made during the fixed step are queued until the next Sync.
+func FixedTraverse() {
+	Sync()
+	gemInstance.locked = true
+	defer clock.SetScopeScale(1)
+	defer resources.SetCurrent(resources.Current())
+
+	var walk func(node *gemNode, parentProcessMode entities.ProcessMode, parentTimeScale float32, scope *resources.Scope)
+	walk = func(node *gemNode, parentProcessMode entities.ProcessMode, parentTimeScale float32, scope *resources.Scope) {
+		// frozen subtrees are skipped entirely, as nothing in them is processed
+		if !node.entity.IsEnabled() || node.removed || gemInstance.frozen[node.entity] {
+			return
+		}
+
+		processMode := resolveProcessMode(node.entity.GetProcessMode(), parentProcessMode)
+		timeScale := parentTimeScale * node.entity.GetTimeScale()
+		if node.scope != nil {
+			scope = node.scope
+		}
+		if shouldProcess(processMode) {
+			clock.SetScopeScale(timeScale)
+			resources.SetCurrent(scope)
+			initComponents(node.entity)
+			node.entity.FixedUpdate()
+			updateComponents(node.entity, entities.IComponent.FixedUpdate)
+		}
+
+		for _, child := range node.children {
+			walk(child, processMode, timeScale, scope)
+		}
+	}
+	walk(gemInstance.root, entities.ProcessModePausable, 1, resources.Global())
+}
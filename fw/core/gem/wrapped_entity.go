@@ -2,33 +2,80 @@ package gem
 
 import (
 	"gorl/fw/core/entities"
+	inputevent "gorl/fw/core/input/input_event"
 	input "gorl/fw/core/input/input_handling"
 	"gorl/fw/core/math"
 	"gorl/fw/core/render"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
 var _ render.Drawable = &WrappedEntity{}
+var _ input.PointerReceiver = WrappedEntity{}
+var _ input.KeyedReceiver = WrappedEntity{}
 
 type WrappedEntity struct {
 	entities.IEntity
-	absTransform math.Transform2D
+	node *gemNode
+
+	// the effective visibility and layer flags, computed during traversal
+	visible    bool
+	layerFlags math.BitFlag
 }
 
-// ShouldDraw checks if the entity should be drawn based on its layer flags,
-// enabled and visible properties.
+// ShouldDraw checks if the entity should be drawn based on its effective
+// layer flags and visibility, and its enabled property. Entities removed
+// during the frame are not drawn.
 func (d WrappedEntity) ShouldDraw(layerFlags math.BitFlag) bool {
 	e := d.IEntity.IsEnabled()
-	v := d.IEntity.IsVisible()
-	f := d.IEntity.GetLayerFlags().IsAny(layerFlags)
-	return e && v && f
+	v := d.visible
+	f := d.layerFlags.IsAny(layerFlags)
+	return e && v && f && !d.node.removed
+}
+
+// OnInputEvent passes the event on to the entity, unless the entity was
+// removed during the frame.
+func (d WrappedEntity) OnInputEvent(event *inputevent.InputEvent) bool {
+	if d.node.removed {
+		return true
+	}
+	return d.IEntity.OnInputEvent(event)
+}
+
+// ReceiverKey identifies the entity across frames, see input.KeyedReceiver.
+func (d WrappedEntity) ReceiverKey() any {
+	return d.node
+}
+
+// HitTest returns true if the entity implements entities.IPointerTarget and
+// the world position is inside its bounds. An entity with a zero scale has no
+// area, so it is never hit.
+func (d WrappedEntity) HitTest(worldPosition rl.Vector2) bool {
+	target, ok := d.IEntity.(entities.IPointerTarget)
+	if !ok || d.node.removed {
+		return false
+	}
+	world := worldMatrixOf(d.node)
+	if world.Determinant() == 0 {
+		return false
+	}
+	local := world.Inverse().MultiplyV(worldPosition)
+	return rl.CheckCollisionPointRec(local, target.GetBounds())
+}
+
+// OnPointerEvent passes the event on to the entity, unless the entity was
+// removed during the frame.
+func (d WrappedEntity) OnPointerEvent(event *inputevent.PointerEvent) {
+	if target, ok := d.IEntity.(entities.IPointerTarget); ok && !d.node.removed {
+		target.OnPointerEvent(event)
+	}
 }
 
 // Draw draws the entity.
+// The entity's transform is its local transform, entities that draw at their
+// position should use GetGlobalTransform.
 func (d WrappedEntity) Draw() {
-	oldTransform := *d.IEntity.GetTransform() // save the entity's old *local* transform
-	d.IEntity.SetTransform(d.absTransform)    // set the entity's transform to the new transform matrix
-	d.IEntity.Draw()                          // draw the entity
-	d.IEntity.SetTransform(oldTransform)      // restore the entity's old *local* transform
+	d.IEntity.Draw()
 }
 
 // GetEntity retrieves the wrapped entity.
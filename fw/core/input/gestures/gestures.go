@@ -0,0 +1,270 @@
+// Package gestures recognizes touch gestures like taps, drags, pinches and
+// swipes from raw pointer data. It is pure logic: the pointers that are down
+// are fed in every frame, so recognition can be tested with synthetic pointer
+// streams. The input handling feeds it with the touch points and the mouse,
+// see input_handling.GetGestures.
+package gestures
+
+import (
+	"fmt"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// MousePointer is the ID of the mouse, when it is used as a pointer.
+const MousePointer int32 = -1
+
+// Pointer is a pointer that is down, e.g. a finger on a touch screen. Its ID
+// stays the same while it is down.
+type Pointer struct {
+	ID       int32
+	Position rl.Vector2
+}
+
+// Type is the type of a gesture.
+type Type int32
+
+const (
+	// Tap is a short touch without movement.
+	Tap Type = iota
+	// DragStart, Drag and DragEnd are sent when a single pointer moves
+	// beyond Config.DragMinDistance, while it moves, and when it is released.
+	DragStart
+	Drag
+	DragEnd
+	// Pinch is sent while two pointers move, see Gesture.Scale.
+	Pinch
+	// Swipe is a fast drag, sent when the pointer is released.
+	Swipe
+)
+
+var typeNames = []string{"tap", "dragStart", "drag", "dragEnd", "pinch", "swipe"}
+
+// String returns the name of the gesture type.
+func (t Type) String() string {
+	if t < 0 || int(t) >= len(typeNames) {
+		return "unknown"
+	}
+	return typeNames[t]
+}
+
+// MarshalText implements encoding.TextMarshaler, so gesture types are stored
+// by name in the bindings file.
+func (t Type) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (t *Type) UnmarshalText(text []byte) error {
+	for i, name := range typeNames {
+		if name == string(text) {
+			*t = Type(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown gesture type %q", text)
+}
+
+// Gesture is a recognized gesture.
+type Gesture struct {
+	Type Type
+	// Position is the position of the tap, the current position of the drag,
+	// the center of the pinch or the end of the swipe.
+	Position rl.Vector2
+	// Delta is the movement since the last frame, of the drag or of the
+	// center of the pinch. For DragStart, it is the movement since the
+	// pointer went down.
+	Delta rl.Vector2
+	// Scale is the change of the distance between the pointers of a pinch
+	// since the last frame, e.g. 1.1 if they moved 10% apart.
+	Scale float32
+	// Direction is the normalized direction of a swipe, Velocity its speed in
+	// pixels per second.
+	Direction rl.Vector2
+	Velocity  float32
+	// PointerID is the pointer of a tap, drag or swipe.
+	PointerID int32
+}
+
+// Config holds the thresholds of the recognition.
+type Config struct {
+	// TapMaxDuration is the longest a pointer can be down for a tap, in
+	// seconds.
+	TapMaxDuration float32
+	// DragMinDistance is the distance in pixels a pointer has to move to
+	// start a drag. Pointers that move less can cause a tap.
+	DragMinDistance float32
+	// SwipeMaxDuration is the longest a drag can take to be a swipe, in
+	// seconds, and SwipeMinVelocity the speed it needs, in pixels per second.
+	SwipeMaxDuration float32
+	SwipeMinVelocity float32
+}
+
+// DefaultConfig returns the default thresholds.
+func DefaultConfig() Config {
+	return Config{
+		TapMaxDuration:   0.3,
+		DragMinDistance:  10,
+		SwipeMaxDuration: 0.4,
+		SwipeMinVelocity: 500,
+	}
+}
+
+// track is the state of a pointer that is down.
+type track struct {
+	start, last rl.Vector2
+	startTime   float64
+	dragging    bool
+}
+
+// Recognizer recognizes gestures from the pointers that are down in every
+// frame.
+type Recognizer struct {
+	config Config
+	tracks map[int32]*track
+	// order holds the IDs of the pointers in the order they went down.
+	order []int32
+	// multi is true if several pointers were down since all pointers were
+	// last released. Taps, drags and swipes need a single pointer.
+	multi bool
+
+	pinching      bool
+	pinchDistance float32
+	pinchCenter   rl.Vector2
+}
+
+// NewRecognizer creates a recognizer with the given thresholds.
+func NewRecognizer(config Config) *Recognizer {
+	return &Recognizer{config: config, tracks: map[int32]*track{}}
+}
+
+// SetConfig changes the thresholds of the recognizer.
+func (r *Recognizer) SetConfig(config Config) {
+	r.config = config
+}
+
+// GetConfig returns the thresholds of the recognizer.
+func (r *Recognizer) GetConfig() Config {
+	return r.config
+}
+
+// Reset forgets all pointers, without recognizing any gestures.
+func (r *Recognizer) Reset() {
+	r.tracks = map[int32]*track{}
+	r.order = nil
+	r.multi = false
+	r.pinching = false
+}
+
+// Update advances the recognizer by a frame. now is the current time in
+// seconds, pointers are the pointers that are down. Pointers that were down
+// in the last frame and are missing now were released. Returns the gestures
+// recognized in this frame.
+func (r *Recognizer) Update(now float64, pointers []Pointer) []Gesture {
+	gestures := []Gesture{}
+
+	down := make(map[int32]rl.Vector2, len(pointers))
+	for _, pointer := range pointers {
+		down[pointer.ID] = pointer.Position
+	}
+
+	// released pointers
+	remaining := r.order[:0]
+	for _, id := range r.order {
+		if _, ok := down[id]; ok {
+			remaining = append(remaining, id)
+			continue
+		}
+		gestures = append(gestures, r.release(now, id)...)
+		delete(r.tracks, id)
+	}
+	r.order = remaining
+	if len(r.order) < 2 {
+		r.pinching = false
+	}
+
+	// new pointers
+	for _, pointer := range pointers {
+		if _, ok := r.tracks[pointer.ID]; ok {
+			continue
+		}
+		r.tracks[pointer.ID] = &track{start: pointer.Position, last: pointer.Position, startTime: now}
+		r.order = append(r.order, pointer.ID)
+		if len(r.order) > 1 && !r.multi {
+			// a second pointer turns the drag into a pinch
+			r.multi = true
+			for _, id := range r.order {
+				if t := r.tracks[id]; t.dragging {
+					t.dragging = false
+					gestures = append(gestures, Gesture{Type: DragEnd, Position: t.last, PointerID: id})
+				}
+			}
+		}
+	}
+
+	// moved pointers
+	if len(r.order) == 1 && !r.multi {
+		id := r.order[0]
+		t := r.tracks[id]
+		position := down[id]
+		if !t.dragging && rl.Vector2Distance(position, t.start) >= r.config.DragMinDistance {
+			t.dragging = true
+			gestures = append(gestures, Gesture{Type: DragStart, Position: t.start, Delta: rl.Vector2Subtract(position, t.start), PointerID: id})
+		} else if t.dragging && position != t.last {
+			gestures = append(gestures, Gesture{Type: Drag, Position: position, Delta: rl.Vector2Subtract(position, t.last), PointerID: id})
+		}
+	}
+	if len(r.order) >= 2 {
+		a, b := down[r.order[0]], down[r.order[1]]
+		distance := rl.Vector2Distance(a, b)
+		center := rl.Vector2Scale(rl.Vector2Add(a, b), 0.5)
+		if r.pinching && r.pinchDistance > 0 && (distance != r.pinchDistance || center != r.pinchCenter) {
+			gestures = append(gestures, Gesture{
+				Type:     Pinch,
+				Position: center,
+				Delta:    rl.Vector2Subtract(center, r.pinchCenter),
+				Scale:    distance / r.pinchDistance,
+			})
+		}
+		r.pinching = true
+		r.pinchDistance = distance
+		r.pinchCenter = center
+	}
+
+	for _, id := range r.order {
+		r.tracks[id].last = down[id]
+	}
+	if len(r.order) == 0 {
+		r.multi = false
+	}
+	return gestures
+}
+
+// release returns the gestures caused by releasing the pointer.
+func (r *Recognizer) release(now float64, id int32) []Gesture {
+	t := r.tracks[id]
+	duration := float32(now - t.startTime)
+
+	if !t.dragging {
+		if !r.multi && duration <= r.config.TapMaxDuration {
+			return []Gesture{{Type: Tap, Position: t.last, PointerID: id}}
+		}
+		return nil
+	}
+
+	gestures := []Gesture{{Type: DragEnd, Position: t.last, PointerID: id}}
+	movement := rl.Vector2Subtract(t.last, t.start)
+	if duration > 0 && duration <= r.config.SwipeMaxDuration {
+		velocity := rl.Vector2Length(movement) / duration
+		if velocity >= r.config.SwipeMinVelocity {
+			gestures = append(gestures, Gesture{
+				Type:      Swipe,
+				Position:  t.last,
+				Direction: rl.Vector2Normalize(movement),
+				Velocity:  velocity,
+				PointerID: id,
+			})
+		}
+	}
+	return gestures
+}
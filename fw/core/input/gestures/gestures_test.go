@@ -0,0 +1,93 @@
+package gestures
+
+import (
+	"reflect"
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// frame is a frame of a synthetic pointer stream.
+type frame struct {
+	time     float64
+	pointers []Pointer
+}
+
+// run feeds the stream to a new recognizer and returns the recognized
+// gestures.
+func run(frames ...frame) []Gesture {
+	recognizer := NewRecognizer(DefaultConfig())
+	gestures := []Gesture{}
+	for _, f := range frames {
+		gestures = append(gestures, recognizer.Update(f.time, f.pointers)...)
+	}
+	return gestures
+}
+
+func types(gestures []Gesture) []Type {
+	result := []Type{}
+	for _, gesture := range gestures {
+		result = append(result, gesture.Type)
+	}
+	return result
+}
+
+func at(x, y float32) []Pointer {
+	return []Pointer{{ID: 0, Position: rl.NewVector2(x, y)}}
+}
+
+func TestTap(t *testing.T) {
+	gestures := run(frame{0, at(10, 10)}, frame{0.1, at(12, 11)}, frame{0.15, nil})
+	if !reflect.DeepEqual(types(gestures), []Type{Tap}) || gestures[0].Position != rl.NewVector2(12, 11) {
+		t.Errorf("expected a tap at (12, 11), got %+v", gestures)
+	}
+
+	// held too long
+	if gestures := run(frame{0, at(10, 10)}, frame{1, nil}); len(gestures) != 0 {
+		t.Errorf("expected no gesture for a long press, got %+v", gestures)
+	}
+}
+
+func TestDragAndSwipe(t *testing.T) {
+	// a slow drag
+	gestures := run(frame{0, at(0, 0)}, frame{0.5, at(20, 0)}, frame{1, at(30, 0)}, frame{1.5, nil})
+	if want := []Type{DragStart, Drag, DragEnd}; !reflect.DeepEqual(types(gestures), want) {
+		t.Fatalf("expected %v, got %+v", want, gestures)
+	}
+	if gestures[0].Position != rl.NewVector2(0, 0) || gestures[1].Delta != rl.NewVector2(10, 0) {
+		t.Errorf("unexpected drag positions: %+v", gestures)
+	}
+
+	// a fast drag is a swipe
+	gestures = run(frame{0, at(0, 0)}, frame{0.05, at(0, 50)}, frame{0.1, at(0, 100)}, frame{0.15, nil})
+	if want := []Type{DragStart, Drag, DragEnd, Swipe}; !reflect.DeepEqual(types(gestures), want) {
+		t.Fatalf("expected %v, got %+v", want, gestures)
+	}
+	if swipe := gestures[3]; swipe.Direction != rl.NewVector2(0, 1) || swipe.Velocity < 600 {
+		t.Errorf("expected a swipe down at 666 px/s, got %+v", swipe)
+	}
+}
+
+func TestPinch(t *testing.T) {
+	two := func(distance float32) []Pointer {
+		return []Pointer{
+			{ID: 3, Position: rl.NewVector2(100-distance/2, 100)},
+			{ID: 7, Position: rl.NewVector2(100+distance/2, 100)},
+		}
+	}
+	gestures := run(
+		frame{0, two(100)[:1]},
+		// the first pointer started a drag before the second went down
+		frame{0.1, []Pointer{{ID: 3, Position: rl.NewVector2(30, 100)}}},
+		frame{0.2, two(100)},
+		frame{0.3, two(150)},
+		frame{0.4, two(150)[1:]},
+		frame{0.45, nil},
+	)
+	if want := []Type{DragStart, DragEnd, Pinch}; !reflect.DeepEqual(types(gestures), want) {
+		t.Fatalf("expected %v, got %+v", want, gestures)
+	}
+	if pinch := gestures[2]; pinch.Scale != 1.5 || pinch.Position != rl.NewVector2(100, 100) {
+		t.Errorf("expected a pinch with scale 1.5 around (100, 100), got %+v", pinch)
+	}
+}
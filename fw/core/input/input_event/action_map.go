@@ -1,8 +1,14 @@
 package input
 
-import rl "github.com/gen2brain/raylib-go/raylib"
+import (
+	"gorl/fw/core/logging"
 
-// Actions
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Action is an abstract action like "move up" or "jump", which is caused by
+// one or more Triggers. The framework defines the actions below, games can add
+// their own at runtime using RegisterAction.
 type Action int32
 
 const (
@@ -15,45 +21,158 @@ const (
 	ActionClickUp
 	ActionMouseHover
 	ActionEscape
-	// Add other actions as needed
 	ActionZoomIn
 	ActionZoomOut
+	ActionMove
 )
 
-var ActionMap = map[Action][]Trigger{
-	ActionMoveUp: {
-		{InputType: InputTypeKey, TriggerType: TriggerTypeDown, Key: rl.KeyW},
-	},
-	ActionMoveDown: {
-		{InputType: InputTypeKey, TriggerType: TriggerTypeDown, Key: rl.KeyS},
-	},
-	ActionMoveLeft: {
-		{InputType: InputTypeKey, TriggerType: TriggerTypeDown, Key: rl.KeyA},
-	},
-	ActionMoveRight: {
-		{InputType: InputTypeKey, TriggerType: TriggerTypeDown, Key: rl.KeyD},
-	},
-	ActionClickDown: {
-		{InputType: InputTypeMouse, TriggerType: TriggerTypePressed, MouseButton: rl.MouseLeftButton},
-	},
-	ActionClickHeld: {
-		{InputType: InputTypeMouse, TriggerType: TriggerTypeDown, MouseButton: rl.MouseLeftButton},
-	},
-	ActionClickUp: {
-		{InputType: InputTypeMouse, TriggerType: TriggerTypeReleased, MouseButton: rl.MouseLeftButton},
-	},
-	ActionMouseHover: {
-		{InputType: InputTypeMouse, TriggerType: TriggerTypePassive},
-	},
-	ActionEscape: {
-		{InputType: InputTypeKey, TriggerType: TriggerTypeDown, Key: rl.KeyEscape},
-	},
+// actionNames holds the name of every action, indexed by the action.
+// Names are used to refer to actions in the bindings file.
+var actionNames = []string{
+	"move_up",
+	"move_down",
+	"move_left",
+	"move_right",
+	"click_down",
+	"click_held",
+	"click_up",
+	"mouse_hover",
+	"escape",
+	"zoom_in",
+	"zoom_out",
+	"move",
+}
 
-	ActionZoomIn: {
-		{InputType: InputTypeKey, TriggerType: TriggerTypeDown, Key: rl.KeyQ},
-	},
-	ActionZoomOut: {
-		{InputType: InputTypeKey, TriggerType: TriggerTypeDown, Key: rl.KeyE},
+var actionsByName = func() map[string]Action {
+	m := make(map[string]Action, len(actionNames))
+	for i, name := range actionNames {
+		m[name] = Action(i)
+	}
+	return m
+}()
+
+// RegisterAction registers a new, game defined action with the given name and
+// returns it. The name is used to save and load bindings, so it should not
+// change between versions of the game.
+//
+// Example:
+//
+//	var ActionJump = input.RegisterAction("jump")
+func RegisterAction(name string) Action {
+	if _, exists := actionsByName[name]; exists {
+		logging.Fatal("An action with name \"%v\" is already registered.", name)
+	}
+	action := Action(len(actionNames))
+	actionNames = append(actionNames, name)
+	actionsByName[name] = action
+	return action
+}
+
+// ActionByName returns the action registered with the given name.
+// The second return value is false if there is no such action.
+func ActionByName(name string) (Action, bool) {
+	action, ok := actionsByName[name]
+	return action, ok
+}
+
+// String returns the name of the action.
+func (a Action) String() string {
+	if a < 0 || int(a) >= len(actionNames) {
+		return "unknown"
+	}
+	return actionNames[a]
+}
+
+// BindingMap maps actions to the triggers that cause them.
+type BindingMap map[Action][]Trigger
+
+// Clone returns a deep copy of the binding map.
+func (m BindingMap) Clone() BindingMap {
+	clone := make(BindingMap, len(m))
+	for action, triggers := range m {
+		cloned := make([]Trigger, len(triggers))
+		for i, trigger := range triggers {
+			cloned[i] = trigger.Clone()
+		}
+		clone[action] = cloned
+	}
+	return clone
+}
+
+// DefaultContext is the name of the context holding the bindings of the
+// framework actions. It is active unless the active contexts are changed.
+const DefaultContext = "default"
+
+// contexts holds the default bindings of every registered context. Every
+// player starts with a copy of these, see PlayerBindings.
+var contexts = map[string]BindingMap{
+	DefaultContext: {
+		ActionMoveUp: {
+			{InputType: InputTypeKey, TriggerType: TriggerTypeDown, Key: rl.KeyW},
+		},
+		ActionMoveDown: {
+			{InputType: InputTypeKey, TriggerType: TriggerTypeDown, Key: rl.KeyS},
+		},
+		ActionMoveLeft: {
+			{InputType: InputTypeKey, TriggerType: TriggerTypeDown, Key: rl.KeyA},
+		},
+		ActionMoveRight: {
+			{InputType: InputTypeKey, TriggerType: TriggerTypeDown, Key: rl.KeyD},
+		},
+		// touching the screen clicks as well
+		ActionClickDown: {
+			{InputType: InputTypeMouse, TriggerType: TriggerTypePressed, MouseButton: rl.MouseLeftButton},
+			{InputType: InputTypeTouch, TriggerType: TriggerTypePressed},
+		},
+		ActionClickHeld: {
+			{InputType: InputTypeMouse, TriggerType: TriggerTypeDown, MouseButton: rl.MouseLeftButton},
+			{InputType: InputTypeTouch, TriggerType: TriggerTypeDown},
+		},
+		ActionClickUp: {
+			{InputType: InputTypeMouse, TriggerType: TriggerTypeReleased, MouseButton: rl.MouseLeftButton},
+			{InputType: InputTypeTouch, TriggerType: TriggerTypeReleased},
+		},
+		ActionMouseHover: {
+			{InputType: InputTypeMouse, TriggerType: TriggerTypePassive},
+		},
+		ActionEscape: {
+			{InputType: InputTypeKey, TriggerType: TriggerTypeDown, Key: rl.KeyEscape},
+		},
+		ActionZoomIn: {
+			{InputType: InputTypeKey, TriggerType: TriggerTypeDown, Key: rl.KeyQ},
+		},
+		ActionZoomOut: {
+			{InputType: InputTypeKey, TriggerType: TriggerTypeDown, Key: rl.KeyE},
+		},
+		// a movement vector, see InputEvent.Vector
+		ActionMove: {
+			KeyVector(rl.KeyW, rl.KeyS, rl.KeyA, rl.KeyD),
+			GamepadStick(rl.GamepadAxisLeftX),
+			DPadVector(),
+		},
 	},
-	// Add other action-trigger mappings
+}
+
+// RegisterContext registers a named set of default bindings, e.g. "gameplay"
+// or "menu". Contexts can be switched per player, only the bindings of the
+// active contexts cause actions. See PlayerBindings.SetContext.
+//
+// Players that already exist receive a copy of the bindings as well.
+func RegisterContext(name string, bindings BindingMap) {
+	if _, exists := contexts[name]; exists {
+		logging.Fatal("An input context with name \"%v\" is already registered.", name)
+	}
+	contexts[name] = bindings.Clone()
+	for _, player := range players {
+		player.contexts[name] = bindings.Clone()
+	}
+}
+
+// GetContextNames returns the names of all registered contexts.
+func GetContextNames() []string {
+	names := make([]string, 0, len(contexts))
+	for name := range contexts {
+		names = append(names, name)
+	}
+	return names
 }
@@ -0,0 +1,114 @@
+package input
+
+import (
+	"encoding/json"
+	"gorl/fw/core/logging"
+	"os"
+	"strconv"
+)
+
+// DefaultBindingsPath is the default path of the bindings file, next to the
+// settings file.
+const DefaultBindingsPath = "bindings.json"
+
+// bindingsFile is the structure of the bindings file.
+// Actions are referred to by name, so the file stays valid if actions are
+// added or reordered.
+type bindingsFile struct {
+	Players map[string]playerBindingsFile `json:"players"`
+}
+
+type playerBindingsFile struct {
+	ActiveContexts []string                        `json:"activeContexts"`
+	Contexts       map[string]map[string][]Trigger `json:"contexts"`
+	Deadzone       *float32                        `json:"deadzone,omitempty"`
+	ResponseCurve  *ResponseCurve                  `json:"responseCurve,omitempty"`
+}
+
+// SaveBindings writes the bindings, active contexts and analog settings of all
+// players to a JSON file at the given path.
+func SaveBindings(path string) error {
+	file := bindingsFile{Players: make(map[string]playerBindingsFile, len(players))}
+	for index, player := range players {
+		playerFile := playerBindingsFile{
+			ActiveContexts: player.activeContexts,
+			Contexts:       make(map[string]map[string][]Trigger, len(player.contexts)),
+			Deadzone:       &player.deadzone,
+			ResponseCurve:  &player.responseCurve,
+		}
+		for name, bindings := range player.contexts {
+			named := make(map[string][]Trigger, len(bindings))
+			for action, triggers := range bindings {
+				named[action.String()] = triggers
+			}
+			playerFile.Contexts[name] = named
+		}
+		file.Players[strconv.Itoa(index)] = playerFile
+	}
+
+	data, err := json.MarshalIndent(file, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// LoadBindings reads the bindings of the players from a JSON file written by
+// SaveBindings. The file only overrides the bindings it contains, so actions
+// and contexts added since it was saved keep their defaults. Contexts and
+// actions that are no longer registered are skipped with a warning.
+//
+// Games should register their actions and contexts before loading the
+// bindings.
+func LoadBindings(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	file := bindingsFile{}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return err
+	}
+
+	for key, playerFile := range file.Players {
+		index, err := strconv.Atoi(key)
+		if err != nil {
+			logging.Warning("Skipping bindings of invalid player \"%v\".", key)
+			continue
+		}
+		player := Player(index)
+
+		for name, named := range playerFile.Contexts {
+			bindings, ok := player.contexts[name]
+			if !ok {
+				logging.Warning("Skipping bindings of unknown input context \"%v\".", name)
+				continue
+			}
+			for actionName, triggers := range named {
+				action, ok := ActionByName(actionName)
+				if !ok {
+					logging.Warning("Skipping bindings of unknown action \"%v\".", actionName)
+					continue
+				}
+				bindings[action] = triggers
+			}
+		}
+
+		if playerFile.Deadzone != nil {
+			player.SetDeadzone(*playerFile.Deadzone)
+		}
+		if playerFile.ResponseCurve != nil {
+			player.SetResponseCurve(*playerFile.ResponseCurve)
+		}
+
+		if playerFile.ActiveContexts != nil {
+			player.activeContexts = []string{}
+			for _, name := range playerFile.ActiveContexts {
+				if _, ok := player.contexts[name]; ok {
+					player.activeContexts = append(player.activeContexts, name)
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package input
+
+import (
+	"gorl/fw/core/clock"
+)
+
+// CaptureCallback is called with the captured trigger when a capture ends.
+// ok is false if the capture was cancelled.
+type CaptureCallback func(trigger Trigger, ok bool)
+
+type captureState struct {
+	callback   CaptureCallback
+	startFrame uint64
+}
+
+// capture is the running capture, or nil.
+var capture *captureState
+
+// CaptureNextTrigger listens for the next key, mouse button or gamepad button
+// the player presses, and passes it to the callback as a pressed trigger. This is used
+// to let players rebind actions, e.g. "press a key for jump".
+//
+// While capturing, no input events are dispatched. Pressing escape cancels
+// the capture. Input of the frame the capture was started in is ignored, so
+// the click on a "rebind" button is not captured.
+func CaptureNextTrigger(callback CaptureCallback) {
+	if capture != nil {
+		capture.callback(Trigger{}, false)
+	}
+	capture = &captureState{callback: callback, startFrame: clock.FrameCount()}
+}
+
+// CaptureRebind captures the next trigger and uses it to replace the trigger
+// at the given index of the action in the given context (see Rebind). The
+// trigger type of the replaced trigger is kept. done is called when the
+// capture ends and may be nil.
+func (p *PlayerBindings) CaptureRebind(context string, action Action, index int, done func(ok bool)) {
+	CaptureNextTrigger(func(trigger Trigger, ok bool) {
+		if ok {
+			if existing := p.GetBindings(context, action); index >= 0 && index < len(existing) {
+				trigger.TriggerType = existing[index].TriggerType
+			}
+			p.Rebind(context, action, index, trigger)
+		}
+		if done != nil {
+			done(ok)
+		}
+	})
+}
+
+// CancelCapture cancels the running capture, if any.
+func CancelCapture() {
+	endCapture(Trigger{}, false)
+}
+
+// IsCapturing returns true if a capture is running.
+func IsCapturing() bool {
+	return capture != nil
+}
+
+// CompleteCapture ends the running capture with the given trigger, or cancels
+// it if ok is false. It is called by the input handling, which polls the
+// devices. Input of the frame the capture was started in is ignored, in that
+// case (or if there is no capture) CompleteCapture returns false.
+func CompleteCapture(trigger Trigger, ok bool) bool {
+	if capture == nil || clock.FrameCount() == capture.startFrame {
+		return false
+	}
+	endCapture(trigger, ok)
+	return true
+}
+
+// endCapture ends the running capture with the given result.
+func endCapture(trigger Trigger, ok bool) {
+	if capture == nil {
+		return
+	}
+	callback := capture.callback
+	capture = nil
+	callback(trigger, ok)
+}
@@ -0,0 +1,200 @@
+package input
+
+import (
+	"fmt"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// ============================================================================
+// Composite triggers combine several inputs into one value.
+// ----------------------------------------------------------------------------
+//
+//		An axis composite turns two triggers into a value in [-1, 1], e.g.
+//		A/D into a horizontal axis. A vector composite turns four triggers
+//		into a 2D vector, e.g. WASD or the d-pad, and a gamepad stick reads
+//		both axes of a stick at once. Vectors are normalized, so diagonal
+//		movement is not faster.
+//
+//		The value is passed on in InputEvent.Value (axis) and
+//		InputEvent.Vector (vector). Composites with the trigger type down are
+//		active while their value is not zero, passive composites are always
+//		active.
+//
+// ============================================================================
+
+// AxisComposite returns a trigger whose value is 1 while the positive trigger
+// is active and -1 while the negative trigger is active. Parts that read an
+// axis contribute their analog value.
+func AxisComposite(negative, positive Trigger) Trigger {
+	return Trigger{
+		InputType:   InputTypeAxisComposite,
+		TriggerType: TriggerTypeDown,
+		Parts:       []Trigger{negative, positive},
+	}
+}
+
+// VectorComposite returns a trigger whose value is a normalized 2D vector
+// built from four triggers. Up is negative y, as on screen.
+func VectorComposite(up, down, left, right Trigger) Trigger {
+	return Trigger{
+		InputType:   InputTypeVectorComposite,
+		TriggerType: TriggerTypeDown,
+		Parts:       []Trigger{up, down, left, right},
+	}
+}
+
+// KeyVector returns a vector composite of four keys, e.g.
+// KeyVector(rl.KeyW, rl.KeyS, rl.KeyA, rl.KeyD).
+func KeyVector(up, down, left, right int32) Trigger {
+	key := func(k int32) Trigger {
+		return Trigger{InputType: InputTypeKey, TriggerType: TriggerTypeDown, Key: k}
+	}
+	return VectorComposite(key(up), key(down), key(left), key(right))
+}
+
+// DPadVector returns a vector composite of the d-pad of the gamepad.
+func DPadVector() Trigger {
+	button := func(b int32) Trigger {
+		return Trigger{InputType: InputTypeGamepad, TriggerType: TriggerTypeDown, GamepadButton: b}
+	}
+	return VectorComposite(
+		button(rl.GamepadButtonLeftFaceUp), button(rl.GamepadButtonLeftFaceDown),
+		button(rl.GamepadButtonLeftFaceLeft), button(rl.GamepadButtonLeftFaceRight),
+	)
+}
+
+// GamepadStick returns a trigger that reads both axes of a stick as a vector.
+// xAxis is the horizontal axis of the stick (rl.GamepadAxisLeftX or
+// rl.GamepadAxisRightX), the vertical axis is the one after it. The deadzone
+// and response curve of the player are applied to the length of the vector.
+func GamepadStick(xAxis int32) Trigger {
+	return Trigger{InputType: InputTypeGamepadStick, TriggerType: TriggerTypeDown, GamepadAxis: xAxis}
+}
+
+// Chord returns a copy of the trigger that is only active while the given
+// modifier keys are held, e.g. Chord(saveTrigger, rl.KeyLeftControl).
+func Chord(trigger Trigger, modifiers ...int32) Trigger {
+	trigger.Modifiers = append(append([]int32{}, trigger.Modifiers...), modifiers...)
+	return trigger
+}
+
+// modifierPair maps the right variant of a modifier key to the left one, so
+// both can be compared. Other keys are returned unchanged.
+func modifierPair(key int32) int32 {
+	switch key {
+	case rl.KeyRightControl:
+		return rl.KeyLeftControl
+	case rl.KeyRightShift:
+		return rl.KeyLeftShift
+	case rl.KeyRightAlt:
+		return rl.KeyLeftAlt
+	case rl.KeyRightSuper:
+		return rl.KeyLeftSuper
+	}
+	return key
+}
+
+// OtherModifier returns the other variant of a modifier key, e.g. the right
+// control key for the left one. Returns 0 for other keys.
+func OtherModifier(key int32) int32 {
+	switch key {
+	case rl.KeyLeftControl:
+		return rl.KeyRightControl
+	case rl.KeyRightControl:
+		return rl.KeyLeftControl
+	case rl.KeyLeftShift:
+		return rl.KeyRightShift
+	case rl.KeyRightShift:
+		return rl.KeyLeftShift
+	case rl.KeyLeftAlt:
+		return rl.KeyRightAlt
+	case rl.KeyRightAlt:
+		return rl.KeyLeftAlt
+	case rl.KeyLeftSuper:
+		return rl.KeyRightSuper
+	case rl.KeyRightSuper:
+		return rl.KeyLeftSuper
+	}
+	return 0
+}
+
+// ============================================================================
+//		INTERACTIONS
+// ============================================================================
+
+// Interaction changes when a trigger is active, based on the timing of its
+// input. The trigger type is ignored for triggers with an interaction, only
+// whether the input is held matters.
+type Interaction int32
+
+const (
+	// InteractionNone uses the trigger type as usual.
+	InteractionNone Interaction = iota
+	// InteractionHold is active every frame once the input was held for the
+	// duration.
+	InteractionHold
+	// InteractionTap is active when the input is released before the
+	// duration passed.
+	InteractionTap
+	// InteractionDoubleTap is active when the input is pressed a second time
+	// within the duration.
+	InteractionDoubleTap
+	// InteractionLongPress is active once, when the input was held for the
+	// duration.
+	InteractionLongPress
+)
+
+var interactionNames = []string{"none", "hold", "tap", "doubleTap", "longPress"}
+
+// DefaultDuration returns the duration of the interaction in seconds, if the
+// trigger does not set one.
+func (i Interaction) DefaultDuration() float32 {
+	switch i {
+	case InteractionTap:
+		return 0.2
+	case InteractionDoubleTap:
+		return 0.3
+	}
+	return 0.5
+}
+
+// String returns the name of the interaction.
+func (i Interaction) String() string {
+	if i < 0 || int(i) >= len(interactionNames) {
+		return "unknown"
+	}
+	return interactionNames[i]
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (i Interaction) MarshalText() ([]byte, error) {
+	return []byte(i.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (i *Interaction) UnmarshalText(text []byte) error {
+	for index, name := range interactionNames {
+		if name == string(text) {
+			*i = Interaction(index)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown interaction %q", text)
+}
+
+// WithInteraction returns a copy of the trigger with the given interaction.
+// A duration of 0 uses the default duration of the interaction.
+func WithInteraction(trigger Trigger, interaction Interaction, duration float32) Trigger {
+	trigger.Interaction = interaction
+	trigger.Duration = duration
+	return trigger
+}
+
+// GetDuration returns the duration of the interaction of the trigger.
+func (t Trigger) GetDuration() float32 {
+	if t.Duration > 0 {
+		return t.Duration
+	}
+	return t.Interaction.DefaultDuration()
+}
@@ -0,0 +1,148 @@
+package input
+
+import (
+	"fmt"
+	"math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// DeviceKeyboardMouse is the device of input events caused by the keyboard or
+// the mouse. Events caused by a gamepad carry the index of the gamepad.
+const DeviceKeyboardMouse int32 = -1
+
+// DeviceTouch is the device of input events caused by touch and gesture
+// triggers.
+const DeviceTouch int32 = -2
+
+// DefaultDeadzone is the deadzone of a new player, see
+// PlayerBindings.SetDeadzone.
+const DefaultDeadzone float32 = 0.15
+
+// ResponseCurve shapes the value of an analog axis after the deadzone is
+// applied. Steeper curves give finer control near the center of a stick.
+type ResponseCurve int32
+
+const (
+	ResponseCurveLinear ResponseCurve = iota
+	ResponseCurveQuadratic
+	ResponseCurveCubic
+)
+
+var responseCurveNames = []string{"linear", "quadratic", "cubic"}
+
+// String returns the name of the response curve.
+func (c ResponseCurve) String() string {
+	if c < 0 || int(c) >= len(responseCurveNames) {
+		return "unknown"
+	}
+	return responseCurveNames[c]
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (c ResponseCurve) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (c *ResponseCurve) UnmarshalText(text []byte) error {
+	for i, name := range responseCurveNames {
+		if name == string(text) {
+			*c = ResponseCurve(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown response curve %q", text)
+}
+
+// Apply applies the curve to a value in the range [-1, 1], keeping its sign.
+func (c ResponseCurve) Apply(value float32) float32 {
+	magnitude := float32(math.Abs(float64(value)))
+	switch c {
+	case ResponseCurveQuadratic:
+		magnitude = magnitude * magnitude
+	case ResponseCurveCubic:
+		magnitude = magnitude * magnitude * magnitude
+	}
+	if value < 0 {
+		return -magnitude
+	}
+	return magnitude
+}
+
+// IsTriggerAxis returns true if the axis is an analog trigger rather than a
+// stick axis. Raylib reports triggers in the range [-1, 1] with -1 at rest.
+func IsTriggerAxis(axis int32) bool {
+	return axis == rl.GamepadAxisLeftTrigger || axis == rl.GamepadAxisRightTrigger
+}
+
+// SetGamepad assigns the gamepad with the given index to the player. Gamepad
+// triggers of the player only react to this gamepad. By default, player n
+// uses gamepad n.
+func (p *PlayerBindings) SetGamepad(gamepad int32) {
+	p.gamepad = gamepad
+}
+
+// GetGamepad returns the index of the gamepad assigned to the player.
+func (p *PlayerBindings) GetGamepad() int32 {
+	return p.gamepad
+}
+
+// SetDeadzone sets the deadzone of the analog axes of the player, in the
+// range [0, 1). Axis values within the deadzone are reported as 0, values
+// outside it are rescaled to start at 0.
+func (p *PlayerBindings) SetDeadzone(deadzone float32) {
+	p.deadzone = float32(math.Max(0, math.Min(float64(deadzone), 0.99)))
+}
+
+// GetDeadzone returns the deadzone of the analog axes of the player.
+func (p *PlayerBindings) GetDeadzone() float32 {
+	return p.deadzone
+}
+
+// SetResponseCurve sets the response curve of the analog axes of the player.
+func (p *PlayerBindings) SetResponseCurve(curve ResponseCurve) {
+	p.responseCurve = curve
+}
+
+// GetResponseCurve returns the response curve of the analog axes of the
+// player.
+func (p *PlayerBindings) GetResponseCurve() ResponseCurve {
+	return p.responseCurve
+}
+
+// ProcessAxis turns the raw value of a gamepad axis into the value reported
+// to the game: trigger axes are mapped to [0, 1], then the deadzone and the
+// response curve of the player are applied.
+func (p *PlayerBindings) ProcessAxis(axis int32, raw float32) float32 {
+	value := raw
+	if IsTriggerAxis(axis) {
+		value = (raw + 1) / 2
+	}
+	value = float32(math.Max(-1, math.Min(float64(value), 1)))
+
+	magnitude := float32(math.Abs(float64(value)))
+	if magnitude <= p.deadzone {
+		return 0
+	}
+	magnitude = (magnitude - p.deadzone) / (1 - p.deadzone)
+	if value < 0 {
+		magnitude = -magnitude
+	}
+	return p.responseCurve.Apply(magnitude)
+}
+
+// ProcessStick turns the raw values of both axes of a stick into the vector
+// reported to the game. The deadzone and the response curve of the player are
+// applied to the length of the vector, so the deadzone is round and the
+// direction is kept. The result has a length of at most 1.
+func (p *PlayerBindings) ProcessStick(x, y float32) rl.Vector2 {
+	vector := rl.NewVector2(x, y)
+	length := rl.Vector2Length(vector)
+	if length <= p.deadzone {
+		return rl.Vector2Zero()
+	}
+	scaled := float32(math.Min(float64((length-p.deadzone)/(1-p.deadzone)), 1))
+	scaled = p.responseCurve.Apply(scaled)
+	return rl.Vector2Scale(vector, scaled/length)
+}
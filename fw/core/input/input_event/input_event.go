@@ -8,21 +8,51 @@ import (
 // It has a TriggerType which describes what kind of trigger caused the event,
 // and determines what data the event has.
 type InputEvent struct {
-	Action         Action
+	Action Action
+	// Player is the index of the player whose bindings caused the event,
+	// see Player.
+	Player int
+	// Device is the index of the gamepad that caused the event, or
+	// DeviceKeyboardMouse.
+	Device int32
+	// Value is the processed axis value for events caused by a gamepad axis or
+	// an axis composite, in the range [-1, 1]. For vector composites it is the
+	// length of the Vector, for all other events 1.
+	Value float32
+	// Vector is the normalized value of events caused by a vector composite
+	// or a gamepad stick, see composite.go.
+	Vector         rl.Vector2
 	cursorPosition rl.Vector2
-	// TODO: there might be more fields necessary here, especially to support
-	// other input devices such as gamepads.
+
+	// worldPosition is the cursor position in the world of the receiver that
+	// currently handles the event, see GetWorldMousePosition.
+	worldPosition    rl.Vector2
+	hasWorldPosition bool
 }
 
 func NewInputEvent(action Action, cursorPosition rl.Vector2) *InputEvent {
-	return &InputEvent{Action: action, cursorPosition: cursorPosition}
+	return &InputEvent{Action: action, Device: DeviceKeyboardMouse, Value: 1, cursorPosition: cursorPosition}
 }
 
+// GetScreenSpaceMousePosition returns the position of the cursor on the
+// screen, in window coordinates.
 func (e *InputEvent) GetScreenSpaceMousePosition() rl.Vector2 {
-	// FIXME: this is called during update and not during render, so there is not active render stage.
-	// Approaches:
-	// 1. make sure this function is called during render.
-	// 2. have only a single camera config for all stages, so we don't need to know the active stage.
-	// 3. have each entity know its render stage. this could be accomplished by "learning", e.g. every time the entity is drawn, it learns the current render stage.
 	return e.cursorPosition
 }
+
+// GetWorldMousePosition returns the position of the cursor in the world, as
+// seen by the camera the receiver of the event was drawn with. If the receiver
+// was drawn by several cameras, e.g. in split-screen, the camera under the
+// cursor is used. The second return value is false if the receiver was not
+// drawn this frame.
+func (e *InputEvent) GetWorldMousePosition() (rl.Vector2, bool) {
+	return e.worldPosition, e.hasWorldPosition
+}
+
+// SetWorldMousePosition sets the world position of the cursor for the next
+// receiver of the event. It is called by the input handling, see
+// GetWorldMousePosition.
+func (e *InputEvent) SetWorldMousePosition(position rl.Vector2, ok bool) {
+	e.worldPosition = position
+	e.hasWorldPosition = ok
+}
@@ -0,0 +1,227 @@
+package input
+
+import (
+	"gorl/fw/core/clock"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+func keyTrigger(key int32) Trigger {
+	return Trigger{InputType: InputTypeKey, TriggerType: TriggerTypePressed, Key: key}
+}
+
+var (
+	actionJump  = RegisterAction("test_jump")
+	actionFire  = RegisterAction("test_fire")
+	actionPause = RegisterAction("test_pause")
+)
+
+func init() {
+	RegisterContext("test_gameplay", BindingMap{
+		actionJump: {keyTrigger(rl.KeySpace)},
+		actionFire: {keyTrigger(rl.KeyF)},
+	})
+	RegisterContext("test_menu", BindingMap{
+		actionPause: {keyTrigger(rl.KeyP)},
+	})
+}
+
+func TestActionRegistry(t *testing.T) {
+	if action, ok := ActionByName("test_jump"); !ok || action != actionJump {
+		t.Errorf("registered action not found by name")
+	}
+	if ActionMoveUp.String() != "move_up" || actionFire.String() != "test_fire" {
+		t.Errorf("unexpected action names %v, %v", ActionMoveUp, actionFire)
+	}
+	if _, ok := ActionByName("missing"); ok {
+		t.Errorf("found an action that was never registered")
+	}
+}
+
+func TestContexts(t *testing.T) {
+	player := Player(0)
+	defer player.ResetAll()
+
+	player.SetContext("test_gameplay")
+	player.EnableContext("test_menu")
+	active := player.GetActiveBindings()
+	if _, ok := active[actionJump]; !ok {
+		t.Errorf("gameplay bindings are not active")
+	}
+	if _, ok := active[ActionMoveUp]; ok {
+		t.Errorf("default bindings must not be active after SetContext")
+	}
+
+	player.DisableContext("test_menu")
+	if player.IsContextActive("test_menu") {
+		t.Errorf("menu context is still active")
+	}
+	if _, ok := player.GetActiveBindings()[actionPause]; ok {
+		t.Errorf("menu bindings are still active")
+	}
+}
+
+func TestRebindingAndConflicts(t *testing.T) {
+	first := Player(0)
+	second := Player(1)
+	defer first.ResetAll()
+	defer RemovePlayer(1)
+
+	first.Rebind("test_gameplay", actionJump, 0, keyTrigger(rl.KeyF))
+	if got := first.GetBindings("test_gameplay", actionJump); !reflect.DeepEqual(got, []Trigger{keyTrigger(rl.KeyF)}) {
+		t.Errorf("unexpected bindings after rebind: %v", got)
+	}
+	if got := second.GetBindings("test_gameplay", actionJump); !reflect.DeepEqual(got, []Trigger{keyTrigger(rl.KeySpace)}) {
+		t.Errorf("rebinding must only affect one player, got %v", got)
+	}
+
+	conflicts := first.GetConflicts("test_gameplay")
+	if len(conflicts) != 1 || !reflect.DeepEqual(conflicts[0].Actions, []Action{actionJump, actionFire}) {
+		t.Errorf("expected jump and fire to conflict, got %+v", conflicts)
+	}
+	if others := first.GetConflictsWith("test_gameplay", actionFire, keyTrigger(rl.KeyF)); !reflect.DeepEqual(others, []Action{actionJump}) {
+		t.Errorf("unexpected conflicts with F: %v", others)
+	}
+
+	first.Unbind("test_gameplay", actionFire, keyTrigger(rl.KeyF))
+	if len(first.GetConflicts("test_gameplay")) != 0 {
+		t.Errorf("conflict was not resolved by unbinding")
+	}
+
+	first.ResetContext("test_gameplay")
+	if got := first.GetBindings("test_gameplay", actionFire); !reflect.DeepEqual(got, []Trigger{keyTrigger(rl.KeyF)}) {
+		t.Errorf("context was not reset, got %v", got)
+	}
+}
+
+func TestCaptureRebind(t *testing.T) {
+	player := Player(0)
+	defer player.ResetAll()
+
+	held := Trigger{InputType: InputTypeKey, TriggerType: TriggerTypeDown, Key: rl.KeyW}
+	player.SetBindings("test_gameplay", actionJump, []Trigger{held})
+
+	result := ""
+	player.CaptureRebind("test_gameplay", actionJump, 0, func(ok bool) {
+		if ok {
+			result = "bound"
+		} else {
+			result = "cancelled"
+		}
+	})
+	if !IsCapturing() {
+		t.Fatalf("capture did not start")
+	}
+	if CompleteCapture(keyTrigger(rl.KeyJ), true) {
+		t.Errorf("input of the frame the capture started in must be ignored")
+	}
+	clock.Advance(0)
+	if !CompleteCapture(keyTrigger(rl.KeyJ), true) {
+		t.Errorf("capture was not completed")
+	}
+
+	want := Trigger{InputType: InputTypeKey, TriggerType: TriggerTypeDown, Key: rl.KeyJ}
+	if got := player.GetBindings("test_gameplay", actionJump); result != "bound" || !reflect.DeepEqual(got, []Trigger{want}) {
+		t.Errorf("capture did not rebind (%v): %v", result, got)
+	}
+
+	player.CaptureRebind("test_gameplay", actionJump, 0, func(ok bool) {
+		if !ok {
+			result = "cancelled"
+		}
+	})
+	CancelCapture()
+	if result != "cancelled" || IsCapturing() {
+		t.Errorf("capture was not cancelled")
+	}
+}
+
+func TestSaveAndLoadBindings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultBindingsPath)
+	first := Player(0)
+	second := Player(1)
+	defer first.ResetAll()
+	defer RemovePlayer(1)
+
+	first.SetBindings("test_gameplay", actionJump, []Trigger{keyTrigger(rl.KeyUp)})
+	second.SetBindings("test_gameplay", actionFire, []Trigger{
+		{InputType: InputTypeMouse, TriggerType: TriggerTypeDown, MouseButton: rl.MouseButtonRight},
+	})
+	second.SetContext("test_menu")
+	if err := SaveBindings(path); err != nil {
+		t.Fatalf("failed to save bindings: %v", err)
+	}
+
+	first.ResetAll()
+	RemovePlayer(1)
+	if err := LoadBindings(path); err != nil {
+		t.Fatalf("failed to load bindings: %v", err)
+	}
+
+	if got := Player(0).GetBindings("test_gameplay", actionJump); !reflect.DeepEqual(got, []Trigger{keyTrigger(rl.KeyUp)}) {
+		t.Errorf("player 0 bindings were not restored: %v", got)
+	}
+	loaded := Player(1)
+	if got := loaded.GetBindings("test_gameplay", actionFire); len(got) != 1 || got[0].MouseButton != rl.MouseButtonRight {
+		t.Errorf("player 1 bindings were not restored: %v", got)
+	}
+	if !reflect.DeepEqual(loaded.GetActiveContexts(), []string{"test_menu"}) {
+		t.Errorf("active contexts were not restored: %v", loaded.GetActiveContexts())
+	}
+}
+
+func TestProcessAxis(t *testing.T) {
+	player := Player(0)
+	defer player.SetDeadzone(DefaultDeadzone)
+	defer player.SetResponseCurve(ResponseCurveLinear)
+
+	player.SetDeadzone(0.5)
+	if v := player.ProcessAxis(rl.GamepadAxisLeftX, 0.4); v != 0 {
+		t.Errorf("value within the deadzone must be 0, got %v", v)
+	}
+	if v := player.ProcessAxis(rl.GamepadAxisLeftX, -0.75); v != -0.5 {
+		t.Errorf("value outside the deadzone must be rescaled, got %v", v)
+	}
+	player.SetResponseCurve(ResponseCurveQuadratic)
+	if v := player.ProcessAxis(rl.GamepadAxisLeftX, -0.75); v != -0.25 {
+		t.Errorf("response curve must keep the sign, got %v", v)
+	}
+
+	player.SetDeadzone(0)
+	player.SetResponseCurve(ResponseCurveLinear)
+	if v := player.ProcessAxis(rl.GamepadAxisLeftTrigger, -1); v != 0 {
+		t.Errorf("trigger at rest must be 0, got %v", v)
+	}
+	if v := player.ProcessAxis(rl.GamepadAxisLeftTrigger, 1); v != 1 {
+		t.Errorf("fully pressed trigger must be 1, got %v", v)
+	}
+}
+
+// focusRecorder records the focus changes it is notified of.
+type focusRecorder struct {
+	changes []bool
+}
+
+func (r *focusRecorder) OnTextInput(event *TextEvent)    {}
+func (r *focusRecorder) OnTextFocusChanged(focused bool) { r.changes = append(r.changes, focused) }
+
+func TestTextFocus(t *testing.T) {
+	a, b := &focusRecorder{}, &focusRecorder{}
+	SetTextFocus(a)
+	SetTextFocus(b)
+	// releasing a receiver without the focus does nothing
+	ReleaseTextFocus(a)
+	if !HasTextFocus(b) || HasTextFocus(a) {
+		t.Fatalf("expected b to have the focus")
+	}
+	ReleaseTextFocus(b)
+	if GetTextFocus() != nil {
+		t.Errorf("expected no focus after release")
+	}
+	if len(a.changes) != 2 || !a.changes[0] || a.changes[1] || len(b.changes) != 2 {
+		t.Errorf("unexpected focus changes: a %v, b %v", a.changes, b.changes)
+	}
+}
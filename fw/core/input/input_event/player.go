@@ -0,0 +1,304 @@
+package input
+
+import (
+	"gorl/fw/core/logging"
+	"sort"
+)
+
+// PlayerBindings holds the bindings and the active contexts of a single
+// player. Every player starts with a copy of the default bindings of all
+// registered contexts, which can then be rebound individually.
+//
+// Single player games only use Player(0).
+type PlayerBindings struct {
+	index          int
+	contexts       map[string]BindingMap
+	activeContexts []string
+
+	// gamepad settings, see gamepad.go
+	gamepad       int32
+	deadzone      float32
+	responseCurve ResponseCurve
+}
+
+// players holds the bindings of all players, by player index.
+var players = map[int]*PlayerBindings{}
+
+func init() {
+	// the first player always exists, so single player games don't have to
+	// create it.
+	Player(0)
+}
+
+// Player returns the bindings of the player with the given index, creating
+// them if the player has none yet.
+func Player(index int) *PlayerBindings {
+	player, ok := players[index]
+	if !ok {
+		player = &PlayerBindings{
+			index:          index,
+			contexts:       make(map[string]BindingMap, len(contexts)),
+			activeContexts: []string{DefaultContext},
+			gamepad:        int32(index),
+			deadzone:       DefaultDeadzone,
+		}
+		for name, bindings := range contexts {
+			player.contexts[name] = bindings.Clone()
+		}
+		players[index] = player
+	}
+	return player
+}
+
+// Players returns the bindings of all players, ordered by player index.
+func Players() []*PlayerBindings {
+	result := make([]*PlayerBindings, 0, len(players))
+	for _, player := range players {
+		result = append(result, player)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].index < result[j].index })
+	return result
+}
+
+// RemovePlayer removes the bindings of the player with the given index.
+// The player no longer causes input events.
+func RemovePlayer(index int) {
+	delete(players, index)
+}
+
+// Index returns the index of the player.
+func (p *PlayerBindings) Index() int {
+	return p.index
+}
+
+// context returns the bindings of the context with the given name, logging an
+// error if the context does not exist.
+func (p *PlayerBindings) context(name string) (BindingMap, bool) {
+	bindings, ok := p.contexts[name]
+	if !ok {
+		logging.Error("Input context with name \"%v\" not found.", name)
+	}
+	return bindings, ok
+}
+
+// ============================================================================
+//		CONTEXTS
+// ============================================================================
+
+// SetContext makes the given context the only active context of the player.
+func (p *PlayerBindings) SetContext(name string) {
+	if _, ok := p.context(name); !ok {
+		return
+	}
+	p.activeContexts = []string{name}
+}
+
+// EnableContext activates the given context in addition to the already
+// active ones.
+func (p *PlayerBindings) EnableContext(name string) {
+	if _, ok := p.context(name); !ok || p.IsContextActive(name) {
+		return
+	}
+	p.activeContexts = append(p.activeContexts, name)
+}
+
+// DisableContext deactivates the given context.
+func (p *PlayerBindings) DisableContext(name string) {
+	for i, active := range p.activeContexts {
+		if active == name {
+			p.activeContexts = append(p.activeContexts[:i], p.activeContexts[i+1:]...)
+			return
+		}
+	}
+}
+
+// IsContextActive returns true if the given context is active.
+func (p *PlayerBindings) IsContextActive(name string) bool {
+	for _, active := range p.activeContexts {
+		if active == name {
+			return true
+		}
+	}
+	return false
+}
+
+// GetActiveContexts returns the names of the active contexts, in the order
+// they were activated. The returned slice should not be modified.
+func (p *PlayerBindings) GetActiveContexts() []string {
+	return p.activeContexts
+}
+
+// GetActiveBindings returns the bindings of all active contexts, merged into a
+// single map.
+func (p *PlayerBindings) GetActiveBindings() BindingMap {
+	merged := BindingMap{}
+	for _, name := range p.activeContexts {
+		for action, triggers := range p.contexts[name] {
+			merged[action] = append(merged[action], triggers...)
+		}
+	}
+	return merged
+}
+
+// ============================================================================
+//		REBINDING
+// ============================================================================
+
+// GetBindings returns the triggers of the action in the given context.
+// The returned slice should not be modified.
+func (p *PlayerBindings) GetBindings(context string, action Action) []Trigger {
+	bindings, ok := p.context(context)
+	if !ok {
+		return nil
+	}
+	return bindings[action]
+}
+
+// SetBindings replaces all triggers of the action in the given context.
+func (p *PlayerBindings) SetBindings(context string, action Action, triggers []Trigger) {
+	bindings, ok := p.context(context)
+	if !ok {
+		return
+	}
+	if len(triggers) == 0 {
+		delete(bindings, action)
+		return
+	}
+	bindings[action] = append([]Trigger{}, triggers...)
+}
+
+// Bind adds a trigger to the action in the given context.
+func (p *PlayerBindings) Bind(context string, action Action, trigger Trigger) {
+	bindings, ok := p.context(context)
+	if !ok {
+		return
+	}
+	bindings[action] = append(bindings[action], trigger)
+}
+
+// Rebind replaces the trigger at the given index of the action in the given
+// context. If the index is out of range, the trigger is added instead.
+func (p *PlayerBindings) Rebind(context string, action Action, index int, trigger Trigger) {
+	bindings, ok := p.context(context)
+	if !ok {
+		return
+	}
+	if index < 0 || index >= len(bindings[action]) {
+		bindings[action] = append(bindings[action], trigger)
+		return
+	}
+	bindings[action][index] = trigger
+}
+
+// Unbind removes all triggers of the action in the given context that use
+// the same physical input as the given trigger.
+func (p *PlayerBindings) Unbind(context string, action Action, trigger Trigger) {
+	bindings, ok := p.context(context)
+	if !ok {
+		return
+	}
+	kept := []Trigger{}
+	for _, t := range bindings[action] {
+		if !t.SameInput(trigger) {
+			kept = append(kept, t)
+		}
+	}
+	bindings[action] = kept
+}
+
+// ResetContext restores the default bindings of the given context.
+func (p *PlayerBindings) ResetContext(context string) {
+	defaults, ok := contexts[context]
+	if !ok {
+		logging.Error("Input context with name \"%v\" not found.", context)
+		return
+	}
+	p.contexts[context] = defaults.Clone()
+}
+
+// ResetAll restores the default bindings of all contexts and the default
+// deadzone and response curve, and activates only the default context.
+func (p *PlayerBindings) ResetAll() {
+	for name, defaults := range contexts {
+		p.contexts[name] = defaults.Clone()
+	}
+	p.activeContexts = []string{DefaultContext}
+	p.deadzone = DefaultDeadzone
+	p.responseCurve = ResponseCurveLinear
+}
+
+// ============================================================================
+//		CONFLICTS
+// ============================================================================
+
+// Conflict describes a physical input that is bound to several actions in the
+// same context with the same trigger type.
+type Conflict struct {
+	Trigger Trigger
+	Actions []Action
+}
+
+// GetConflictsWith returns the actions other than the given one, that the
+// trigger is already bound to in the given context with the same trigger
+// type. Use it to warn the player before rebinding.
+func (p *PlayerBindings) GetConflictsWith(context string, action Action, trigger Trigger) []Action {
+	bindings, ok := p.context(context)
+	if !ok {
+		return nil
+	}
+	conflicts := []Action{}
+	for other, triggers := range bindings {
+		if other == action {
+			continue
+		}
+		for _, t := range triggers {
+			if t.SameInput(trigger) && t.TriggerType == trigger.TriggerType {
+				conflicts = append(conflicts, other)
+				break
+			}
+		}
+	}
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i] < conflicts[j] })
+	return conflicts
+}
+
+// GetConflicts returns all conflicts in the given context.
+func (p *PlayerBindings) GetConflicts(context string) []Conflict {
+	bindings, ok := p.context(context)
+	if !ok {
+		return nil
+	}
+
+	// iterate in a stable order, so the result does not depend on map order
+	actions := make([]Action, 0, len(bindings))
+	for action := range bindings {
+		actions = append(actions, action)
+	}
+	sort.Slice(actions, func(i, j int) bool { return actions[i] < actions[j] })
+
+	conflicts := []Conflict{}
+	for _, action := range actions {
+		for _, trigger := range bindings[action] {
+			if trigger.TriggerType == TriggerTypePassive || isReported(conflicts, trigger) {
+				continue
+			}
+			others := p.GetConflictsWith(context, action, trigger)
+			if len(others) > 0 {
+				conflicts = append(conflicts, Conflict{
+					Trigger: trigger,
+					Actions: append([]Action{action}, others...),
+				})
+			}
+		}
+	}
+	return conflicts
+}
+
+func isReported(conflicts []Conflict, trigger Trigger) bool {
+	for _, c := range conflicts {
+		if c.Trigger.SameInput(trigger) && c.Trigger.TriggerType == trigger.TriggerType {
+			return true
+		}
+	}
+	return false
+}
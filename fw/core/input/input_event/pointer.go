@@ -0,0 +1,49 @@
+package input
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// PointerEventType defines what a PointerEvent describes.
+type PointerEventType int32
+
+const (
+	// PointerEnter is sent when the pointer moves onto the receiver.
+	PointerEnter PointerEventType = iota
+	// PointerExit is sent when the pointer leaves the receiver, or another
+	// receiver in front of it is hit instead.
+	PointerExit
+	// PointerHover is sent every frame the pointer is over the receiver.
+	PointerHover
+	// PointerPressed and PointerReleased are sent when a mouse button is
+	// pressed or released over the receiver.
+	PointerPressed
+	PointerReleased
+	// PointerClick is sent when a mouse button is released over the receiver
+	// it was pressed on.
+	PointerClick
+)
+
+var pointerEventTypeNames = []string{"enter", "exit", "hover", "pressed", "released", "click"}
+
+// String returns the name of the pointer event type.
+func (t PointerEventType) String() string {
+	if t < 0 || int(t) >= len(pointerEventTypeNames) {
+		return "unknown"
+	}
+	return pointerEventTypeNames[t]
+}
+
+// A PointerEvent is sent to the front-most receiver under the pointer, see
+// input_handling.PointerReceiver.
+type PointerEvent struct {
+	Type PointerEventType
+	// Button is the mouse button, for PointerPressed, PointerReleased and
+	// PointerClick.
+	Button int32
+	// ScreenPosition is the position of the pointer on the screen.
+	ScreenPosition rl.Vector2
+	// WorldPosition is the position of the pointer in the world, as seen by
+	// the camera the receiver was drawn with.
+	WorldPosition rl.Vector2
+}
@@ -0,0 +1,83 @@
+package input
+
+// TextEventType defines what a TextEvent describes.
+type TextEventType int32
+
+const (
+	// TextEventChar is a typed character, see TextEvent.Char.
+	TextEventChar TextEventType = iota
+	// TextEventKey is an editing key like backspace, enter or the arrow
+	// keys, or a shortcut like Ctrl+C, see TextEvent.Key.
+	TextEventKey
+	// TextEventPaste is text pasted from the clipboard, see TextEvent.Text.
+	TextEventPaste
+)
+
+// TextEvent is sent to the receiver with the text focus.
+type TextEvent struct {
+	Type TextEventType
+	// Char is the typed character, for TextEventChar.
+	Char rune
+	// Key is the pressed key, for TextEventKey.
+	Key int32
+	// Repeat is true if the key event was caused by holding the key down.
+	Repeat bool
+	// Ctrl and Shift are true if the modifier was held, for TextEventKey.
+	Ctrl, Shift bool
+	// Text is the pasted text, for TextEventPaste.
+	Text string
+}
+
+// TextReceiver is implemented by anything that can receive typed text, e.g. a
+// text field or a console. Only the receiver with the text focus receives
+// TextEvents, see SetTextFocus.
+//
+// Characters come from the operating system, so keyboard layouts, dead keys
+// and input methods (IME) work as expected. While a receiver has the focus,
+// triggers that use the keyboard are suppressed, so typing "w" into a chat box
+// does not move the player. Mouse and gamepad triggers keep working.
+type TextReceiver interface {
+	OnTextInput(event *TextEvent)
+}
+
+// TextFocusListener can be implemented by a TextReceiver to be notified when
+// it gains or loses the text focus.
+type TextFocusListener interface {
+	OnTextFocusChanged(focused bool)
+}
+
+// textFocus is the receiver with the text focus, or nil.
+var textFocus TextReceiver
+
+// SetTextFocus gives the text focus to the receiver. The receiver that had
+// the focus before loses it.
+func SetTextFocus(receiver TextReceiver) {
+	if textFocus == receiver {
+		return
+	}
+	previous := textFocus
+	textFocus = receiver
+	if listener, ok := previous.(TextFocusListener); ok {
+		listener.OnTextFocusChanged(false)
+	}
+	if listener, ok := receiver.(TextFocusListener); ok {
+		listener.OnTextFocusChanged(true)
+	}
+}
+
+// ReleaseTextFocus removes the text focus from the receiver, if it has it.
+func ReleaseTextFocus(receiver TextReceiver) {
+	if textFocus == receiver {
+		SetTextFocus(nil)
+	}
+}
+
+// GetTextFocus returns the receiver with the text focus, or nil.
+func GetTextFocus() TextReceiver {
+	return textFocus
+}
+
+// HasTextFocus returns true if the receiver has the text focus.
+func HasTextFocus(receiver TextReceiver) bool {
+	return receiver != nil && textFocus == receiver
+}
@@ -1,5 +1,10 @@
 package input
 
+import (
+	"fmt"
+	"gorl/fw/core/input/gestures"
+)
+
 // TriggerType defines the type of event, e.g. down, pressed, released.
 type TriggerType int32
 
@@ -10,21 +15,239 @@ const (
 	TriggerTypePassive // Passive triggers are always active
 )
 
+var triggerTypeNames = []string{"down", "pressed", "released", "passive"}
+
+// String returns the name of the trigger type.
+func (t TriggerType) String() string {
+	if t < 0 || int(t) >= len(triggerTypeNames) {
+		return "unknown"
+	}
+	return triggerTypeNames[t]
+}
+
+// MarshalText implements encoding.TextMarshaler, so trigger types are stored
+// by name in the bindings file.
+func (t TriggerType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (t *TriggerType) UnmarshalText(text []byte) error {
+	for i, name := range triggerTypeNames {
+		if name == string(text) {
+			*t = TriggerType(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown trigger type %q", text)
+}
+
 // InputType defines the physical type of trigger. It can be a key, mouse
-// button, or gamepad button.
+// button, gamepad button or gamepad axis, a touch or a gesture, or a composite
+// of other triggers, see composite.go.
 type InputType int32
 
 const (
 	InputTypeKey InputType = iota
 	InputTypeMouse
 	InputTypeGamepad
+	InputTypeGamepadAxis
+	InputTypeAxisComposite
+	InputTypeVectorComposite
+	InputTypeGamepadStick
+	InputTypeTouch
+	InputTypeGesture
 )
 
+var inputTypeNames = []string{
+	"key", "mouse", "gamepad", "gamepadAxis",
+	"axisComposite", "vectorComposite", "gamepadStick",
+	"touch", "gesture",
+}
+
+// String returns the name of the input type.
+func (t InputType) String() string {
+	if t < 0 || int(t) >= len(inputTypeNames) {
+		return "unknown"
+	}
+	return inputTypeNames[t]
+}
+
+// MarshalText implements encoding.TextMarshaler, so input types are stored by
+// name in the bindings file.
+func (t InputType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (t *InputType) UnmarshalText(text []byte) error {
+	for i, name := range inputTypeNames {
+		if name == string(text) {
+			*t = InputType(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown input type %q", text)
+}
+
 // A Trigger is a definition of an input trigger that can cause an action. We
 // use it to map specific triggers to abstract actions.
+//
+// Gamepad triggers use the gamepad assigned to the player, see
+// PlayerBindings.SetGamepad.
+//
+// Axis triggers (InputTypeGamepadAxis) are active while the axis value, after
+// the deadzone and response curve of the player are applied, is beyond the
+// Threshold in the AxisDirection. Pressed and released fire when the value
+// crosses the threshold, so analog triggers can be used as buttons. Passive
+// axis triggers are always active and report the current value, e.g. for
+// analog movement.
+//
+// Touch triggers (InputTypeTouch) are active while the screen is touched, like
+// a mouse button. Gesture triggers (InputTypeGesture) are active in the frame
+// the Gesture is recognized, see the gestures package. Their events carry the
+// position of the gesture as cursor position, the movement or swipe direction
+// as Vector, and the scale of a pinch as Value.
 type Trigger struct {
-	InputType   InputType
-	TriggerType TriggerType
-	Key         int32
-	MouseButton int32
+	InputType     InputType   `json:"inputType"`
+	TriggerType   TriggerType `json:"triggerType"`
+	Key           int32       `json:"key,omitempty"`
+	MouseButton   int32       `json:"mouseButton,omitempty"`
+	GamepadButton int32       `json:"gamepadButton,omitempty"`
+	GamepadAxis   int32       `json:"gamepadAxis,omitempty"`
+	// AxisDirection is 1 for the positive half of the axis, -1 for the
+	// negative half, and 0 for both.
+	AxisDirection int32 `json:"axisDirection,omitempty"`
+	// Threshold is the value the axis has to exceed in the AxisDirection.
+	// 0 means any value outside the deadzone.
+	Threshold float32 `json:"threshold,omitempty"`
+	// Gesture is the gesture of a gesture trigger.
+	Gesture gestures.Type `json:"gesture,omitempty"`
+
+	// Modifiers are keys that have to be held for the trigger to be active,
+	// e.g. rl.KeyLeftControl for Ctrl+S. The left and right variants of the
+	// modifier keys are interchangeable.
+	Modifiers []int32 `json:"modifiers,omitempty"`
+	// Interaction changes when the trigger is active, based on how long its
+	// input is held, see Interaction. Duration overrides the default duration
+	// of the interaction, in seconds.
+	Interaction Interaction `json:"interaction,omitempty"`
+	Duration    float32     `json:"duration,omitempty"`
+
+	// Parts are the triggers a composite is made of, see composite.go.
+	Parts []Trigger `json:"parts,omitempty"`
+}
+
+// SameInput returns true if both triggers are caused by the same physical
+// input, e.g. the same key, regardless of their trigger type.
+// Passive triggers have no physical input and never match.
+func (t Trigger) SameInput(other Trigger) bool {
+	if t.TriggerType == TriggerTypePassive || other.TriggerType == TriggerTypePassive {
+		return false
+	}
+	if t.InputType != other.InputType || !sameModifiers(t.Modifiers, other.Modifiers) {
+		return false
+	}
+	switch t.InputType {
+	case InputTypeKey:
+		return t.Key == other.Key
+	case InputTypeMouse:
+		return t.MouseButton == other.MouseButton
+	case InputTypeGamepad:
+		return t.GamepadButton == other.GamepadButton
+	case InputTypeGamepadAxis:
+		return t.GamepadAxis == other.GamepadAxis &&
+			(t.AxisDirection == 0 || other.AxisDirection == 0 || t.AxisDirection == other.AxisDirection)
+	case InputTypeGamepadStick:
+		return t.GamepadAxis == other.GamepadAxis
+	case InputTypeTouch:
+		return true
+	case InputTypeGesture:
+		return t.Gesture == other.Gesture
+	}
+	return false
+}
+
+// sameModifiers returns true if both triggers require the same modifiers.
+func sameModifiers(a, b []int32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, modifier := range a {
+		found := false
+		for _, other := range b {
+			if modifierPair(modifier) == modifierPair(other) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Clone returns a deep copy of the trigger.
+func (t Trigger) Clone() Trigger {
+	if t.Modifiers != nil {
+		t.Modifiers = append([]int32{}, t.Modifiers...)
+	}
+	if t.Parts != nil {
+		parts := make([]Trigger, len(t.Parts))
+		for i, part := range t.Parts {
+			parts[i] = part.Clone()
+		}
+		t.Parts = parts
+	}
+	return t
+}
+
+// IsComposite returns true if the trigger is made of other triggers, or reads
+// several axes at once.
+func (t Trigger) IsComposite() bool {
+	return t.InputType == InputTypeAxisComposite ||
+		t.InputType == InputTypeVectorComposite ||
+		t.InputType == InputTypeGamepadStick
+}
+
+// UsesKeyboard returns true if the trigger, its modifiers or any of its parts
+// read the keyboard.
+func (t Trigger) UsesKeyboard() bool {
+	if t.InputType == InputTypeKey || len(t.Modifiers) > 0 {
+		return true
+	}
+	for _, part := range t.Parts {
+		if part.UsesKeyboard() {
+			return true
+		}
+	}
+	return false
+}
+
+// UsesGamepad returns true if the trigger, or any of its parts, reads a
+// gamepad.
+func (t Trigger) UsesGamepad() bool {
+	switch t.InputType {
+	case InputTypeGamepad, InputTypeGamepadAxis, InputTypeGamepadStick:
+		return true
+	}
+	for _, part := range t.Parts {
+		if part.UsesGamepad() {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAxisActive returns true if the processed axis value is beyond the
+// threshold of the trigger, in its direction.
+func (t Trigger) IsAxisActive(value float32) bool {
+	switch {
+	case t.AxisDirection > 0:
+		return value > t.Threshold
+	case t.AxisDirection < 0:
+		return -value > t.Threshold
+	}
+	return value > t.Threshold || -value > t.Threshold
 }
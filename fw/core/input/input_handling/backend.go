@@ -0,0 +1,123 @@
+package input
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// MaxGamepads is the number of gamepads that are checked for input.
+const MaxGamepads int32 = 4
+
+// Backend provides the state of the input devices. The input handling reads
+// all device state through the backend, so the mapping from devices to
+// actions can be tested without hardware, see FakeBackend.
+type Backend interface {
+	IsKeyDown(key int32) bool
+	IsKeyPressed(key int32) bool
+	IsKeyReleased(key int32) bool
+	// GetKeyPressed returns the next key that was pressed this frame, or 0.
+	GetKeyPressed() int32
+	// IsKeyPressedRepeat returns true if the key is held and repeated by the
+	// operating system this frame.
+	IsKeyPressedRepeat(key int32) bool
+	// GetCharPressed returns the next character that was typed this frame,
+	// or 0.
+	GetCharPressed() int32
+
+	GetClipboardText() string
+	SetClipboardText(text string)
+
+	IsMouseButtonDown(button int32) bool
+	IsMouseButtonPressed(button int32) bool
+	IsMouseButtonReleased(button int32) bool
+	GetMousePosition() rl.Vector2
+
+	// GetTouchPointCount returns the number of touch points that are down.
+	// GetTouchPointId and GetTouchPosition return the ID and position of the
+	// touch point with the given index.
+	GetTouchPointCount() int32
+	GetTouchPointId(index int32) int32
+	GetTouchPosition(index int32) rl.Vector2
+
+	IsGamepadAvailable(gamepad int32) bool
+	IsGamepadButtonDown(gamepad, button int32) bool
+	IsGamepadButtonPressed(gamepad, button int32) bool
+	IsGamepadButtonReleased(gamepad, button int32) bool
+	// GetGamepadAxisMovement returns the raw value of the axis, in the range
+	// [-1, 1].
+	GetGamepadAxisMovement(gamepad, axis int32) float32
+}
+
+// RaylibBackend reads the input devices through raylib. This is the default
+// backend.
+type RaylibBackend struct{}
+
+func (RaylibBackend) IsKeyDown(key int32) bool     { return rl.IsKeyDown(key) }
+func (RaylibBackend) IsKeyPressed(key int32) bool  { return rl.IsKeyPressed(key) }
+func (RaylibBackend) IsKeyReleased(key int32) bool { return rl.IsKeyReleased(key) }
+func (RaylibBackend) GetKeyPressed() int32         { return rl.GetKeyPressed() }
+func (RaylibBackend) IsKeyPressedRepeat(key int32) bool {
+	return rl.IsKeyPressedRepeat(key)
+}
+func (RaylibBackend) GetCharPressed() int32 { return rl.GetCharPressed() }
+
+func (RaylibBackend) GetClipboardText() string     { return rl.GetClipboardText() }
+func (RaylibBackend) SetClipboardText(text string) { rl.SetClipboardText(text) }
+
+func (RaylibBackend) IsMouseButtonDown(button int32) bool    { return rl.IsMouseButtonDown(button) }
+func (RaylibBackend) IsMouseButtonPressed(button int32) bool { return rl.IsMouseButtonPressed(button) }
+func (RaylibBackend) IsMouseButtonReleased(button int32) bool {
+	return rl.IsMouseButtonReleased(button)
+}
+func (RaylibBackend) GetMousePosition() rl.Vector2 { return rl.GetMousePosition() }
+
+func (RaylibBackend) GetTouchPointCount() int32               { return rl.GetTouchPointCount() }
+func (RaylibBackend) GetTouchPointId(index int32) int32       { return rl.GetTouchPointId(index) }
+func (RaylibBackend) GetTouchPosition(index int32) rl.Vector2 { return rl.GetTouchPosition(index) }
+
+func (RaylibBackend) IsGamepadAvailable(gamepad int32) bool { return rl.IsGamepadAvailable(gamepad) }
+func (RaylibBackend) IsGamepadButtonDown(gamepad, button int32) bool {
+	return rl.IsGamepadButtonDown(gamepad, button)
+}
+func (RaylibBackend) IsGamepadButtonPressed(gamepad, button int32) bool {
+	return rl.IsGamepadButtonPressed(gamepad, button)
+}
+func (RaylibBackend) IsGamepadButtonReleased(gamepad, button int32) bool {
+	return rl.IsGamepadButtonReleased(gamepad, button)
+}
+func (RaylibBackend) GetGamepadAxisMovement(gamepad, axis int32) float32 {
+	return rl.GetGamepadAxisMovement(gamepad, axis)
+}
+
+// backend is the backend the input handling reads from.
+var backend Backend = RaylibBackend{}
+
+// SetBackend sets the backend the input handling reads the devices from.
+func SetBackend(b Backend) {
+	backend = b
+}
+
+// GetBackend returns the backend the input handling reads the devices from.
+func GetBackend() Backend {
+	return backend
+}
+
+// GetClipboardText returns the text on the system clipboard.
+func GetClipboardText() string {
+	return backend.GetClipboardText()
+}
+
+// SetClipboardText puts the text on the system clipboard.
+func SetClipboardText(text string) {
+	backend.SetClipboardText(text)
+}
+
+// GetConnectedGamepads returns the indices of all connected gamepads.
+func GetConnectedGamepads() []int32 {
+	gamepads := []int32{}
+	for gamepad := int32(0); gamepad < MaxGamepads; gamepad++ {
+		if backend.IsGamepadAvailable(gamepad) {
+			gamepads = append(gamepads, gamepad)
+		}
+	}
+	return gamepads
+}
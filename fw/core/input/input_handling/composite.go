@@ -0,0 +1,222 @@
+package input
+
+import (
+	"fmt"
+	"gorl/fw/core/clock"
+	input "gorl/fw/core/input/input_event"
+	"math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// triggerKey identifies a trigger of an action of a player, to keep the state
+// of its interaction between frames. The trigger is identified by its
+// contents rather than its position in the bindings, which changes when
+// contexts are enabled or disabled.
+type triggerKey struct {
+	player  int
+	action  input.Action
+	trigger string
+}
+
+// newTriggerKey returns the key of the trigger of the action of the player.
+func newTriggerKey(player int, action input.Action, trigger input.Trigger) triggerKey {
+	return triggerKey{player: player, action: action, trigger: fmt.Sprint(trigger)}
+}
+
+// triggerResult is the result of evaluating a trigger in a frame.
+type triggerResult struct {
+	active bool
+	value  float32
+	vector rl.Vector2
+	// position is the screen position of the input, if it has one, e.g. the
+	// position of a gesture.
+	position    rl.Vector2
+	hasPosition bool
+}
+
+// evaluate evaluates a trigger for the current frame, including its
+// modifiers, interaction and parts.
+func evaluate(player *input.PlayerBindings, key triggerKey, trigger input.Trigger) triggerResult {
+	modifiersHeld := areModifiersDown(trigger.Modifiers)
+
+	if trigger.Interaction != input.InteractionNone {
+		held := modifiersHeld && isHeld(player, trigger)
+		return triggerResult{active: updateInteraction(key, trigger, held), value: 1}
+	}
+	if !modifiersHeld {
+		return triggerResult{}
+	}
+
+	passive := trigger.TriggerType == input.TriggerTypePassive
+	switch trigger.InputType {
+	case input.InputTypeAxisComposite:
+		value := axisCompositeValue(player, trigger)
+		return triggerResult{active: passive || value != 0, value: value}
+	case input.InputTypeVectorComposite, input.InputTypeGamepadStick:
+		vector := vectorValue(player, trigger)
+		length := rl.Vector2Length(vector)
+		return triggerResult{active: passive || length > 0, value: length, vector: vector}
+	case input.InputTypeGesture:
+		return gestureResult(trigger)
+	}
+
+	active, value := isTriggered(player, trigger)
+	return triggerResult{active: active, value: value}
+}
+
+// areModifiersDown returns true if all modifier keys are held, accepting the
+// left and right variant of each.
+func areModifiersDown(modifiers []int32) bool {
+	for _, modifier := range modifiers {
+		other := input.OtherModifier(modifier)
+		if !backend.IsKeyDown(modifier) && (other == 0 || !backend.IsKeyDown(other)) {
+			return false
+		}
+	}
+	return true
+}
+
+// isHeld returns true if the input of the trigger is held down, regardless
+// of its trigger type.
+func isHeld(player *input.PlayerBindings, trigger input.Trigger) bool {
+	switch trigger.InputType {
+	case input.InputTypeKey:
+		return backend.IsKeyDown(trigger.Key)
+	case input.InputTypeMouse:
+		return backend.IsMouseButtonDown(trigger.MouseButton)
+	case input.InputTypeGamepad:
+		return backend.IsGamepadButtonDown(player.GetGamepad(), trigger.GamepadButton)
+	case input.InputTypeGamepadAxis:
+		return trigger.IsAxisActive(axisValue(player, trigger.GamepadAxis))
+	case input.InputTypeAxisComposite:
+		return axisCompositeValue(player, trigger) != 0
+	case input.InputTypeVectorComposite, input.InputTypeGamepadStick:
+		return rl.Vector2Length(vectorValue(player, trigger)) > 0
+	case input.InputTypeTouch:
+		return len(touchPoints) > 0
+	case input.InputTypeGesture:
+		_, ok := findGesture(trigger.Gesture)
+		return ok
+	}
+	return false
+}
+
+// partValue returns the value a part of a composite contributes, in [0, 1].
+// Axes contribute their analog value in the direction of the part, all other
+// inputs 1 while held.
+func partValue(player *input.PlayerBindings, part input.Trigger) float32 {
+	if part.InputType == input.InputTypeGamepadAxis {
+		value := axisValue(player, part.GamepadAxis)
+		switch {
+		case part.AxisDirection > 0:
+			return float32(math.Max(0, float64(value)))
+		case part.AxisDirection < 0:
+			return float32(math.Max(0, float64(-value)))
+		}
+		return float32(math.Abs(float64(value)))
+	}
+	if isHeld(player, part) {
+		return 1
+	}
+	return 0
+}
+
+// axisCompositeValue returns the value of an axis composite in [-1, 1].
+func axisCompositeValue(player *input.PlayerBindings, trigger input.Trigger) float32 {
+	if len(trigger.Parts) != 2 {
+		return 0
+	}
+	value := partValue(player, trigger.Parts[1]) - partValue(player, trigger.Parts[0])
+	return float32(math.Max(-1, math.Min(float64(value), 1)))
+}
+
+// vectorValue returns the value of a vector composite or a gamepad stick,
+// with a length of at most 1.
+func vectorValue(player *input.PlayerBindings, trigger input.Trigger) rl.Vector2 {
+	if trigger.InputType == input.InputTypeGamepadStick {
+		gamepad := player.GetGamepad()
+		return player.ProcessStick(
+			backend.GetGamepadAxisMovement(gamepad, trigger.GamepadAxis),
+			backend.GetGamepadAxisMovement(gamepad, trigger.GamepadAxis+1),
+		)
+	}
+
+	if len(trigger.Parts) != 4 {
+		return rl.Vector2Zero()
+	}
+	vector := rl.NewVector2(
+		partValue(player, trigger.Parts[3])-partValue(player, trigger.Parts[2]),
+		partValue(player, trigger.Parts[1])-partValue(player, trigger.Parts[0]),
+	)
+	if rl.Vector2Length(vector) > 1 {
+		vector = rl.Vector2Normalize(vector)
+	}
+	return vector
+}
+
+// interactionState is the state of the interaction of a trigger.
+type interactionState struct {
+	held      bool
+	heldSince float64
+	lastPress float64
+	fired     bool
+}
+
+// interactions holds the state of the interactions of all bound triggers.
+var interactions = map[triggerKey]*interactionState{}
+
+// pruneInteractions drops the state of the interactions of triggers that are
+// not bound anymore, e.g. because their context was disabled.
+func pruneInteractions(bound map[triggerKey]bool) {
+	for key := range interactions {
+		if !bound[key] {
+			delete(interactions, key)
+		}
+	}
+}
+
+// updateInteraction advances the interaction of the trigger by a frame, and
+// returns true if it is active. Interactions measure real time, so they are
+// not affected by the time scale.
+func updateInteraction(key triggerKey, trigger input.Trigger, held bool) bool {
+	state, ok := interactions[key]
+	if !ok {
+		state = &interactionState{lastPress: math.Inf(-1)}
+		interactions[key] = state
+	}
+
+	now := clock.UnscaledTotalTime()
+	duration := float64(trigger.GetDuration())
+	pressed := held && !state.held
+	released := !held && state.held
+	if pressed {
+		state.heldSince = now
+		state.fired = false
+	}
+
+	active := false
+	switch trigger.Interaction {
+	case input.InteractionHold:
+		active = held && now-state.heldSince >= duration
+	case input.InteractionLongPress:
+		if held && !state.fired && now-state.heldSince >= duration {
+			active = true
+			state.fired = true
+		}
+	case input.InteractionTap:
+		active = released && now-state.heldSince < duration
+	case input.InteractionDoubleTap:
+		if pressed {
+			if now-state.lastPress <= duration {
+				active = true
+				state.lastPress = math.Inf(-1)
+			} else {
+				state.lastPress = now
+			}
+		}
+	}
+
+	state.held = held
+	return active
+}
@@ -0,0 +1,204 @@
+package input
+
+import (
+	inputevent "gorl/fw/core/input/input_event"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+var _ Backend = (*FakeBackend)(nil)
+
+// FakeBackend is a Backend without hardware, for tests. The state of the
+// devices is set directly, and NextFrame() moves on to the next frame, which
+// determines what counts as pressed and released.
+//
+// Example:
+//
+//	fake := input.NewFakeBackend()
+//	input.SetBackend(fake)
+//	fake.SetKey(rl.KeySpace, true)
+//	input.HandleInputEvents(receivers, nil) // space is pressed and down
+//	fake.NextFrame()
+//	input.HandleInputEvents(receivers, nil) // space is only down
+type FakeBackend struct {
+	keys, previousKeys                     map[int32]bool
+	mouseButtons, previousMouseButtons     map[int32]bool
+	gamepadButtons, previousGamepadButtons map[[2]int32]bool
+	axes                                   map[[2]int32]float32
+	gamepads                               map[int32]bool
+	mousePosition                          rl.Vector2
+	touchPoints                            []fakeTouchPoint
+	repeatedKeys                           map[int32]bool
+	chars                                  []rune
+	clipboard                              string
+}
+
+// NewFakeBackend creates a FakeBackend with no keys or buttons down and no
+// gamepads connected.
+func NewFakeBackend() *FakeBackend {
+	return &FakeBackend{
+		keys:                   map[int32]bool{},
+		previousKeys:           map[int32]bool{},
+		mouseButtons:           map[int32]bool{},
+		previousMouseButtons:   map[int32]bool{},
+		gamepadButtons:         map[[2]int32]bool{},
+		previousGamepadButtons: map[[2]int32]bool{},
+		axes:                   map[[2]int32]float32{},
+		gamepads:               map[int32]bool{},
+		repeatedKeys:           map[int32]bool{},
+	}
+}
+
+// NextFrame ends the current frame. The current state becomes the previous
+// state, which pressed and released are compared against.
+func (f *FakeBackend) NextFrame() {
+	f.previousKeys = copyMap(f.keys)
+	f.previousMouseButtons = copyMap(f.mouseButtons)
+	f.previousGamepadButtons = copyMap(f.gamepadButtons)
+	f.repeatedKeys = map[int32]bool{}
+	f.chars = nil
+}
+
+// fakeTouchPoint is a touch point of the FakeBackend.
+type fakeTouchPoint struct {
+	id       int32
+	position rl.Vector2
+}
+
+func copyMap[K comparable](m map[K]bool) map[K]bool {
+	c := make(map[K]bool, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+// SetKey sets whether the key is down.
+func (f *FakeBackend) SetKey(key int32, down bool) { f.keys[key] = down }
+
+// RepeatKey repeats the key this frame, as if it was held down long enough
+// for the operating system to repeat it.
+func (f *FakeBackend) RepeatKey(key int32) { f.repeatedKeys[key] = true }
+
+// TypeText queues the characters of the text as typed this frame.
+func (f *FakeBackend) TypeText(text string) { f.chars = append(f.chars, []rune(text)...) }
+
+// SetMouseButton sets whether the mouse button is down.
+func (f *FakeBackend) SetMouseButton(button int32, down bool) { f.mouseButtons[button] = down }
+
+// SetMousePosition sets the position of the mouse.
+func (f *FakeBackend) SetMousePosition(position rl.Vector2) { f.mousePosition = position }
+
+// SetTouch puts the touch point with the given ID down at the position, or
+// moves it there.
+func (f *FakeBackend) SetTouch(id int32, position rl.Vector2) {
+	for i := range f.touchPoints {
+		if f.touchPoints[i].id == id {
+			f.touchPoints[i].position = position
+			return
+		}
+	}
+	f.touchPoints = append(f.touchPoints, fakeTouchPoint{id: id, position: position})
+}
+
+// ReleaseTouch lifts the touch point with the given ID.
+func (f *FakeBackend) ReleaseTouch(id int32) {
+	for i := range f.touchPoints {
+		if f.touchPoints[i].id == id {
+			f.touchPoints = append(f.touchPoints[:i], f.touchPoints[i+1:]...)
+			return
+		}
+	}
+}
+
+// ConnectGamepad connects or disconnects the gamepad with the given index.
+func (f *FakeBackend) ConnectGamepad(gamepad int32, connected bool) { f.gamepads[gamepad] = connected }
+
+// SetGamepadButton sets whether the button of the gamepad is down.
+func (f *FakeBackend) SetGamepadButton(gamepad, button int32, down bool) {
+	f.gamepadButtons[[2]int32{gamepad, button}] = down
+}
+
+// SetGamepadAxis sets the raw value of the axis of the gamepad.
+func (f *FakeBackend) SetGamepadAxis(gamepad, axis int32, value float32) {
+	f.axes[[2]int32{gamepad, axis}] = value
+}
+
+func (f *FakeBackend) IsKeyDown(key int32) bool     { return f.keys[key] }
+func (f *FakeBackend) IsKeyPressed(key int32) bool  { return f.keys[key] && !f.previousKeys[key] }
+func (f *FakeBackend) IsKeyReleased(key int32) bool { return !f.keys[key] && f.previousKeys[key] }
+
+// GetKeyPressed returns the lowest key that was pressed this frame, or 0.
+func (f *FakeBackend) GetKeyPressed() int32 {
+	pressed := int32(0)
+	for key := range f.keys {
+		if f.IsKeyPressed(key) && (pressed == 0 || key < pressed) {
+			pressed = key
+		}
+	}
+	return pressed
+}
+
+func (f *FakeBackend) IsKeyPressedRepeat(key int32) bool { return f.keys[key] && f.repeatedKeys[key] }
+
+// GetCharPressed returns the next character queued with TypeText, or 0.
+func (f *FakeBackend) GetCharPressed() int32 {
+	if len(f.chars) == 0 {
+		return 0
+	}
+	char := f.chars[0]
+	f.chars = f.chars[1:]
+	return char
+}
+
+func (f *FakeBackend) GetClipboardText() string     { return f.clipboard }
+func (f *FakeBackend) SetClipboardText(text string) { f.clipboard = text }
+
+func (f *FakeBackend) IsMouseButtonDown(button int32) bool { return f.mouseButtons[button] }
+func (f *FakeBackend) IsMouseButtonPressed(button int32) bool {
+	return f.mouseButtons[button] && !f.previousMouseButtons[button]
+}
+func (f *FakeBackend) IsMouseButtonReleased(button int32) bool {
+	return !f.mouseButtons[button] && f.previousMouseButtons[button]
+}
+func (f *FakeBackend) GetMousePosition() rl.Vector2 { return f.mousePosition }
+
+func (f *FakeBackend) GetTouchPointCount() int32 { return int32(len(f.touchPoints)) }
+func (f *FakeBackend) GetTouchPointId(index int32) int32 {
+	if index < 0 || int(index) >= len(f.touchPoints) {
+		return -1
+	}
+	return f.touchPoints[index].id
+}
+func (f *FakeBackend) GetTouchPosition(index int32) rl.Vector2 {
+	if index < 0 || int(index) >= len(f.touchPoints) {
+		return rl.Vector2{}
+	}
+	return f.touchPoints[index].position
+}
+
+func (f *FakeBackend) IsGamepadAvailable(gamepad int32) bool { return f.gamepads[gamepad] }
+func (f *FakeBackend) IsGamepadButtonDown(gamepad, button int32) bool {
+	return f.gamepads[gamepad] && f.gamepadButtons[[2]int32{gamepad, button}]
+}
+func (f *FakeBackend) IsGamepadButtonPressed(gamepad, button int32) bool {
+	key := [2]int32{gamepad, button}
+	return f.gamepads[gamepad] && f.gamepadButtons[key] && !f.previousGamepadButtons[key]
+}
+func (f *FakeBackend) IsGamepadButtonReleased(gamepad, button int32) bool {
+	key := [2]int32{gamepad, button}
+	return f.gamepads[gamepad] && !f.gamepadButtons[key] && f.previousGamepadButtons[key]
+}
+
+// GetGamepadAxisMovement returns the raw value of the axis. Like in raylib,
+// trigger axes that were never set rest at -1.
+func (f *FakeBackend) GetGamepadAxisMovement(gamepad, axis int32) float32 {
+	if !f.gamepads[gamepad] {
+		return 0
+	}
+	value, ok := f.axes[[2]int32{gamepad, axis}]
+	if !ok && inputevent.IsTriggerAxis(axis) {
+		return -1
+	}
+	return value
+}
@@ -1,7 +1,9 @@
 package input
 
 import (
+	"gorl/fw/core/clock"
 	input "gorl/fw/core/input/input_event"
+	"gorl/fw/core/settings"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
@@ -12,15 +14,27 @@ type InputReceiver interface {
 	OnInputEvent(event *input.InputEvent) bool
 }
 
-// HandleInputEvents checks for input events and propagates them to the entities.
-// Receives a sorted slice of layers, each containing a slice of entities.
-// Both must be sorted from back to front (from far away to close to camera).
-func HandleInputEvents(inputReceivers []InputReceiver) {
-	// TODO: since therea re no more layers, ths makes no sense. rewrite.
-	events := checkForInputs()
+// HandleInputEvents checks for input events and propagates them to the
+// receivers, which must be sorted from back to front (from far away to close
+// to camera).
+//
+// drawn are the receivers that were drawn this frame and their viewports, see
+// render.Draw. They are used to resolve the world position of the cursor for
+// each receiver (see input.InputEvent.GetWorldMousePosition), and to send
+// pointer events to the front-most PointerReceiver under the cursor.
+func HandleInputEvents(inputReceivers []InputReceiver, drawn []DrawnReceiver) {
+	frame, events := pollFrame()
+	dispatchPointer(inputReceivers, drawn, frame)
+
+	viewports := resolveViewports(drawn, frame.CursorPosition)
 	for _, event := range events {
 		// walk backwards so that the front-most entities receive the input first
 		for i := len(inputReceivers) - 1; i >= 0; i-- {
+			if viewport, ok := viewports[receiverKey(inputReceivers[i])]; ok {
+				event.SetWorldMousePosition(viewport.ScreenToWorld(frame.CursorPosition), true)
+			} else {
+				event.SetWorldMousePosition(rl.Vector2{}, false)
+			}
 			shouldContinue := inputReceivers[i].OnInputEvent(event)
 			if !shouldContinue {
 				break
@@ -29,51 +43,284 @@ func HandleInputEvents(inputReceivers []InputReceiver) {
 	}
 }
 
+// pollFrame returns the input of this frame and its events. They are read
+// from the devices, or from the recording during a replay (see StartReplay).
+func pollFrame() (*RecordedFrame, []*input.InputEvent) {
+	if replay != nil {
+		return replayFrame()
+	}
+
+	pollTouch()
+	frame := &RecordedFrame{Delta: clock.UnscaledDeltaTime(), CursorPosition: GetPointerPosition()}
+	events := pollDevices(frame)
+	if recording != nil {
+		recordFrame(frame, events)
+	}
+	return frame, events
+}
+
+// pollDevices reads the devices, and returns the input events of this frame.
+// Text and capture results are dispatched directly, and stored in the frame.
+func pollDevices(frame *RecordedFrame) []*input.InputEvent {
+	// while the player is choosing a new binding, the input must not trigger
+	// any actions.
+	if pollCapture(frame) {
+		return nil
+	}
+	frame.PointerPressed, frame.PointerReleased = pollPointerButtons()
+
+	// typed text goes to the receiver with the text focus, see
+	// input.SetTextFocus.
+	for _, event := range dispatchText(readText()) {
+		frame.Text = append(frame.Text, *event)
+	}
+
+	return checkForInputs()
+}
+
+// gamepadEnabled returns true if gamepads are enabled in the settings.
+func gamepadEnabled() bool {
+	s := settings.CurrentSettings()
+	return s != nil && s.EnableGamepad
+}
+
+// pollCapture checks if the player pressed a key or button for a running
+// capture (see input.CaptureNextTrigger), and returns true if a capture is
+// running. The result of a capture that ends is stored in the frame.
+func pollCapture(frame *RecordedFrame) bool {
+	if !input.IsCapturing() {
+		return false
+	}
+
+	complete := func(trigger input.Trigger, ok bool) {
+		if input.CompleteCapture(trigger, ok) {
+			frame.Capture = &RecordedCapture{Trigger: trigger, Ok: ok}
+		}
+	}
+
+	if key := backend.GetKeyPressed(); key != 0 {
+		if key == rl.KeyEscape {
+			complete(input.Trigger{}, false)
+		} else {
+			complete(input.Trigger{InputType: input.InputTypeKey, TriggerType: input.TriggerTypePressed, Key: key}, true)
+		}
+		return true
+	}
+	for button := int32(rl.MouseButtonLeft); button <= rl.MouseButtonBack; button++ {
+		if backend.IsMouseButtonPressed(button) {
+			complete(input.Trigger{InputType: input.InputTypeMouse, TriggerType: input.TriggerTypePressed, MouseButton: button}, true)
+			return true
+		}
+	}
+	if isTouched(input.TriggerTypePressed) {
+		complete(input.Trigger{InputType: input.InputTypeTouch, TriggerType: input.TriggerTypePressed}, true)
+		return true
+	}
+	if gamepadEnabled() {
+		for _, gamepad := range GetConnectedGamepads() {
+			for button := int32(rl.GamepadButtonLeftFaceUp); button <= rl.GamepadButtonRightThumb; button++ {
+				if backend.IsGamepadButtonPressed(gamepad, button) {
+					complete(input.Trigger{InputType: input.InputTypeGamepad, TriggerType: input.TriggerTypePressed, GamepadButton: button}, true)
+					return true
+				}
+			}
+		}
+	}
+	return true
+}
+
+// axisKey identifies an axis of the gamepad of a player.
+type axisKey struct {
+	player int
+	axis   int32
+}
+
+// previousAxes holds the processed axis values of the previous frame, to
+// detect axis triggers being pressed and released.
+var previousAxes = map[axisKey]float32{}
+
+// candidate is an event and the trigger that caused it.
+type candidate struct {
+	event   *input.InputEvent
+	trigger input.Trigger
+}
+
+// checkForInputs returns an event for every action of every player that was
+// triggered this frame, according to the active contexts of the player.
+// An action causes at most one event per player and frame, even if several of
+// its triggers are active. Passive triggers only cause the event if no other
+// trigger of the action is active.
+//
+// While a chord (a trigger with modifiers) is active, triggers of the same
+// player and input without modifiers are suppressed, so Ctrl+S does not also
+// trigger S.
+//
+// While a receiver has the text focus, triggers that use the keyboard are
+// ignored.
 func checkForInputs() []*input.InputEvent {
 
-	events := []*input.InputEvent{}
-	mousePosition := rl.GetMousePosition()
-
-	for action, triggers := range input.ActionMap {
-		for _, trigger := range triggers {
-			switch trigger.InputType {
-			case input.InputTypeKey:
-				switch trigger.TriggerType {
-				case input.TriggerTypeDown:
-					if rl.IsKeyDown(trigger.Key) {
-						events = append(events, input.NewInputEvent(action, mousePosition))
-					}
-				case input.TriggerTypePressed:
-					if rl.IsKeyPressed(trigger.Key) {
-						events = append(events, input.NewInputEvent(action, mousePosition))
-					}
-				case input.TriggerTypeReleased:
-					if rl.IsKeyReleased(trigger.Key) {
-						events = append(events, input.NewInputEvent(action, mousePosition))
-					}
+	mousePosition := GetPointerPosition()
+	withGamepad := gamepadEnabled()
+	typing := input.GetTextFocus() != nil
+
+	candidates := []candidate{}
+	chords := map[int][]input.Trigger{}
+	bound := map[triggerKey]bool{}
+
+	for _, player := range input.Players() {
+		gamepadAvailable := withGamepad && backend.IsGamepadAvailable(player.GetGamepad())
+		for action, triggers := range player.GetActiveBindings() {
+			var chosen, passive *candidate
+			// all triggers are evaluated, even after one was active, so the
+			// interactions of the others see every frame.
+			for _, trigger := range triggers {
+				key := newTriggerKey(player.Index(), action, trigger)
+				bound[key] = true
+				if trigger.UsesGamepad() && !gamepadAvailable {
+					continue
 				}
-			case input.InputTypeMouse:
-				switch trigger.TriggerType {
-				case input.TriggerTypeDown:
-					if rl.IsMouseButtonDown(trigger.MouseButton) {
-						events = append(events, input.NewInputEvent(action, mousePosition))
-					}
-				case input.TriggerTypePressed:
-					if rl.IsMouseButtonPressed(trigger.MouseButton) {
-						events = append(events, input.NewInputEvent(action, mousePosition))
-					}
-				case input.TriggerTypeReleased:
-					if rl.IsMouseButtonReleased(trigger.MouseButton) {
-						events = append(events, input.NewInputEvent(action, mousePosition))
+				if typing && trigger.UsesKeyboard() {
+					continue
+				}
+				result := evaluate(player, key, trigger)
+				if !result.active {
+					continue
+				}
+				if len(trigger.Modifiers) > 0 {
+					chords[player.Index()] = append(chords[player.Index()], trigger)
+				}
+
+				position := mousePosition
+				if result.hasPosition {
+					position = result.position
+				}
+				event := input.NewInputEvent(action, position)
+				event.Player = player.Index()
+				event.Value = result.value
+				event.Vector = result.vector
+				switch {
+				case trigger.UsesGamepad():
+					event.Device = player.GetGamepad()
+				case trigger.InputType == input.InputTypeTouch || trigger.InputType == input.InputTypeGesture:
+					event.Device = input.DeviceTouch
+				}
+				if trigger.TriggerType == input.TriggerTypePassive {
+					if passive == nil {
+						passive = &candidate{event: event, trigger: trigger}
 					}
-				case input.TriggerTypePassive:
-					events = append(events, input.NewInputEvent(action, mousePosition))
+				} else if chosen == nil {
+					chosen = &candidate{event: event, trigger: trigger}
 				}
-			case input.InputTypeGamepad:
-				// Implement the checks for gamepad buttons using a similar pattern
+			}
+			if chosen == nil {
+				chosen = passive
+			}
+			if chosen != nil {
+				candidates = append(candidates, *chosen)
+			}
+		}
+	}
+
+	pruneInteractions(bound)
+
+	events := make([]*input.InputEvent, 0, len(candidates))
+	for _, c := range candidates {
+		if !isShadowedByChord(c.trigger, chords[c.event.Player]) {
+			events = append(events, c.event)
+		}
+	}
+
+	// remember the axis values for the next frame
+	for _, player := range input.Players() {
+		for axis := int32(rl.GamepadAxisLeftX); axis <= rl.GamepadAxisRightTrigger; axis++ {
+			key := axisKey{player: player.Index(), axis: axis}
+			if withGamepad {
+				previousAxes[key] = axisValue(player, axis)
+			} else {
+				delete(previousAxes, key)
 			}
 		}
 	}
 
 	return events
 }
+
+// isShadowedByChord returns true if the trigger has no modifiers, and it or
+// one of its parts uses the input of one of the active chords.
+func isShadowedByChord(trigger input.Trigger, chords []input.Trigger) bool {
+	if len(trigger.Modifiers) > 0 {
+		return false
+	}
+	for _, chord := range chords {
+		chord.Modifiers = nil
+		if chord.SameInput(trigger) {
+			return true
+		}
+	}
+	for _, part := range trigger.Parts {
+		if isShadowedByChord(part, chords) {
+			return true
+		}
+	}
+	return false
+}
+
+// axisValue returns the processed value of an axis of the gamepad of the
+// player.
+func axisValue(player *input.PlayerBindings, axis int32) float32 {
+	return player.ProcessAxis(axis, backend.GetGamepadAxisMovement(player.GetGamepad(), axis))
+}
+
+// isTriggered returns true if the trigger is active this frame, and the value
+// of the event it causes.
+func isTriggered(player *input.PlayerBindings, trigger input.Trigger) (bool, float32) {
+	switch trigger.InputType {
+	case input.InputTypeKey:
+		switch trigger.TriggerType {
+		case input.TriggerTypeDown:
+			return backend.IsKeyDown(trigger.Key), 1
+		case input.TriggerTypePressed:
+			return backend.IsKeyPressed(trigger.Key), 1
+		case input.TriggerTypeReleased:
+			return backend.IsKeyReleased(trigger.Key), 1
+		}
+	case input.InputTypeMouse:
+		switch trigger.TriggerType {
+		case input.TriggerTypeDown:
+			return backend.IsMouseButtonDown(trigger.MouseButton), 1
+		case input.TriggerTypePressed:
+			return backend.IsMouseButtonPressed(trigger.MouseButton), 1
+		case input.TriggerTypeReleased:
+			return backend.IsMouseButtonReleased(trigger.MouseButton), 1
+		case input.TriggerTypePassive:
+			return true, 1
+		}
+	case input.InputTypeGamepad:
+		gamepad := player.GetGamepad()
+		switch trigger.TriggerType {
+		case input.TriggerTypeDown:
+			return backend.IsGamepadButtonDown(gamepad, trigger.GamepadButton), 1
+		case input.TriggerTypePressed:
+			return backend.IsGamepadButtonPressed(gamepad, trigger.GamepadButton), 1
+		case input.TriggerTypeReleased:
+			return backend.IsGamepadButtonReleased(gamepad, trigger.GamepadButton), 1
+		}
+	case input.InputTypeGamepadAxis:
+		value := axisValue(player, trigger.GamepadAxis)
+		active := trigger.IsAxisActive(value)
+		wasActive := trigger.IsAxisActive(previousAxes[axisKey{player: player.Index(), axis: trigger.GamepadAxis}])
+		switch trigger.TriggerType {
+		case input.TriggerTypeDown:
+			return active, value
+		case input.TriggerTypePressed:
+			return active && !wasActive, value
+		case input.TriggerTypeReleased:
+			return !active && wasActive, value
+		case input.TriggerTypePassive:
+			return true, value
+		}
+	case input.InputTypeTouch:
+		return isTouched(trigger.TriggerType), 1
+	}
+	return false, 0
+}
@@ -0,0 +1,636 @@
+package input
+
+import (
+	"gorl/fw/core/clock"
+	"gorl/fw/core/input/gestures"
+	input "gorl/fw/core/input/input_event"
+	"gorl/fw/core/math"
+	"gorl/fw/core/settings"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+var (
+	actionJump  = input.RegisterAction("test_jump")
+	actionMoveX = input.RegisterAction("test_move_x")
+	actionShoot = input.RegisterAction("test_shoot")
+
+	actionSave   = input.RegisterAction("test_save")
+	actionCharge = input.RegisterAction("test_charge")
+	actionDodge  = input.RegisterAction("test_dodge")
+	actionTap    = input.RegisterAction("test_tap")
+
+	actionSwipe = input.RegisterAction("test_swipe")
+	actionPinch = input.RegisterAction("test_pinch")
+)
+
+func init() {
+	settings.UseFallbackSettings()
+	settings.CurrentSettings().EnableGamepad = true
+
+	input.RegisterContext("test_gamepad", input.BindingMap{
+		actionJump: {
+			{InputType: input.InputTypeKey, TriggerType: input.TriggerTypePressed, Key: rl.KeySpace},
+			{InputType: input.InputTypeGamepad, TriggerType: input.TriggerTypePressed, GamepadButton: rl.GamepadButtonRightFaceDown},
+		},
+		actionMoveX: {
+			{InputType: input.InputTypeKey, TriggerType: input.TriggerTypeDown, Key: rl.KeyD},
+			{InputType: input.InputTypeGamepadAxis, TriggerType: input.TriggerTypePassive, GamepadAxis: rl.GamepadAxisLeftX},
+		},
+		actionShoot: {
+			{InputType: input.InputTypeGamepadAxis, TriggerType: input.TriggerTypePressed,
+				GamepadAxis: rl.GamepadAxisRightTrigger, AxisDirection: 1, Threshold: 0.5},
+		},
+	})
+}
+
+// setupFake sets up a fake backend with the test context active for the given
+// players.
+func setupFake(t *testing.T, playerIndices ...int) *FakeBackend {
+	fake := NewFakeBackend()
+	SetBackend(fake)
+	previousAxes = map[axisKey]float32{}
+	touchPoints, previousTouchPoints, frameGestures = nil, nil, nil
+	recognizer.Reset()
+	for _, index := range playerIndices {
+		input.Player(index).SetContext("test_gamepad")
+	}
+	t.Cleanup(func() {
+		SetBackend(RaylibBackend{})
+		for _, player := range input.Players() {
+			player.ResetAll()
+			if player.Index() != 0 {
+				input.RemovePlayer(player.Index())
+			}
+		}
+	})
+	return fake
+}
+
+// eventsByAction returns the events of the frame by action and player.
+func eventsByAction() map[input.Action]map[int]*input.InputEvent {
+	result := map[input.Action]map[int]*input.InputEvent{}
+	for _, event := range checkForInputs() {
+		if result[event.Action] == nil {
+			result[event.Action] = map[int]*input.InputEvent{}
+		}
+		result[event.Action][event.Player] = event
+	}
+	return result
+}
+
+func TestKeysAndButtons(t *testing.T) {
+	fake := setupFake(t, 0)
+	fake.ConnectGamepad(0, true)
+
+	fake.SetKey(rl.KeySpace, true)
+	events := eventsByAction()
+	if event := events[actionJump][0]; event == nil || event.Device != input.DeviceKeyboardMouse {
+		t.Fatalf("expected a keyboard jump event, got %+v", event)
+	}
+
+	fake.NextFrame()
+	if events := eventsByAction(); events[actionJump] != nil {
+		t.Errorf("pressed trigger fired in two frames")
+	}
+
+	fake.SetKey(rl.KeySpace, false)
+	fake.SetGamepadButton(0, rl.GamepadButtonRightFaceDown, true)
+	events = eventsByAction()
+	if event := events[actionJump][0]; event == nil || event.Device != 0 || event.Value != 1 {
+		t.Errorf("expected a gamepad jump event, got %+v", event)
+	}
+}
+
+func TestAxes(t *testing.T) {
+	fake := setupFake(t, 0)
+	fake.ConnectGamepad(0, true)
+	input.Player(0).SetDeadzone(0.2)
+
+	// within the deadzone, the passive axis trigger reports 0
+	fake.SetGamepadAxis(0, rl.GamepadAxisLeftX, 0.1)
+	if event := eventsByAction()[actionMoveX][0]; event == nil || event.Value != 0 {
+		t.Errorf("expected an axis event with value 0, got %+v", event)
+	}
+
+	fake.SetGamepadAxis(0, rl.GamepadAxisLeftX, -0.6)
+	if event := eventsByAction()[actionMoveX][0]; event == nil || event.Value > -0.49 || event.Value < -0.51 {
+		t.Errorf("expected an axis event with value -0.5, got %+v", event)
+	}
+
+	// a key overrides the passive axis
+	fake.SetKey(rl.KeyD, true)
+	if event := eventsByAction()[actionMoveX][0]; event == nil || event.Value != 1 || event.Device != input.DeviceKeyboardMouse {
+		t.Errorf("expected the key to override the axis, got %+v", event)
+	}
+}
+
+func TestTriggerThreshold(t *testing.T) {
+	fake := setupFake(t, 0)
+	fake.ConnectGamepad(0, true)
+	input.Player(0).SetDeadzone(0)
+
+	if eventsByAction()[actionShoot] != nil {
+		t.Errorf("trigger at rest must not fire")
+	}
+	fake.SetGamepadAxis(0, rl.GamepadAxisRightTrigger, 0) // half pressed
+	if eventsByAction()[actionShoot] != nil {
+		t.Errorf("trigger below the threshold must not fire")
+	}
+	fake.SetGamepadAxis(0, rl.GamepadAxisRightTrigger, 0.6)
+	if eventsByAction()[actionShoot] == nil {
+		t.Errorf("trigger crossing the threshold must fire")
+	}
+	if eventsByAction()[actionShoot] != nil {
+		t.Errorf("pressed axis trigger fired in two frames")
+	}
+}
+
+func TestMultipleGamepads(t *testing.T) {
+	fake := setupFake(t, 0, 1)
+	fake.ConnectGamepad(0, true)
+	fake.ConnectGamepad(1, true)
+
+	fake.SetGamepadButton(1, rl.GamepadButtonRightFaceDown, true)
+	events := eventsByAction()
+	if events[actionJump][0] != nil {
+		t.Errorf("player 0 must not react to gamepad 1")
+	}
+	if event := events[actionJump][1]; event == nil || event.Device != 1 {
+		t.Errorf("expected player 1 to jump with gamepad 1, got %+v", event)
+	}
+
+	settings.CurrentSettings().EnableGamepad = false
+	defer func() { settings.CurrentSettings().EnableGamepad = true }()
+	fake.NextFrame()
+	fake.SetGamepadButton(1, rl.GamepadButtonRightFaceDown, false)
+	fake.NextFrame()
+	fake.SetGamepadButton(1, rl.GamepadButtonRightFaceDown, true)
+	if eventsByAction()[actionJump] != nil {
+		t.Errorf("gamepads must be ignored if disabled in the settings")
+	}
+}
+
+func TestCaptureGamepadButton(t *testing.T) {
+	fake := setupFake(t, 0)
+	fake.ConnectGamepad(0, true)
+
+	input.Player(0).CaptureRebind("test_gamepad", actionJump, 0, nil)
+	clock.Advance(0)
+	fake.SetGamepadButton(0, rl.GamepadButtonRightFaceUp, true)
+	if !pollCapture(&RecordedFrame{}) || input.IsCapturing() {
+		t.Fatalf("gamepad button was not captured")
+	}
+	trigger := input.Player(0).GetBindings("test_gamepad", actionJump)[0]
+	if trigger.InputType != input.InputTypeGamepad || trigger.GamepadButton != rl.GamepadButtonRightFaceUp {
+		t.Errorf("unexpected captured trigger %+v", trigger)
+	}
+}
+
+func TestCompositeActions(t *testing.T) {
+	fake := setupFake(t)
+	fake.ConnectGamepad(0, true)
+	input.Player(0).SetDeadzone(0)
+
+	// WASD diagonal is normalized
+	fake.SetKey(rl.KeyW, true)
+	fake.SetKey(rl.KeyD, true)
+	event := eventsByAction()[input.ActionMove][0]
+	if event == nil || event.Vector.X < 0.70 || event.Vector.X > 0.71 || event.Vector.Y > -0.70 || event.Vector.Y < -0.71 {
+		t.Fatalf("expected a normalized diagonal vector, got %+v", event)
+	}
+	fake.SetKey(rl.KeyW, false)
+	fake.SetKey(rl.KeyD, false)
+
+	// the stick is used if no key is held
+	fake.SetGamepadAxis(0, rl.GamepadAxisLeftX, -0.5)
+	event = eventsByAction()[input.ActionMove][0]
+	if event == nil || event.Vector.X != -0.5 || event.Vector.Y != 0 || event.Device != 0 {
+		t.Errorf("expected the stick vector, got %+v", event)
+	}
+	fake.SetGamepadAxis(0, rl.GamepadAxisLeftX, 0)
+	if eventsByAction()[input.ActionMove] != nil {
+		t.Errorf("movement vector must not fire while zero")
+	}
+
+	player := input.Player(0)
+	player.SetBindings(input.DefaultContext, actionMoveX, []input.Trigger{
+		input.AxisComposite(
+			input.Trigger{InputType: input.InputTypeKey, Key: rl.KeyLeft},
+			input.Trigger{InputType: input.InputTypeKey, Key: rl.KeyRight},
+		),
+	})
+	fake.SetKey(rl.KeyLeft, true)
+	if event := eventsByAction()[actionMoveX][0]; event == nil || event.Value != -1 {
+		t.Errorf("expected an axis value of -1, got %+v", event)
+	}
+}
+
+func TestChords(t *testing.T) {
+	fake := setupFake(t)
+	save := input.Trigger{InputType: input.InputTypeKey, TriggerType: input.TriggerTypePressed, Key: rl.KeyS}
+	input.Player(0).SetBindings(input.DefaultContext, actionSave, []input.Trigger{input.Chord(save, rl.KeyLeftControl)})
+
+	fake.SetKey(rl.KeyS, true)
+	events := eventsByAction()
+	if events[actionSave] != nil || events[input.ActionMove] == nil {
+		t.Errorf("S alone must move, not save")
+	}
+
+	fake.NextFrame()
+	fake.SetKey(rl.KeyS, false)
+	fake.NextFrame()
+	input.Player(1)
+	fake.SetKey(rl.KeyRightControl, true)
+	fake.SetKey(rl.KeyS, true)
+	events = eventsByAction()
+	if events[actionSave][0] == nil {
+		t.Errorf("Ctrl+S must save, with either control key")
+	}
+	if events[input.ActionMoveDown][0] != nil || events[input.ActionMove][0] != nil {
+		t.Errorf("Ctrl+S must not also move")
+	}
+	// player 1 has no chord, so its S is not shadowed
+	if events[input.ActionMove][1] == nil {
+		t.Errorf("the chord of player 0 must not shadow S for player 1")
+	}
+}
+
+func TestInteractions(t *testing.T) {
+	fake := setupFake(t)
+	clock.Reset()
+	defer clock.Reset()
+	interactions = map[triggerKey]*interactionState{}
+
+	key := func(k int32) input.Trigger { return input.Trigger{InputType: input.InputTypeKey, Key: k} }
+	player := input.Player(0)
+	player.SetBindings(input.DefaultContext, actionCharge, []input.Trigger{input.WithInteraction(key(rl.KeyC), input.InteractionLongPress, 0.5)})
+	player.SetBindings(input.DefaultContext, actionDodge, []input.Trigger{input.WithInteraction(key(rl.KeyX), input.InteractionDoubleTap, 0.3)})
+	player.SetBindings(input.DefaultContext, actionTap, []input.Trigger{input.WithInteraction(key(rl.KeyT), input.InteractionTap, 0.2)})
+
+	count := func(action input.Action) int {
+		if eventsByAction()[action] != nil {
+			return 1
+		}
+		return 0
+	}
+
+	// long press fires once after the duration
+	fake.SetKey(rl.KeyC, true)
+	fired := 0
+	for i := 0; i < 10; i++ {
+		fired += count(actionCharge)
+		clock.Advance(0.1)
+	}
+	if fired != 1 {
+		t.Errorf("expected the long press to fire once, got %d", fired)
+	}
+	fake.SetKey(rl.KeyC, false)
+
+	// double tap fires on the second press within the duration
+	for i, down := range []bool{true, false, true} {
+		fake.SetKey(rl.KeyX, down)
+		if fired := count(actionDodge); (i == 2) != (fired == 1) {
+			t.Errorf("unexpected double tap result in step %d", i)
+		}
+		clock.Advance(0.1)
+	}
+
+	// tap fires on a quick release, but not on a slow one
+	fake.SetKey(rl.KeyT, true)
+	count(actionTap)
+	clock.Advance(0.1)
+	fake.SetKey(rl.KeyT, false)
+	if count(actionTap) != 1 {
+		t.Errorf("quick release must tap")
+	}
+	fake.SetKey(rl.KeyT, true)
+	count(actionTap)
+	clock.Advance(0.5)
+	fake.SetKey(rl.KeyT, false)
+	if count(actionTap) != 0 {
+		t.Errorf("slow release must not tap")
+	}
+}
+
+func TestInteractionsAcrossContexts(t *testing.T) {
+	fake := setupFake(t, 0)
+	clock.Reset()
+	defer clock.Reset()
+	interactions = map[triggerKey]*interactionState{}
+
+	player := input.Player(0)
+	player.SetBindings("test_gamepad", actionCharge, []input.Trigger{
+		input.WithInteraction(input.Trigger{InputType: input.InputTypeKey, Key: rl.KeyC}, input.InteractionLongPress, 0.5),
+	})
+	player.SetBindings(input.DefaultContext, actionCharge, []input.Trigger{{InputType: input.InputTypeKey, Key: rl.KeyX}})
+
+	fake.SetKey(rl.KeyC, true)
+	fired := 0
+	for i := 0; i < 3; i++ {
+		fired += len(eventsByAction()[actionCharge])
+		clock.Advance(0.1)
+	}
+
+	// enabling a context before it moves the long press to another index,
+	// which must not restart it
+	player.SetContext(input.DefaultContext)
+	player.EnableContext("test_gamepad")
+	for i := 0; i < 3; i++ {
+		fired += len(eventsByAction()[actionCharge])
+		clock.Advance(0.1)
+	}
+	if fired != 1 {
+		t.Errorf("expected the long press to fire once, got %d", fired)
+	}
+
+	// the state is dropped once the trigger is not bound anymore
+	player.DisableContext("test_gamepad")
+	eventsByAction()
+	if len(interactions) != 0 {
+		t.Errorf("expected no interaction state, got %v", len(interactions))
+	}
+}
+
+// textRecorder records the text events it receives, and gives up the focus on
+// escape.
+type textRecorder struct {
+	events []input.TextEvent
+}
+
+func (r *textRecorder) OnTextInput(event *input.TextEvent) {
+	r.events = append(r.events, *event)
+	if event.Type == input.TextEventKey && event.Key == rl.KeyEscape {
+		input.ReleaseTextFocus(r)
+	}
+}
+
+func TestTextInput(t *testing.T) {
+	fake := setupFake(t, 0)
+	fake.ConnectGamepad(0, true)
+	recorder := &textRecorder{}
+	input.SetTextFocus(recorder)
+	t.Cleanup(func() { input.ReleaseTextFocus(recorder) })
+
+	// typed characters go to the receiver, the key triggers are suppressed
+	fake.TypeText("hé ")
+	fake.SetKey(rl.KeySpace, true)
+	fake.SetGamepadButton(0, rl.GamepadButtonRightFaceDown, true)
+	dispatchText(readText())
+	if len(recorder.events) != 3 || recorder.events[1].Char != 'é' {
+		t.Fatalf("expected 3 characters, got %+v", recorder.events)
+	}
+	if event := eventsByAction()[actionJump][0]; event == nil || event.Device != 0 {
+		t.Errorf("expected only the gamepad to trigger jump while typing, got %+v", event)
+	}
+
+	// editing keys are repeated while held
+	recorder.events = nil
+	fake.NextFrame()
+	fake.SetKey(rl.KeyBackspace, true)
+	dispatchText(readText())
+	fake.NextFrame()
+	fake.RepeatKey(rl.KeyBackspace)
+	dispatchText(readText())
+	if len(recorder.events) != 2 || recorder.events[0].Repeat || !recorder.events[1].Repeat {
+		t.Errorf("expected a backspace and a repeated backspace, got %+v", recorder.events)
+	}
+
+	// Ctrl+V pastes the clipboard
+	recorder.events = nil
+	fake.NextFrame()
+	SetClipboardText("pasted")
+	fake.SetKey(rl.KeyLeftControl, true)
+	fake.SetKey(rl.KeyV, true)
+	dispatchText(readText())
+	if len(recorder.events) != 1 || recorder.events[0].Type != input.TextEventPaste || recorder.events[0].Text != "pasted" {
+		t.Errorf("expected a paste event, got %+v", recorder.events)
+	}
+
+	// after escape, the receiver has no focus and the keys trigger actions
+	// again
+	fake.NextFrame()
+	fake.SetKey(rl.KeyEscape, true)
+	fake.TypeText("x")
+	dispatchText(readText())
+	if input.GetTextFocus() != nil {
+		t.Fatalf("expected escape to release the focus")
+	}
+	fake.NextFrame()
+	fake.SetKey(rl.KeySpace, false)
+	fake.SetGamepadButton(0, rl.GamepadButtonRightFaceDown, false)
+	fake.NextFrame()
+	fake.SetKey(rl.KeySpace, true)
+	if event := eventsByAction()[actionJump][0]; event == nil || event.Device != input.DeviceKeyboardMouse {
+		t.Errorf("expected a keyboard jump after the focus was released, got %+v", event)
+	}
+}
+
+// eventRecorder collects the events it receives.
+type eventRecorder struct {
+	events []input.InputEvent
+}
+
+func (r *eventRecorder) OnInputEvent(event *input.InputEvent) bool {
+	r.events = append(r.events, *event)
+	return true
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	fake := setupFake(t, 0)
+	clock.Reset()
+	t.Cleanup(clock.Reset)
+
+	// frame i has a delta of i/100, space is held in frames 1 and 2
+	runFrames := func(live bool) ([]input.InputEvent, []float32, []float32) {
+		recorder := &eventRecorder{}
+		deltas, randoms := []float32{}, []float32{}
+		for i := 1; i <= 4; i++ {
+			if live {
+				clock.Advance(float32(i) / 100)
+				fake.SetKey(rl.KeySpace, i == 1 || i == 2)
+				fake.SetMousePosition(rl.NewVector2(float32(i), 0))
+			} else {
+				clock.Tick()
+			}
+			deltas = append(deltas, clock.UnscaledDeltaTime())
+			randoms = append(randoms, math.RandRange(0, 1))
+			HandleInputEvents([]InputReceiver{recorder}, nil)
+			fake.NextFrame()
+		}
+		return recorder.events, deltas, randoms
+	}
+
+	StartRecording()
+	recordedEvents, recordedDeltas, recordedRandoms := runFrames(true)
+	recording := StopRecording()
+	if len(recording.Frames) != 4 || len(recordedEvents) == 0 {
+		t.Fatalf("expected 4 recorded frames with events, got %v frames and %v events", len(recording.Frames), len(recordedEvents))
+	}
+
+	path := filepath.Join(t.TempDir(), "input.rec")
+	if err := SaveRecording(path, recording); err != nil {
+		t.Fatalf("saving the recording failed: %v", err)
+	}
+	loaded, err := LoadRecording(path)
+	if err != nil {
+		t.Fatalf("loading the recording failed: %v", err)
+	}
+
+	// the replay ignores the devices, which have no input now
+	fake.SetKey(rl.KeySpace, false)
+	fake.NextFrame()
+	StartReplay(loaded)
+	replayedEvents, replayedDeltas, replayedRandoms := runFrames(false)
+	if IsReplaying() {
+		t.Errorf("expected the replay to stop after the last frame")
+	}
+
+	if !reflect.DeepEqual(recordedEvents, replayedEvents) {
+		t.Errorf("replayed events differ:\nrecorded %+v\nreplayed %+v", recordedEvents, replayedEvents)
+	}
+	if !reflect.DeepEqual(recordedDeltas, replayedDeltas) {
+		t.Errorf("replayed deltas differ: recorded %v, replayed %v", recordedDeltas, replayedDeltas)
+	}
+	if !reflect.DeepEqual(recordedRandoms, replayedRandoms) {
+		t.Errorf("replayed random numbers differ: recorded %v, replayed %v", recordedRandoms, replayedRandoms)
+	}
+}
+
+// offsetViewport shows the world shifted by an offset in an area of the
+// screen.
+type offsetViewport struct {
+	area   rl.Rectangle
+	offset rl.Vector2
+}
+
+func (v offsetViewport) ScreenToWorld(screenPos rl.Vector2) rl.Vector2 {
+	return rl.Vector2Add(screenPos, v.offset)
+}
+func (v offsetViewport) ContainsScreenPoint(screenPos rl.Vector2) bool {
+	return rl.CheckCollisionPointRec(screenPos, v.area)
+}
+
+// pointerRecorder is hit inside its bounds, and records the pointer events
+// and the world position of the input events it receives.
+type pointerRecorder struct {
+	bounds        rl.Rectangle
+	pointerEvents []input.PointerEventType
+	worldPosition rl.Vector2
+}
+
+func (r *pointerRecorder) HitTest(worldPosition rl.Vector2) bool {
+	return rl.CheckCollisionPointRec(worldPosition, r.bounds)
+}
+func (r *pointerRecorder) OnPointerEvent(event *input.PointerEvent) {
+	r.pointerEvents = append(r.pointerEvents, event.Type)
+}
+func (r *pointerRecorder) OnInputEvent(event *input.InputEvent) bool {
+	r.worldPosition, _ = event.GetWorldMousePosition()
+	return true
+}
+
+func TestPointerSplitScreen(t *testing.T) {
+	fake := setupFake(t, 0)
+	pointer = pointerState{}
+
+	// two viewports side by side, the right one shows the world shifted by
+	// 1000
+	left := offsetViewport{area: rl.NewRectangle(0, 0, 100, 100)}
+	right := offsetViewport{area: rl.NewRectangle(100, 0, 100, 100), offset: rl.NewVector2(1000, 0)}
+
+	back := &pointerRecorder{bounds: rl.NewRectangle(1100, 0, 100, 100)}
+	front := &pointerRecorder{bounds: rl.NewRectangle(1150, 0, 10, 10)}
+	paused := &pointerRecorder{bounds: rl.NewRectangle(1100, 0, 100, 100)}
+	drawn := []DrawnReceiver{
+		{Receiver: back, Viewport: left}, {Receiver: back, Viewport: right},
+		{Receiver: front, Viewport: left}, {Receiver: front, Viewport: right},
+		{Receiver: paused, Viewport: left}, {Receiver: paused, Viewport: right},
+	}
+	// the paused receiver is drawn, but not processed
+	receivers := []InputReceiver{back, front}
+
+	// space causes an event, which carries the world position
+	fake.SetKey(rl.KeySpace, true)
+	fake.SetMousePosition(rl.NewVector2(155, 5))
+	HandleInputEvents(receivers, drawn)
+	if back.worldPosition != rl.NewVector2(1155, 5) {
+		t.Errorf("expected the world position of the right viewport, got %v", back.worldPosition)
+	}
+	fake.SetMousePosition(rl.NewVector2(120, 5))
+	HandleInputEvents(receivers, drawn)
+
+	if want := []input.PointerEventType{input.PointerEnter, input.PointerHover, input.PointerExit}; !reflect.DeepEqual(front.pointerEvents, want) {
+		t.Errorf("unexpected pointer events of the front receiver: got %v, want %v", front.pointerEvents, want)
+	}
+	if want := []input.PointerEventType{input.PointerEnter, input.PointerHover}; !reflect.DeepEqual(back.pointerEvents, want) {
+		t.Errorf("unexpected pointer events of the back receiver: got %v, want %v", back.pointerEvents, want)
+	}
+	if len(paused.pointerEvents) != 0 {
+		t.Errorf("the paused receiver received pointer events: %v", paused.pointerEvents)
+	}
+}
+
+func TestTouch(t *testing.T) {
+	fake := setupFake(t)
+	clock.Reset()
+	t.Cleanup(clock.Reset)
+	player := input.Player(0)
+	player.SetBindings(input.DefaultContext, actionSwipe, []input.Trigger{
+		{InputType: input.InputTypeGesture, Gesture: gestures.Swipe},
+	})
+	player.SetBindings(input.DefaultContext, actionPinch, []input.Trigger{
+		{InputType: input.InputTypeGesture, Gesture: gestures.Pinch},
+	})
+
+	frame := func(delta float32) map[input.Action]map[int]*input.InputEvent {
+		fake.NextFrame()
+		clock.Advance(delta)
+		pollTouch()
+		return eventsByAction()
+	}
+
+	// touching the screen clicks at the touch point
+	fake.SetTouch(3, rl.NewVector2(100, 100))
+	events := frame(0)
+	click := events[input.ActionClickDown][0]
+	if click == nil || click.Device != input.DeviceTouch || click.GetScreenSpaceMousePosition() != rl.NewVector2(100, 100) {
+		t.Fatalf("expected a touch click at (100, 100), got %+v", click)
+	}
+	if pressed, _ := pollPointerButtons(); pressed != 1<<rl.MouseButtonLeft || !IsPointerPressed() {
+		t.Errorf("touching the screen must press the pointer")
+	}
+
+	// a fast flick to the right is a swipe
+	fake.SetTouch(3, rl.NewVector2(160, 100))
+	events = frame(0.05)
+	if events[input.ActionClickHeld][0] == nil || events[input.ActionClickDown] != nil {
+		t.Errorf("expected the click to be held, got %+v", events)
+	}
+	fake.ReleaseTouch(3)
+	events = frame(0.05)
+	swipe := events[actionSwipe][0]
+	if swipe == nil || swipe.Vector != rl.NewVector2(1, 0) || swipe.GetScreenSpaceMousePosition() != rl.NewVector2(160, 100) {
+		t.Errorf("expected a swipe to the right, got %+v", swipe)
+	}
+	if events[input.ActionClickUp][0] == nil || !IsPointerReleased() {
+		t.Errorf("lifting the finger must release the click")
+	}
+
+	// two fingers moving apart pinch
+	fake.SetTouch(0, rl.NewVector2(100, 100))
+	fake.SetTouch(1, rl.NewVector2(200, 100))
+	frame(0.5)
+	fake.SetTouch(0, rl.NewVector2(50, 100))
+	fake.SetTouch(1, rl.NewVector2(250, 100))
+	pinch := frame(0.05)[actionPinch][0]
+	if pinch == nil || pinch.Value != 2 || pinch.GetScreenSpaceMousePosition() != rl.NewVector2(150, 100) {
+		t.Errorf("expected a pinch with a scale of 2, got %+v", pinch)
+	}
+	if points := GetTouchPoints(); len(points) != 2 || points[0].ID != 0 {
+		t.Errorf("expected two touch points, got %+v", points)
+	}
+}
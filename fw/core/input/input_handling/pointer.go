@@ -0,0 +1,173 @@
+package input
+
+import (
+	input "gorl/fw/core/input/input_event"
+	"reflect"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// A Viewport is an area of the screen that shows the world, e.g. a
+// render.Camera. It is used to resolve the cursor position in the world of a
+// receiver.
+type Viewport interface {
+	ScreenToWorld(screenPos rl.Vector2) rl.Vector2
+	ContainsScreenPoint(screenPos rl.Vector2) bool
+}
+
+// DrawnReceiver is a receiver that was drawn this frame, and the viewport it
+// was drawn with. render.Draw returns them in draw order, back to front.
+type DrawnReceiver struct {
+	Receiver InputReceiver
+	Viewport Viewport
+}
+
+// PointerReceiver is implemented by receivers that are hit-tested against the
+// pointer, e.g. clickable world objects. Only the front-most drawn receiver
+// under the pointer receives pointer events.
+type PointerReceiver interface {
+	// HitTest returns true if the world position is inside the receiver.
+	HitTest(worldPosition rl.Vector2) bool
+	OnPointerEvent(event *input.PointerEvent)
+}
+
+// KeyedReceiver is implemented by receivers that are recreated every frame,
+// e.g. wrappers around entities. The key identifies the receiver across
+// frames and lists. Other receivers are identified by their value.
+type KeyedReceiver interface {
+	ReceiverKey() any
+}
+
+// receiverKey returns the key that identifies the receiver, or nil if the
+// receiver cannot be identified.
+func receiverKey(receiver InputReceiver) any {
+	if keyed, ok := receiver.(KeyedReceiver); ok {
+		return keyed.ReceiverKey()
+	}
+	if receiver == nil || !reflect.ValueOf(receiver).Comparable() {
+		return nil
+	}
+	return receiver
+}
+
+// pointerButtons is the number of mouse buttons that cause pointer events.
+const pointerButtons = rl.MouseButtonBack + 1
+
+// pointerState is the state of the pointer across frames.
+type pointerState struct {
+	hovered         PointerReceiver
+	hoveredKey      any
+	hoveredViewport Viewport
+	// pressedOn holds the key of the receiver each button was pressed on.
+	pressedOn [pointerButtons]any
+}
+
+var pointer pointerState
+
+// pollPointerButtons returns bit masks of the mouse buttons that were pressed
+// and released this frame. Touching the screen counts as the left button.
+func pollPointerButtons() (pressed, released uint8) {
+	for button := int32(0); button < pointerButtons; button++ {
+		if backend.IsMouseButtonPressed(button) {
+			pressed |= 1 << button
+		}
+		if backend.IsMouseButtonReleased(button) {
+			released |= 1 << button
+		}
+	}
+	if isTouched(input.TriggerTypePressed) {
+		pressed |= 1 << rl.MouseButtonLeft
+	}
+	if isTouched(input.TriggerTypeReleased) {
+		released |= 1 << rl.MouseButtonLeft
+	}
+	return pressed, released
+}
+
+// resolveViewports returns the viewport of every drawn receiver. If a
+// receiver was drawn with several viewports, the front-most one under the
+// cursor is used, or the front-most one if none is under the cursor.
+func resolveViewports(drawn []DrawnReceiver, cursor rl.Vector2) map[any]Viewport {
+	viewports := make(map[any]Viewport, len(drawn))
+	for _, d := range drawn {
+		key := receiverKey(d.Receiver)
+		if key == nil || d.Viewport == nil {
+			continue
+		}
+		current, exists := viewports[key]
+		if !exists || d.Viewport.ContainsScreenPoint(cursor) || !current.ContainsScreenPoint(cursor) {
+			viewports[key] = d.Viewport
+		}
+	}
+	return viewports
+}
+
+// dispatchPointer hit-tests the drawn pointer receivers front to back, and
+// sends the pointer events of the frame to the front-most one that is hit.
+// Only receivers in inputReceivers are hit-tested, so entities that are not
+// processed, e.g. because the game is paused, receive no pointer events.
+func dispatchPointer(inputReceivers []InputReceiver, drawn []DrawnReceiver, frame *RecordedFrame) {
+	processed := make(map[any]bool, len(inputReceivers))
+	for _, receiver := range inputReceivers {
+		if key := receiverKey(receiver); key != nil {
+			processed[key] = true
+		}
+	}
+
+	cursor := frame.CursorPosition
+	var hit PointerReceiver
+	var hitKey any
+	var hitViewport Viewport
+	for i := len(drawn) - 1; i >= 0; i-- {
+		d := drawn[i]
+		receiver, ok := d.Receiver.(PointerReceiver)
+		key := receiverKey(d.Receiver)
+		if !ok || key == nil || !processed[key] || d.Viewport == nil || !d.Viewport.ContainsScreenPoint(cursor) {
+			continue
+		}
+		if receiver.HitTest(d.Viewport.ScreenToWorld(cursor)) {
+			hit, hitKey, hitViewport = receiver, key, d.Viewport
+			break
+		}
+	}
+
+	send := func(receiver PointerReceiver, viewport Viewport, eventType input.PointerEventType, button int32) {
+		receiver.OnPointerEvent(&input.PointerEvent{
+			Type:           eventType,
+			Button:         button,
+			ScreenPosition: cursor,
+			WorldPosition:  viewport.ScreenToWorld(cursor),
+		})
+	}
+
+	if hitKey != pointer.hoveredKey {
+		if pointer.hovered != nil {
+			send(pointer.hovered, pointer.hoveredViewport, input.PointerExit, 0)
+		}
+		if hit != nil {
+			send(hit, hitViewport, input.PointerEnter, 0)
+		}
+	}
+	pointer.hovered, pointer.hoveredKey, pointer.hoveredViewport = hit, hitKey, hitViewport
+
+	if hit != nil {
+		send(hit, hitViewport, input.PointerHover, 0)
+	}
+	for button := int32(0); button < pointerButtons; button++ {
+		pressed := frame.PointerPressed&(1<<button) != 0
+		released := frame.PointerReleased&(1<<button) != 0
+		if hit != nil && pressed {
+			send(hit, hitViewport, input.PointerPressed, button)
+			pointer.pressedOn[button] = hitKey
+		}
+		if released {
+			if hit != nil {
+				send(hit, hitViewport, input.PointerReleased, button)
+				if pointer.pressedOn[button] == hitKey {
+					send(hit, hitViewport, input.PointerClick, button)
+				}
+			}
+			pointer.pressedOn[button] = nil
+		}
+	}
+}
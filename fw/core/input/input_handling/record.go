@@ -0,0 +1,219 @@
+package input
+
+import (
+	"compress/gzip"
+	"encoding/gob"
+	"gorl/fw/core/clock"
+	input "gorl/fw/core/input/input_event"
+	"gorl/fw/core/logging"
+	"gorl/fw/core/math"
+	"os"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// A Recording holds the input of a session, frame by frame, so the session
+// can be replayed exactly, e.g. to reproduce a bug report.
+//
+// Not the state of the devices is recorded, but the events they caused, so a
+// replay does not depend on the bindings. Together with the frame times and
+// the seed of math.RandRange, a replay reproduces the session, as long as the
+// game only uses the clock, the input events and math.RandRange.
+type Recording struct {
+	// Seed is the seed of math.RandRange when the recording started.
+	Seed   int64
+	Frames []RecordedFrame
+}
+
+// RecordedFrame is the input of a single frame.
+type RecordedFrame struct {
+	// Delta is the unscaled duration of the frame, see clock.UnscaledDeltaTime.
+	Delta          float32
+	CursorPosition rl.Vector2
+	Events         []RecordedEvent
+	// Text holds the text events sent to the receiver with the text focus.
+	Text []input.TextEvent
+	// PointerPressed and PointerReleased are bit masks of the mouse buttons
+	// that were pressed and released, for the pointer events.
+	PointerPressed, PointerReleased uint8
+	// Capture is the result of a running capture that ended this frame, if
+	// any, see input.CaptureNextTrigger.
+	Capture *RecordedCapture
+}
+
+// RecordedEvent is an input.InputEvent in a recording.
+type RecordedEvent struct {
+	Action input.Action
+	Player int
+	Device int32
+	Value  float32
+	Vector rl.Vector2
+}
+
+// RecordedCapture is the result of a capture in a recording.
+type RecordedCapture struct {
+	Trigger input.Trigger
+	Ok      bool
+}
+
+// recording is the running recording, or nil.
+var recording *Recording
+
+// replay is the running replay, or nil.
+var replay *replayState
+
+type replayState struct {
+	recording *Recording
+	// frame is the index of the next frame to replay.
+	frame int
+	// source is the clock source before the replay started.
+	source clock.Source
+}
+
+// StartRecording starts recording the input of every frame. The random number
+// generator is reseeded and the seed stored in the recording, so for an exact
+// replay, the recording should be started before the game is initialized.
+func StartRecording() {
+	if replay != nil {
+		logging.Warning("Cannot record input during a replay.")
+		return
+	}
+	seed := math.GetSeed()
+	math.SetSeed(seed)
+	recording = &Recording{Seed: seed}
+}
+
+// StopRecording stops the running recording and returns it, or nil if there
+// is none.
+func StopRecording() *Recording {
+	stopped := recording
+	recording = nil
+	return stopped
+}
+
+// IsRecording returns true if the input is being recorded.
+func IsRecording() bool {
+	return recording != nil
+}
+
+// StartReplay replays the recording: every frame, the recorded events are
+// dispatched in place of the input of the devices, and the clock reports the
+// recorded frame times. The random number generator is reseeded with the seed
+// of the recording. The replay stops after the last recorded frame.
+func StartReplay(r *Recording) {
+	if replay != nil {
+		StopReplay()
+	}
+	if len(r.Frames) == 0 {
+		logging.Warning("Cannot replay an empty input recording.")
+		return
+	}
+	recording = nil
+	math.SetSeed(r.Seed)
+	replay = &replayState{recording: r, source: clock.GetSource()}
+	clock.SetSource(replaySource)
+}
+
+// StopReplay stops the running replay, and gives control back to the devices.
+func StopReplay() {
+	if replay == nil {
+		return
+	}
+	clock.SetSource(replay.source)
+	replay = nil
+}
+
+// IsReplaying returns true if a recording is being replayed.
+func IsReplaying() bool {
+	return replay != nil
+}
+
+// replaySource is the clock source during a replay. It reports the recorded
+// time of the frame that is replayed next.
+func replaySource() float32 {
+	if replay == nil || replay.frame >= len(replay.recording.Frames) {
+		return 0
+	}
+	return replay.recording.Frames[replay.frame].Delta
+}
+
+// replayFrame dispatches the text and capture of the next recorded frame, and
+// returns the frame and its events.
+func replayFrame() (*RecordedFrame, []*input.InputEvent) {
+	frame := &replay.recording.Frames[replay.frame]
+	replay.frame++
+	if replay.frame >= len(replay.recording.Frames) {
+		logging.Info("Input replay finished after %v frames.", replay.frame)
+		StopReplay()
+	}
+
+	if frame.Capture != nil {
+		input.CompleteCapture(frame.Capture.Trigger, frame.Capture.Ok)
+	}
+	text := make([]*input.TextEvent, len(frame.Text))
+	for i := range frame.Text {
+		text[i] = &frame.Text[i]
+	}
+	dispatchText(text)
+
+	events := make([]*input.InputEvent, len(frame.Events))
+	for i, recorded := range frame.Events {
+		event := input.NewInputEvent(recorded.Action, frame.CursorPosition)
+		event.Player = recorded.Player
+		event.Device = recorded.Device
+		event.Value = recorded.Value
+		event.Vector = recorded.Vector
+		events[i] = event
+	}
+	return frame, events
+}
+
+// recordFrame adds the frame and its events to the running recording.
+func recordFrame(frame *RecordedFrame, events []*input.InputEvent) {
+	for _, event := range events {
+		frame.Events = append(frame.Events, RecordedEvent{
+			Action: event.Action,
+			Player: event.Player,
+			Device: event.Device,
+			Value:  event.Value,
+			Vector: event.Vector,
+		})
+	}
+	recording.Frames = append(recording.Frames, *frame)
+}
+
+// SaveRecording writes the recording to a compressed file at the given path.
+func SaveRecording(path string, r *Recording) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := gzip.NewWriter(file)
+	if err := gob.NewEncoder(writer).Encode(r); err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+// LoadRecording reads a recording written by SaveRecording.
+func LoadRecording(path string) (*Recording, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	r := &Recording{}
+	if err := gob.NewDecoder(reader).Decode(r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
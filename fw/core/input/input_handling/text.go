@@ -0,0 +1,83 @@
+package input
+
+import (
+	input "gorl/fw/core/input/input_event"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// editingKeys are the keys sent as TextEventKey to the receiver with the text
+// focus, in the order they are sent.
+var editingKeys = []int32{
+	rl.KeyBackspace, rl.KeyDelete,
+	rl.KeyLeft, rl.KeyRight, rl.KeyUp, rl.KeyDown, rl.KeyHome, rl.KeyEnd,
+	rl.KeyEnter, rl.KeyKpEnter, rl.KeyTab, rl.KeyEscape,
+}
+
+// repeatingKeys are the editing keys that are repeated while held.
+var repeatingKeys = map[int32]bool{
+	rl.KeyBackspace: true, rl.KeyDelete: true,
+	rl.KeyLeft: true, rl.KeyRight: true, rl.KeyUp: true, rl.KeyDown: true,
+}
+
+// shortcutKeys are sent as TextEventKey while Ctrl is held, e.g. Ctrl+C.
+var shortcutKeys = []int32{rl.KeyA, rl.KeyC, rl.KeyX, rl.KeyZ, rl.KeyY}
+
+// readText returns the characters and editing keys of this frame as text
+// events, if a receiver has the text focus. Ctrl+V and Shift+Insert paste the
+// clipboard as a single TextEventPaste.
+func readText() []*input.TextEvent {
+	if input.GetTextFocus() == nil {
+		return nil
+	}
+
+	ctrl := backend.IsKeyDown(rl.KeyLeftControl) || backend.IsKeyDown(rl.KeyRightControl)
+	shift := backend.IsKeyDown(rl.KeyLeftShift) || backend.IsKeyDown(rl.KeyRightShift)
+
+	// characters are read before anything is dispatched, so the queue of
+	// the backend is emptied even if the receiver loses the focus.
+	chars := []rune{}
+	for char := backend.GetCharPressed(); char != 0; char = backend.GetCharPressed() {
+		chars = append(chars, rune(char))
+	}
+
+	events := []*input.TextEvent{}
+	for _, char := range chars {
+		events = append(events, &input.TextEvent{Type: input.TextEventChar, Char: char})
+	}
+	for _, key := range editingKeys {
+		pressed := backend.IsKeyPressed(key)
+		repeated := repeatingKeys[key] && !pressed && backend.IsKeyPressedRepeat(key)
+		if pressed || repeated {
+			events = append(events, &input.TextEvent{Type: input.TextEventKey, Key: key, Repeat: repeated, Ctrl: ctrl, Shift: shift})
+		}
+	}
+	if ctrl {
+		for _, key := range shortcutKeys {
+			if backend.IsKeyPressed(key) {
+				events = append(events, &input.TextEvent{Type: input.TextEventKey, Key: key, Ctrl: ctrl, Shift: shift})
+			}
+		}
+	}
+	if (ctrl && backend.IsKeyPressed(rl.KeyV)) || (shift && backend.IsKeyPressed(rl.KeyInsert)) {
+		if text := backend.GetClipboardText(); text != "" {
+			events = append(events, &input.TextEvent{Type: input.TextEventPaste, Text: text})
+		}
+	}
+
+	return events
+}
+
+// dispatchText sends the text events to the receiver with the text focus, and
+// returns the events it received.
+func dispatchText(events []*input.TextEvent) []*input.TextEvent {
+	receiver := input.GetTextFocus()
+	for i, event := range events {
+		// the receiver may give up the focus, e.g. on enter or escape
+		if !input.HasTextFocus(receiver) {
+			return events[:i]
+		}
+		receiver.OnTextInput(event)
+	}
+	return events
+}
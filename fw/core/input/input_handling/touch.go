@@ -0,0 +1,132 @@
+package input
+
+import (
+	"gorl/fw/core/clock"
+	"gorl/fw/core/input/gestures"
+	input "gorl/fw/core/input/input_event"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// touchPoints are the touch points that are down, previousTouchPoints those
+// of the last frame. They are read once per frame, see pollTouch.
+var touchPoints, previousTouchPoints []gestures.Pointer
+
+// recognizer recognizes the gestures of the touch points, and the mouse.
+var recognizer = gestures.NewRecognizer(gestures.DefaultConfig())
+
+// frameGestures are the gestures recognized this frame.
+var frameGestures []gestures.Gesture
+
+// pollTouch reads the touch points and recognizes the gestures of the frame.
+// While the screen is not touched, the left mouse button acts as a pointer,
+// so gestures can be used with a mouse as well.
+func pollTouch() {
+	previousTouchPoints = touchPoints
+	count := backend.GetTouchPointCount()
+	touchPoints = make([]gestures.Pointer, 0, count)
+	for index := int32(0); index < count; index++ {
+		touchPoints = append(touchPoints, gestures.Pointer{
+			ID:       backend.GetTouchPointId(index),
+			Position: backend.GetTouchPosition(index),
+		})
+	}
+
+	pointers := touchPoints
+	if len(pointers) == 0 && backend.IsMouseButtonDown(rl.MouseButtonLeft) {
+		pointers = []gestures.Pointer{{ID: gestures.MousePointer, Position: backend.GetMousePosition()}}
+	}
+	frameGestures = recognizer.Update(clock.UnscaledTotalTime(), pointers)
+}
+
+// GetTouchPoints returns the touch points that are down, in the order they
+// touched the screen.
+func GetTouchPoints() []gestures.Pointer {
+	return touchPoints
+}
+
+// GetGestures returns the gestures recognized this frame.
+func GetGestures() []gestures.Gesture {
+	return frameGestures
+}
+
+// SetGestureConfig changes the thresholds of the gesture recognition.
+func SetGestureConfig(config gestures.Config) {
+	recognizer.SetConfig(config)
+}
+
+// findGesture returns the first gesture of the given type recognized this
+// frame.
+func findGesture(gestureType gestures.Type) (gestures.Gesture, bool) {
+	for _, gesture := range frameGestures {
+		if gesture.Type == gestureType {
+			return gesture, true
+		}
+	}
+	return gestures.Gesture{}, false
+}
+
+// gestureResult returns the result of a gesture trigger. It is active in the
+// frame its gesture is recognized.
+func gestureResult(trigger input.Trigger) triggerResult {
+	gesture, ok := findGesture(trigger.Gesture)
+	if !ok {
+		return triggerResult{}
+	}
+	result := triggerResult{active: true, value: 1, position: gesture.Position, hasPosition: true}
+	switch gesture.Type {
+	case gestures.Drag, gestures.DragStart, gestures.DragEnd:
+		result.vector = gesture.Delta
+	case gestures.Pinch:
+		result.value = gesture.Scale
+		result.vector = gesture.Delta
+	case gestures.Swipe:
+		result.vector = gesture.Direction
+	}
+	return result
+}
+
+// isTouched returns true if a touch trigger of the given trigger type is
+// active this frame. Touch triggers react to any touch point.
+func isTouched(triggerType input.TriggerType) bool {
+	touched, wasTouched := len(touchPoints) > 0, len(previousTouchPoints) > 0
+	switch triggerType {
+	case input.TriggerTypeDown:
+		return touched
+	case input.TriggerTypePressed:
+		return touched && !wasTouched
+	case input.TriggerTypeReleased:
+		return !touched && wasTouched
+	case input.TriggerTypePassive:
+		return true
+	}
+	return false
+}
+
+// GetPointerPosition returns the position of the primary pointer: the first
+// touch point while the screen is touched, the mouse otherwise.
+func GetPointerPosition() rl.Vector2 {
+	if len(touchPoints) > 0 {
+		return touchPoints[0].Position
+	}
+	return backend.GetMousePosition()
+}
+
+// IsPointerDown returns true while the left mouse button is down or the
+// screen is touched. Together with IsPointerPressed, IsPointerReleased and
+// GetPointerPosition, this lets widgets work with the mouse and touch alike.
+func IsPointerDown() bool {
+	return backend.IsMouseButtonDown(rl.MouseButtonLeft) || len(touchPoints) > 0
+}
+
+// IsPointerPressed returns true if the left mouse button was pressed or the
+// screen was touched this frame.
+func IsPointerPressed() bool {
+	return backend.IsMouseButtonPressed(rl.MouseButtonLeft) || isTouched(input.TriggerTypePressed)
+}
+
+// IsPointerReleased returns true if the left mouse button was released or the
+// last touch point was lifted this frame.
+func IsPointerReleased() bool {
+	return backend.IsMouseButtonReleased(rl.MouseButtonLeft) || isTouched(input.TriggerTypeReleased)
+}
@@ -2,6 +2,7 @@ package logging
 
 import (
 	"errors"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
@@ -17,16 +18,20 @@ type Log struct {
 	fatalLogger   *log.Logger
 }
 
-// single instance, initialized in Init
-var log_instance Log
+// single instance, initialized in Init. Until then, everything is logged to
+// stderr, so packages can log without Init, e.g. in tests.
+var log_instance = newLog(os.Stderr)
 
-// each time we try to use a logging function, we use this to assert that
-// the logger has already been initialized.
-func assertInstanceExists() {
-	if (Log{}) == log_instance {
-		// this error is fatal, as it does not depend on the user system, only
-		// on the program correctness itself.
-		log.Fatalf("ATTEMPTED TO USE LOGGING BEFORE HAVING CALLED logging.Init()\n(Or log_instance was empty for another reason.)")
+// newLog creates the loggers of all levels, writing to w.
+func newLog(w io.Writer) Log {
+	// note: ERRO and FATL sounds dumb, but having the same length for every prefix
+	// improves readability
+	return Log{
+		debugLogger:   log.New(w, "DEBG: ", log.Ldate|log.Ltime),
+		infoLogger:    log.New(w, "INFO: ", log.Ldate|log.Ltime),
+		warningLogger: log.New(w, "WARN: ", log.Ldate|log.Ltime),
+		errorLogger:   log.New(w, "ERRO: ", log.Ldate|log.Ltime),
+		fatalLogger:   log.New(w, "FATL: ", log.Ldate|log.Ltime),
 	}
 }
 
@@ -72,19 +77,12 @@ func Init(log_path string) {
 		log.Printf("FAILED TO OPEN LOG FILE AT: %s, due to this error: %s", log_path, err)
 	}
 
-	log_instance.debugLogger = log.New(file, "DEBG: ", log.Ldate|log.Ltime)
-	log_instance.infoLogger = log.New(file, "INFO: ", log.Ldate|log.Ltime)
-	log_instance.warningLogger = log.New(file, "WARN: ", log.Ldate|log.Ltime)
-	log_instance.errorLogger = log.New(file, "ERRO: ", log.Ldate|log.Ltime)
-	log_instance.fatalLogger = log.New(file, "FATL: ", log.Ldate|log.Ltime)
-	// note: ERRO and FATL sounds dumb, but having the same length for every prefix
-	// improves readability
+	log_instance = newLog(file)
 }
 
 // Info writes to the log-file specified in the settings,
 // using the 'DEBG:' specifier.
 func Debug(format string, v ...any) {
-	assertInstanceExists()
 	caller := callerInfo()
 	log_instance.debugLogger.Printf("%s: "+format, append([]interface{}{caller}, v...)...)
 }
@@ -92,7 +90,6 @@ func Debug(format string, v ...any) {
 // Info writes to the log-file specified in the settings,
 // using the 'INFO:' specifier.
 func Info(format string, v ...any) {
-	assertInstanceExists()
 	caller := callerInfo()
 	log_instance.infoLogger.Printf("%s: "+format, append([]interface{}{caller}, v...)...)
 }
@@ -100,7 +97,6 @@ func Info(format string, v ...any) {
 // Warning writes to the log-file specified in the settings,
 // using the 'WARN:' specifier.
 func Warning(format string, v ...any) {
-	assertInstanceExists()
 	caller := callerInfo()
 	log_instance.warningLogger.Printf("%s: "+format, append([]interface{}{caller}, v...)...)
 }
@@ -108,7 +104,6 @@ func Warning(format string, v ...any) {
 // Error writes to the log-file specified in the settings,
 // using the 'ERRO:' specifier.
 func Error(format string, v ...any) {
-	assertInstanceExists()
 	caller := callerInfo()
 	log_instance.errorLogger.Printf("%s: "+format, append([]interface{}{caller}, v...)...)
 }
@@ -116,7 +111,6 @@ func Error(format string, v ...any) {
 // Fatal writes to the log-file specified in the settings,
 // using the 'FATL:' specifier, and calls os.Exit(1) afterwards.
 func Fatal(format string, v ...any) {
-	assertInstanceExists()
 	caller := callerInfo()
 	log_instance.fatalLogger.Printf("%s: "+format, append([]interface{}{caller}, v...)...)
 	os.Exit(1)
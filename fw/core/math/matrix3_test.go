@@ -59,3 +59,17 @@ func TestFromTransformations(t *testing.T) {
 		t.Errorf("Generated matrix does not match expected matrix. Got %v, want %v", transformMatrix, expectedMatrix)
 	}
 }
+
+// TestInverse tests that a matrix multiplied with its inverse is the identity
+func TestInverse(t *testing.T) {
+	m := FromTransformations(rl.Vector2{X: 5, Y: -3}, 30, rl.Vector2{X: 2, Y: 0.5})
+
+	if !almostEqualMatrix3(m.Multiply(m.Inverse()), Matrix3Identity(), 0.001) {
+		t.Errorf("Matrix times inverse is not the identity, got %v", m.Multiply(m.Inverse()))
+	}
+
+	singular := Matrix3Scale(rl.Vector2{X: 0, Y: 1})
+	if !almostEqualMatrix3(singular.Inverse(), Matrix3Identity(), 0.001) {
+		t.Errorf("Inverse of a singular matrix should be the identity, got %v", singular.Inverse())
+	}
+}
@@ -26,6 +26,10 @@ type Transform2D struct {
 	// or Scale fields are changed.
 	matrix Matrix3
 	dirty  bool
+
+	// onChange is called whenever the Position, Rotation, or Scale fields are
+	// changed. It is used by the Gem to invalidate cached world transforms.
+	onChange func()
 }
 
 // ============================================================================
@@ -116,19 +120,19 @@ func (t *Transform2D) GenerateMatrix() Matrix3 {
 // SetPosition sets the Position field of the Transform2D struct.
 func (t *Transform2D) SetPosition(new_position rl.Vector2) {
 	t.position = new_position
-	t.dirty = true
+	t.changed()
 }
 
 // SetScale sets the Scale field of the Transform2D struct.
 func (t *Transform2D) SetScale(new_scale rl.Vector2) {
 	t.scale = new_scale
-	t.dirty = true
+	t.changed()
 }
 
 // SetRotation sets the Rotation field of the Transform2D struct.
 func (t *Transform2D) SetRotation(new_rotation float32) {
 	t.rotation = new_rotation
-	t.dirty = true
+	t.changed()
 }
 
 // GetPosition returns the Position field of the Transform2D struct.
@@ -149,17 +153,42 @@ func (t *Transform2D) GetRotation() float32 {
 // AddPosition adds a vector to the Position field of the Transform2D struct.
 func (t *Transform2D) AddPosition(offset rl.Vector2) {
 	t.position = rl.Vector2Add(t.position, offset)
-	t.dirty = true
+	t.changed()
 }
 
 // AddScale adds a vector to the Scale field of the Transform2D struct.
 func (t *Transform2D) AddScale(offset rl.Vector2) {
 	t.scale = rl.Vector2Add(t.scale, offset)
-	t.dirty = true
+	t.changed()
 }
 
 // AddRotation adds a value to the Rotation field of the Transform2D struct.
 func (t *Transform2D) AddRotation(offset float32) {
 	t.rotation += offset
+	t.changed()
+}
+
+// Set overwrites the Position, Rotation, and Scale fields with the ones of the
+// given Transform2D. Unlike a plain assignment, this keeps the change hook set
+// by SetOnChange.
+func (t *Transform2D) Set(other Transform2D) {
+	onChange := t.onChange
+	*t = other
+	t.onChange = onChange
+	t.changed()
+}
+
+// SetOnChange sets a function that is called whenever the Position, Rotation,
+// or Scale fields are changed. Pass nil to remove it.
+// This is used by the framework and should generally not be called directly.
+func (t *Transform2D) SetOnChange(onChange func()) {
+	t.onChange = onChange
+}
+
+// changed marks the cached matrix as outdated and calls the change hook.
+func (t *Transform2D) changed() {
 	t.dirty = true
+	if t.onChange != nil {
+		t.onChange()
+	}
 }
@@ -3,6 +3,8 @@ package math
 import (
 	"math"
 	"math/rand"
+	"sync"
+	"time"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
@@ -12,18 +14,47 @@ import (
 // beyond wrapping the stdlib as a generic.
 // ============================================================================
 
+// rng is the random number generator behind RandRange and RandRangeInt.
+// It is seeded randomly at startup, SetSeed makes the sequence of random
+// numbers reproducible, e.g. for input replays.
+var (
+	rngMutex sync.Mutex
+	rngSeed  = time.Now().UnixNano()
+	rng      = rand.New(rand.NewSource(rngSeed))
+)
+
+// SetSeed reseeds the random number generator. The same seed produces the
+// same sequence of random numbers.
+func SetSeed(seed int64) {
+	rngMutex.Lock()
+	defer rngMutex.Unlock()
+	rngSeed = seed
+	rng = rand.New(rand.NewSource(seed))
+}
+
+// GetSeed returns the seed the random number generator was last seeded with.
+func GetSeed() int64 {
+	rngMutex.Lock()
+	defer rngMutex.Unlock()
+	return rngSeed
+}
+
 // RandRange returns a random float32 between min and max.
 // The returned value is in the range [min, max).
 // e.g. min is inclusive, max is exclusive.
 func RandRange(min, max float32) float32 {
-	return min + rand.Float32()*(max-min)
+	rngMutex.Lock()
+	defer rngMutex.Unlock()
+	return min + rng.Float32()*(max-min)
 }
 
 // RandRangeInt returns a random int between min and max.
 // The returned value is in the range [min, max).
 // e.g. min is inclusive, max is exclusive.
 func RandRangeInt(min, max int) int {
-	return min + rand.Intn(max-min)
+	rngMutex.Lock()
+	defer rngMutex.Unlock()
+	return min + rng.Intn(max-min)
 }
 
 // ShortestLerp returns the shortest linear interpolation between two numbers.
@@ -0,0 +1,20 @@
+package math
+
+import "testing"
+
+func TestSetSeed(t *testing.T) {
+	SetSeed(42)
+	first := []float32{RandRange(0, 1), RandRange(-5, 5), float32(RandRangeInt(0, 100))}
+	SetSeed(42)
+	second := []float32{RandRange(0, 1), RandRange(-5, 5), float32(RandRangeInt(0, 100))}
+
+	if GetSeed() != 42 {
+		t.Errorf("expected seed 42, got %v", GetSeed())
+	}
+	for i := range first {
+		if first[i] != second[i] {
+			t.Errorf("expected the same sequence for the same seed, got %v and %v", first, second)
+			break
+		}
+	}
+}
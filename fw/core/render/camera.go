@@ -11,6 +11,7 @@ import (
 type renderTarget struct {
 	DisplayPosition rl.Vector2
 	DisplaySize     rl.Vector2
+	renderSize      rl.Vector2
 	renderTexture   rl.RenderTexture2D
 }
 
@@ -35,11 +36,15 @@ type Camera struct {
 func NewCamera(camTarget, camOffset, renderSize, displaySize, displayPosition rl.Vector2, drawFlags math.BitFlag) *Camera {
 	rlCamera := rl.NewCamera2D(camOffset, camTarget, 0, 1)
 	camera := &Camera{
-		rlcamera:      &rlCamera,
-		renderTarget:  &renderTarget{displayPosition, displaySize, rl.LoadRenderTexture(int32(renderSize.X), int32(renderSize.Y))},
-		drawFlags:     drawFlags,
-		shaders:       make([]*rl.Shader, 0),
-		bounceTexture: rl.LoadRenderTexture(int32(renderSize.X), int32(renderSize.Y)),
+		rlcamera:     &rlCamera,
+		renderTarget: &renderTarget{DisplayPosition: displayPosition, DisplaySize: displaySize, renderSize: renderSize},
+		drawFlags:    drawFlags,
+		shaders:      make([]*rl.Shader, 0),
+	}
+	// in headless mode there is no GPU to hold the textures.
+	if !rendererInstance.headless {
+		camera.renderTarget.renderTexture = rl.LoadRenderTexture(int32(renderSize.X), int32(renderSize.Y))
+		camera.bounceTexture = rl.LoadRenderTexture(int32(renderSize.X), int32(renderSize.Y))
 	}
 	rendererInstance.cameras = append(rendererInstance.cameras, camera)
 	return camera
@@ -53,17 +58,65 @@ func (c *Camera) Destroy() {
 			break
 		}
 	}
-	rl.UnloadRenderTexture(c.renderTarget.renderTexture)
+	if !rendererInstance.headless {
+		rl.UnloadRenderTexture(c.renderTarget.renderTexture)
+	}
 }
 
 // ScreenToWorld converts a screen position to a world position.
+// The render target of the camera is displayed at DisplayPosition with
+// DisplaySize, so the screen position is mapped into the render target first.
+// This keeps the conversion correct for split-screen cameras and cameras that
+// render at a different resolution than they are displayed at.
 func (c *Camera) ScreenToWorld(screenPos rl.Vector2) rl.Vector2 {
-	return rl.GetScreenToWorld2D(screenPos, *c.rlcamera)
+	return rl.GetScreenToWorld2D(c.screenToRender(screenPos), *c.rlcamera)
 }
 
 // WorldToScreen converts a world position to a screen position.
+// See ScreenToWorld.
 func (c *Camera) WorldToScreen(worldPos rl.Vector2) rl.Vector2 {
-	return rl.GetWorldToScreen2D(worldPos, *c.rlcamera)
+	return c.renderToScreen(rl.GetWorldToScreen2D(worldPos, *c.rlcamera))
+}
+
+// ContainsScreenPoint returns true if the screen position is inside the area
+// of the screen the camera is displayed in.
+func (c *Camera) ContainsScreenPoint(screenPos rl.Vector2) bool {
+	return rl.CheckCollisionPointRec(screenPos, c.GetDisplayRect())
+}
+
+// GetDisplayRect returns the area of the screen the camera is displayed in.
+func (c *Camera) GetDisplayRect() rl.Rectangle {
+	t := c.renderTarget
+	return rl.NewRectangle(t.DisplayPosition.X, t.DisplayPosition.Y, t.DisplaySize.X, t.DisplaySize.Y)
+}
+
+// displayScale returns the size of a render target pixel on the screen. The
+// render margin is cut off when the target is displayed, see Draw.
+func (c *Camera) displayScale() rl.Vector2 {
+	t := c.renderTarget
+	margin := float32(c.renderMargin * 2)
+	if t.DisplaySize.X == 0 || t.DisplaySize.Y == 0 || t.renderSize.X <= margin || t.renderSize.Y <= margin {
+		return rl.Vector2One()
+	}
+	return rl.NewVector2(t.DisplaySize.X/(t.renderSize.X-margin), t.DisplaySize.Y/(t.renderSize.Y-margin))
+}
+
+// screenToRender converts a screen position to a position in the render
+// target of the camera.
+func (c *Camera) screenToRender(screenPos rl.Vector2) rl.Vector2 {
+	scale := c.displayScale()
+	local := rl.Vector2Subtract(screenPos, c.renderTarget.DisplayPosition)
+	margin := float32(c.renderMargin)
+	return rl.NewVector2(local.X/scale.X+margin, local.Y/scale.Y+margin)
+}
+
+// renderToScreen converts a position in the render target of the camera to a
+// screen position.
+func (c *Camera) renderToScreen(renderPos rl.Vector2) rl.Vector2 {
+	scale := c.displayScale()
+	margin := float32(c.renderMargin)
+	local := rl.NewVector2((renderPos.X-margin)*scale.X, (renderPos.Y-margin)*scale.Y)
+	return rl.Vector2Add(local, c.renderTarget.DisplayPosition)
 }
 
 // SetTarget sets the target (position) of the camera.
@@ -145,10 +198,9 @@ func (c *Camera) SetRenderMargin(margin int32) {
 // position/target, offset, render size and zoom.
 // Rotation is not taken into account.
 func (c *Camera) GetViewRect() rl.Rectangle {
-	// Get the texture width and height from the render target
-	rTex := c.renderTarget.renderTexture.Texture
-	renderWidth := float32(rTex.Width)
-	renderHeight := float32(rTex.Height)
+	// Get the width and height of the render target
+	renderWidth := c.renderTarget.renderSize.X
+	renderHeight := c.renderTarget.renderSize.Y
 
 	// Calculate the view width and height based on the zoom
 	viewWidth := renderWidth / c.rlcamera.Zoom
@@ -0,0 +1,38 @@
+package render
+
+import (
+	"gorl/fw/core/math"
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+func TestScreenToWorld(t *testing.T) {
+	InitHeadless()
+	defer Deinit()
+
+	// a camera rendering at 160x90, displayed at 320x180 in the bottom right
+	// quarter of a 640x360 screen, centered on (100, 50) with a zoom of 2
+	camera := NewCamera(rl.NewVector2(100, 50), rl.NewVector2(80, 45), rl.NewVector2(160, 90), rl.NewVector2(320, 180), rl.NewVector2(320, 180), math.Flag0)
+	defer camera.Destroy()
+	camera.SetZoom(2)
+
+	near := func(a, b rl.Vector2) bool { return rl.Vector2Distance(a, b) < 0.01 }
+
+	// the center of the display shows the target
+	center := rl.NewVector2(480, 270)
+	if got := camera.ScreenToWorld(center); !near(got, rl.NewVector2(100, 50)) {
+		t.Errorf("expected the display center to show the target, got %v", got)
+	}
+	// a screen pixel is half a render pixel, a render pixel half a world unit
+	if got := camera.ScreenToWorld(rl.NewVector2(320, 180)); !near(got, rl.NewVector2(60, 27.5)) {
+		t.Errorf("expected the display corner at world (60, 27.5), got %v", got)
+	}
+	if got := camera.WorldToScreen(rl.NewVector2(110, 55)); !near(got, rl.NewVector2(520, 290)) {
+		t.Errorf("expected world (110, 55) at screen (520, 290), got %v", got)
+	}
+
+	if !camera.ContainsScreenPoint(center) || camera.ContainsScreenPoint(rl.NewVector2(100, 100)) {
+		t.Errorf("unexpected display area %v", camera.GetDisplayRect())
+	}
+}
@@ -23,6 +23,10 @@ type Drawable interface {
 type renderer struct {
 	cameras     []*Camera
 	finalTarget rl.RenderTexture2D
+
+	// in headless mode no window or GPU is used. cameras can be created and
+	// moved as usual, but nothing is drawn.
+	headless bool
 }
 
 // Init initializes the renderer with the given screen size.
@@ -36,13 +40,34 @@ func Init(screenSize rl.Vector2) {
 	}
 }
 
+// InitHeadless initializes the renderer without a window or GPU, for example
+// to run game logic on a server or in CI. Cameras work as usual, but Draw does
+// not draw anything.
+func InitHeadless() {
+	rendererInstance = renderer{
+		cameras:  []*Camera{},
+		headless: true,
+	}
+}
+
+// IsHeadless returns true if the renderer runs without a window or GPU.
+func IsHeadless() bool {
+	return rendererInstance.headless
+}
+
 // Deinit deinitializes the renderer.
 func Deinit() {
+	if rendererInstance.headless {
+		return
+	}
 	rl.UnloadRenderTexture(rendererInstance.finalTarget)
 }
 
 // SetScreenSize changes the size of the screen.
 func SetScreenSize(screenSize rl.Vector2) {
+	if rendererInstance.headless {
+		return
+	}
 	rl.UnloadRenderTexture(rendererInstance.finalTarget)
 	rendererInstance.finalTarget = rl.LoadRenderTexture(
 		int32(screenSize.X),
@@ -54,16 +79,32 @@ func SetScreenSize(screenSize rl.Vector2) {
 var rendererInstance renderer
 
 // Draw draws the given drawable to the screen, using all cameras.
-// Returns the sorted list of drawables, back to front.
-func Draw(drawables []Drawable) []input.InputReceiver {
+// Returns the drawables as input receivers together with the camera that drew
+// them, back to front, see input.HandleInputEvents. A drawable drawn by
+// several cameras is returned once per camera.
+//
+// In headless mode nothing is drawn, but the receivers are returned as usual,
+// so pointer input can be tested without a window.
+func Draw(drawables []Drawable) []input.DrawnReceiver {
 
-	inputReceivers := []input.InputReceiver{}
+	drawn := []input.DrawnReceiver{}
 
 	// sort the drawables by draw index
 	slices.SortStableFunc(drawables, func(l, r Drawable) int {
 		return int(l.GetDrawIndex() - r.GetDrawIndex())
 	})
 
+	if rendererInstance.headless {
+		for _, camera := range rendererInstance.cameras {
+			for _, drawable := range drawables {
+				if drawable.ShouldDraw(camera.drawFlags) {
+					drawn = append(drawn, input.DrawnReceiver{Receiver: drawable.AsInputReceiver(), Viewport: camera})
+				}
+			}
+		}
+		return drawn
+	}
+
 	for _, camera := range rendererInstance.cameras {
 		rl.BeginTextureMode(camera.renderTarget.renderTexture)
 		rl.BeginMode2D(*camera.rlcamera)
@@ -72,7 +113,7 @@ func Draw(drawables []Drawable) []input.InputReceiver {
 		// Draw all drawables that should be drawn by this camera.
 		for _, drawable := range drawables {
 			if drawable.ShouldDraw(camera.drawFlags) {
-				inputReceivers = append(inputReceivers, drawable.AsInputReceiver())
+				drawn = append(drawn, input.DrawnReceiver{Receiver: drawable.AsInputReceiver(), Viewport: camera})
 				drawable.Draw()
 			}
 		}
@@ -135,7 +176,13 @@ func Draw(drawables []Drawable) []input.InputReceiver {
 		0, rl.White,
 	)
 
-	return inputReceivers
+	// effects covering the whole screen, e.g. scene transitions
+	drawScreenEffects(rl.NewVector2(
+		float32(rendererInstance.finalTarget.Texture.Width),
+		float32(rendererInstance.finalTarget.Texture.Height),
+	))
+
+	return drawn
 }
 
 // ApplyShaders applies the shaders of the camera to the cameras render target.
@@ -0,0 +1,39 @@
+package render
+
+import (
+	"slices"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// ScreenEffect is drawn over the whole screen after all cameras, e.g. the fade
+// of a scene transition.
+type ScreenEffect interface {
+	// DrawScreenEffect draws the effect in screen coordinates.
+	DrawScreenEffect(screenSize rl.Vector2)
+}
+
+// screenEffects are drawn in the order they were added.
+var screenEffects []ScreenEffect
+
+// AddScreenEffect adds an effect that is drawn over the screen every frame,
+// until it is removed again.
+func AddScreenEffect(effect ScreenEffect) {
+	if !slices.Contains(screenEffects, effect) {
+		screenEffects = append(screenEffects, effect)
+	}
+}
+
+// RemoveScreenEffect removes an effect added with AddScreenEffect.
+func RemoveScreenEffect(effect ScreenEffect) {
+	if index := slices.Index(screenEffects, effect); index >= 0 {
+		screenEffects = slices.Delete(screenEffects, index, index+1)
+	}
+}
+
+// drawScreenEffects draws all screen effects.
+func drawScreenEffects(screenSize rl.Vector2) {
+	for _, effect := range screenEffects {
+		effect.DrawScreenEffect(screenSize)
+	}
+}
@@ -0,0 +1,335 @@
+package resources
+
+import (
+	"fmt"
+	"gorl/fw/core/logging"
+	"strings"
+	"sync"
+)
+
+// Scope owns the resources that were created while it was the current scope,
+// e.g. the textures, sounds and colliders of a scene. Closing the scope
+// releases all of them, so nothing is left behind when the scene is disabled.
+//
+// Scopes are nested: closing a scope closes the scopes opened below it first.
+// Resources that are created while no scope is current belong to the global
+// scope, which is never closed.
+//
+// Example:
+//
+//	scope := resources.NewScope("level", nil)
+//	previous := resources.SetCurrent(scope)
+//	texture, _ := assets.LoadTexture("sprites/level.png") // tracked by scope
+//	resources.SetCurrent(previous)
+//	...
+//	scope.Close() // unloads the texture
+//
+// The Gem graph and the scene manager manage scopes on their own, see
+// gem.NewResourceScope, so scopes rarely have to be handled directly.
+type Scope struct {
+	name     string
+	parent   *Scope
+	children []*Scope
+	// resources holds the resources of the scope in the order they were
+	// created.
+	resources []*resource
+	closed    bool
+}
+
+// Resource describes a tracked resource, e.g. for a leak report.
+type Resource struct {
+	// Kind is the kind of the resource, e.g. "texture" or "collider".
+	Kind string
+	// Name is the name or path of the resource, if it has one.
+	Name string
+}
+
+// String returns the kind of the resource, followed by its name.
+func (r Resource) String() string {
+	if r.Name == "" {
+		return r.Kind
+	}
+	return fmt.Sprintf("%v \"%v\"", r.Kind, r.Name)
+}
+
+// resource is a resource tracked by a scope.
+type resource struct {
+	Resource
+	key     any
+	release func()
+	scope   *Scope
+}
+
+var (
+	// mutex guards the scopes and the tracked resources.
+	mutex   sync.Mutex
+	global  = &Scope{name: "global"}
+	tracked = map[any]*resource{}
+
+	// current is the scope new resources are tracked by, nil for the global
+	// scope. It is only changed on the main thread.
+	current *Scope
+)
+
+// Global returns the global scope, which owns the resources that are created
+// while no other scope is current. It is never closed.
+func Global() *Scope {
+	return global
+}
+
+// NewScope opens a new scope with the given name, below the parent scope. If
+// parent is nil, the scope is opened below the global scope.
+func NewScope(name string, parent *Scope) *Scope {
+	if parent == nil {
+		parent = global
+	}
+	scope := &Scope{name: name, parent: parent}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if parent.closed {
+		logging.Warning("Scope \"%v\" was opened below scope \"%v\", which is already closed.", name, parent.name)
+		scope.parent = global
+	}
+	scope.parent.children = append(scope.parent.children, scope)
+	return scope
+}
+
+// Current returns the scope new resources are tracked by.
+func Current() *Scope {
+	if current == nil {
+		return global
+	}
+	return current
+}
+
+// SetCurrent makes the scope the current scope, so the resources created from
+// now on are tracked by it, and returns the previous current scope to restore
+// it later. A nil scope makes the global scope current.
+//
+// SetCurrent must be called from the main thread. The Gem graph sets the
+// scope of each entity while it is updated.
+func SetCurrent(scope *Scope) *Scope {
+	previous := Current()
+	current = scope
+	return previous
+}
+
+// Track tracks a resource by the current scope, see Scope.Track.
+func Track(kind, name string, key any, release func()) {
+	Current().Track(kind, name, key, release)
+}
+
+// Track tracks a resource by the scope. release is called to release the
+// resource when the scope is closed. The key identifies the resource, so it
+// can be untracked with Forget once it is released by other means, e.g. the
+// texture ID or a pointer to the collider.
+//
+// If the scope is already closed, the resource outlives its scope: this is
+// logged as a leak, and the resource is tracked by the global scope instead.
+func (s *Scope) Track(kind, name string, key any, release func()) {
+	r := &resource{Resource: Resource{Kind: kind, Name: name}, key: key, release: release, scope: s}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if s.closed {
+		logging.Warning("Leak: %v was created in scope \"%v\" after it was closed, it now belongs to the global scope.", r, s.name)
+		r.scope = global
+	}
+	if previous, ok := tracked[key]; ok {
+		previous.scope.removeResource(previous)
+	}
+	r.scope.resources = append(r.scope.resources, r)
+	tracked[key] = r
+}
+
+// Forget untracks the resource with the given key without releasing it, e.g.
+// because it was unloaded by hand. Returns false if the resource was not
+// tracked.
+func Forget(key any) bool {
+	mutex.Lock()
+	defer mutex.Unlock()
+	r, ok := tracked[key]
+	if !ok {
+		return false
+	}
+	delete(tracked, key)
+	r.scope.removeResource(r)
+	return true
+}
+
+// Release releases the resource with the given key right away, and untracks
+// it. Returns false if the resource was not tracked.
+func Release(key any) bool {
+	mutex.Lock()
+	r, ok := tracked[key]
+	if ok {
+		delete(tracked, key)
+		r.scope.removeResource(r)
+	}
+	mutex.Unlock()
+
+	if ok {
+		r.release()
+	}
+	return ok
+}
+
+// IsTracked returns true if a resource with the given key is tracked.
+func IsTracked(key any) bool {
+	mutex.Lock()
+	defer mutex.Unlock()
+	_, ok := tracked[key]
+	return ok
+}
+
+// removeResource removes the resource from the scope. The mutex must be held.
+func (s *Scope) removeResource(r *resource) {
+	for i, other := range s.resources {
+		if other == r {
+			s.resources = append(s.resources[:i:i], s.resources[i+1:]...)
+			return
+		}
+	}
+}
+
+// Name returns the name of the scope.
+func (s *Scope) Name() string {
+	return s.name
+}
+
+// Parent returns the scope the scope was opened below, or nil for the global
+// scope.
+func (s *Scope) Parent() *Scope {
+	return s.parent
+}
+
+// IsClosed returns true once the scope is closed.
+func (s *Scope) IsClosed() bool {
+	mutex.Lock()
+	defer mutex.Unlock()
+	return s.closed
+}
+
+// Resources returns the resources the scope currently tracks, in the order
+// they were created. The resources of the scopes below it are not included.
+func (s *Scope) Resources() []Resource {
+	mutex.Lock()
+	defer mutex.Unlock()
+	list := make([]Resource, len(s.resources))
+	for i, r := range s.resources {
+		list[i] = r.Resource
+	}
+	return list
+}
+
+// Keys returns the keys of the resources of the given kind that are tracked
+// by the scope and the scopes below it, e.g. to find the colliders of a scene.
+func (s *Scope) Keys(kind string) []any {
+	mutex.Lock()
+	defer mutex.Unlock()
+	return s.keys(kind, nil)
+}
+
+// keys appends the keys of the resources of the kind to list. The mutex must
+// be held.
+func (s *Scope) keys(kind string, list []any) []any {
+	for _, r := range s.resources {
+		if r.Kind == kind {
+			list = append(list, r.key)
+		}
+	}
+	for _, child := range s.children {
+		list = child.keys(kind, list)
+	}
+	return list
+}
+
+// Close closes the scopes below the scope, then releases the resources of the
+// scope in the reverse order they were created. Resources created by the
+// scope afterwards are reported as leaks. Closing a scope twice does nothing,
+// the global scope can't be closed.
+func (s *Scope) Close() {
+	if s == global {
+		logging.Warning("The global resource scope can't be closed.")
+		return
+	}
+
+	mutex.Lock()
+	if s.closed {
+		mutex.Unlock()
+		return
+	}
+	s.closed = true
+	children := s.children
+	s.children = nil
+	s.parent.removeChild(s)
+	mutex.Unlock()
+
+	for i := len(children) - 1; i >= 0; i-- {
+		children[i].Close()
+	}
+
+	// releasing a resource may untrack others, e.g. an audio track unloads
+	// its sound, so the list is taken again for every resource.
+	released := 0
+	for {
+		mutex.Lock()
+		if len(s.resources) == 0 {
+			mutex.Unlock()
+			break
+		}
+		r := s.resources[len(s.resources)-1]
+		s.resources = s.resources[:len(s.resources)-1]
+		delete(tracked, r.key)
+		mutex.Unlock()
+
+		r.release()
+		released++
+	}
+	if released > 0 {
+		logging.Debug("Released %v resources of scope \"%v\".", released, s.name)
+	}
+
+	if current == s {
+		current = s.parent
+	}
+}
+
+// removeChild removes the scope from the children of the scope. The mutex
+// must be held.
+func (s *Scope) removeChild(child *Scope) {
+	for i, other := range s.children {
+		if other == child {
+			s.children = append(s.children[:i:i], s.children[i+1:]...)
+			return
+		}
+	}
+}
+
+// ReportLeaks logs a warning for every scope, including the global scope,
+// that still tracks resources, listing them. It is meant to be called at the
+// end of the program, once all modules are deinitialized: everything that is
+// still tracked then was never released. Returns the number of resources.
+func ReportLeaks() int {
+	mutex.Lock()
+	defer mutex.Unlock()
+	return global.reportLeaks()
+}
+
+// reportLeaks reports the resources of the scope and the scopes below it. The
+// mutex must be held.
+func (s *Scope) reportLeaks() int {
+	count := len(s.resources)
+	if count > 0 {
+		names := make([]string, len(s.resources))
+		for i, r := range s.resources {
+			names[i] = r.String()
+		}
+		logging.Warning("Leak: %v resources of scope \"%v\" were never released: %v", count, s.name, strings.Join(names, ", "))
+	}
+	for _, child := range s.children {
+		count += child.reportLeaks()
+	}
+	return count
+}
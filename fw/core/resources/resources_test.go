@@ -0,0 +1,84 @@
+package resources
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScope(t *testing.T) {
+	var released []string
+	track := func(scope *Scope, name string) {
+		scope.Track("test", name, name, func() { released = append(released, name) })
+	}
+
+	level := NewScope("level", nil)
+	enemies := NewScope("enemies", level)
+	track(level, "a")
+	track(enemies, "b")
+	track(level, "c")
+	track(level, "d")
+
+	// resources released by hand are not released again
+	if !Forget("c") || Forget("c") || IsTracked("c") {
+		t.Fatalf("expected c to be forgotten once")
+	}
+	if !Release("d") || !reflect.DeepEqual(released, []string{"d"}) {
+		t.Fatalf("expected d to be released, got %v", released)
+	}
+	if got := level.Resources(); !reflect.DeepEqual(got, []Resource{{Kind: "test", Name: "a"}}) {
+		t.Fatalf("unexpected resources of level: %v", got)
+	}
+
+	// the scopes below are closed first, resources in reverse order
+	released = nil
+	level.Close()
+	if !reflect.DeepEqual(released, []string{"b", "a"}) || !level.IsClosed() || !enemies.IsClosed() {
+		t.Fatalf("unexpected release order: %v", released)
+	}
+	level.Close()
+	if len(released) != 2 {
+		t.Fatalf("closing twice released resources again")
+	}
+}
+
+func TestLeaks(t *testing.T) {
+	scope := NewScope("level", nil)
+	scope.Close()
+
+	// a resource created after its scope closed outlives it
+	previous := SetCurrent(scope)
+	Track("test", "late", "late", func() {})
+	SetCurrent(previous)
+	if got := Global().Resources(); !reflect.DeepEqual(got, []Resource{{Kind: "test", Name: "late"}}) {
+		t.Fatalf("expected the leak to belong to the global scope, got %v", got)
+	}
+
+	open := NewScope("open", nil)
+	open.Track("test", "", "unnamed", func() {})
+	if count := ReportLeaks(); count != 2 {
+		t.Fatalf("expected 2 leaks, got %v", count)
+	}
+	Release("late")
+	open.Close()
+	if count := ReportLeaks(); count != 0 {
+		t.Fatalf("expected no leaks, got %v", count)
+	}
+}
+
+func TestCurrent(t *testing.T) {
+	if Current() != Global() {
+		t.Fatalf("expected the global scope to be current")
+	}
+	scope := NewScope("level", nil)
+	SetCurrent(scope)
+	Track("test", "a", "a", func() {})
+	if len(scope.Resources()) != 1 {
+		t.Fatalf("expected the resource to be tracked by the current scope")
+	}
+
+	// closing the current scope makes its parent current
+	scope.Close()
+	if Current() != Global() {
+		t.Fatalf("expected the global scope to be current after closing")
+	}
+}
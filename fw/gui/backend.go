@@ -180,3 +180,49 @@ func backend_slider(slider Slider) {
 func backend_slider_finalize(slider Slider) {
 	// nothing to do here
 }
+
+func backend_text_field(text_field TextField) {
+	style := parseStyleDef(text_field.style_info)
+
+	color := rl.Black
+	if c, ok := style["color"]; ok && c != nil {
+		color = c.(rl.Color)
+	}
+
+	font := Gbs.fonts["default"]
+	if f, ok := style["font"]; ok && f != nil {
+		font = Gbs.fonts[f.(string)]
+	}
+
+	font_scale := float32(1.0)
+	if v, ok := style["font-scale"]; ok && v != nil {
+		font_scale = v.(float32)
+	}
+
+	background := rl.LightGray
+	if v, ok := style["background"]; ok && v != nil {
+		background = v.(rl.Color)
+	}
+	if text_field.focused {
+		background = rl.RayWhite
+		if v, ok := style["background-focused"]; ok && v != nil {
+			background = v.(rl.Color)
+		}
+	}
+
+	font_size := float32(font.BaseSize) * font_scale
+	spacing := float32(font.BaseSize/10) * font_scale
+
+	rl.DrawRectangleRec(text_field.Bounds(), background)
+	rl.DrawTextEx(font, string(text_field.text), text_field.position, font_size, spacing, color)
+
+	// draw the cursor after the text before it
+	if text_field.focused {
+		offset := rl.MeasureTextEx(font, string(text_field.text[:text_field.cursor]), font_size, spacing).X
+		rl.DrawRectangleRec(rl.NewRectangle(text_field.position.X+offset, text_field.position.Y, font_scale, font_size), color)
+	}
+}
+
+func backend_text_field_finalize(text_field TextField) {
+	// nothing to do here
+}
@@ -1,9 +1,13 @@
 package gui
 
 import (
+	"gorl/fw/core/clock"
+	inputevent "gorl/fw/core/input/input_event"
+	input "gorl/fw/core/input/input_handling"
 	"gorl/fw/core/logging"
 	"gorl/fw/util"
 	"fmt"
+	"strings"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
@@ -179,15 +183,15 @@ const (
 func (button *Button) update_button() {
 	bounds := rl.NewRectangle(button.position.X, button.position.Y, button.size.X, button.size.Y)
 	button.state = ButtonStateNone
-	if rl.CheckCollisionPointRec(rl.GetMousePosition(), bounds) {
-		if rl.IsMouseButtonDown(rl.MouseLeftButton) {
+	if rl.CheckCollisionPointRec(input.GetPointerPosition(), bounds) {
+		if input.IsPointerDown() {
 			// mouse down, button is pressed down
 			button.state = ButtonStatePressed
 		} else {
 			// mouse is hovering, no click occured
 			button.state = ButtonStateHovered
 		}
-		if rl.IsMouseButtonReleased(rl.MouseLeftButton) {
+		if input.IsPointerReleased() {
 			// button released, do the button action
 			button.state = ButtonStateReleased
 		}
@@ -240,7 +244,7 @@ func (scroll_panel *ScrollPanel) update_scroll_panel() {
 		// Compute the new scroll position
 		new_scroll_position := rl.Vector2Add(
 			scroll_panel.state.scroll_position,
-			rl.Vector2Scale(wheel_move, rl.GetFrameTime()*1800), // scroll speed
+			rl.Vector2Scale(wheel_move, clock.UnscaledDeltaTime()*1800), // scroll speed
 		)
 
 		maxXScroll := scroll_panel.visible_bounds.Width - scroll_panel.full_bounds.Width
@@ -336,11 +340,13 @@ func (slider *Slider) update() {
 	)
 
 	// Check for collisions with mouse position
-	slider_collision := rl.CheckCollisionPointRec(rl.GetMousePosition(), slider_bounds)
-	handle_collision := rl.CheckCollisionPointRec(rl.GetMousePosition(), handle_bounds)
+	// the pointer is the mouse, or the finger touching the screen
+	pointer_position := input.GetPointerPosition()
+	slider_collision := rl.CheckCollisionPointRec(pointer_position, slider_bounds)
+	handle_collision := rl.CheckCollisionPointRec(pointer_position, handle_bounds)
 
-	is_mouse_down := rl.IsMouseButtonDown(rl.MouseLeftButton)
-	mouse_x := float32(rl.GetMouseX())
+	is_mouse_down := input.IsPointerDown()
+	mouse_x := pointer_position.X
 
 	// Clicked on the slider but not on the handle
 	if slider_collision && !handle_collision && is_mouse_down {
@@ -410,6 +416,151 @@ func (slider *Slider) SetCurrentValue(new_value float32) {
 	slider.current_value = new_value
 }
 
+// ----------------
+//   TEXT FIELD   |
+// ----------------
+
+// widget definiton
+type TextField struct {
+	BaseWidget
+	text             []rune
+	cursor           int
+	max_length       int
+	focused          bool
+	changed_callback func(text string)
+	submit_callback  func(text string)
+	style_info       string
+}
+
+// update function
+func (text_field *TextField) update_text_field() {
+	if !input.IsPointerPressed() {
+		return
+	}
+	// clicking the field focuses it, clicking anywhere else unfocuses it
+	if rl.CheckCollisionPointRec(input.GetPointerPosition(), text_field.Bounds()) {
+		text_field.Focus()
+	} else {
+		inputevent.ReleaseTextFocus(text_field)
+	}
+}
+
+// constructor
+func NewTextField(text string, position, size rl.Vector2, style_info string) *TextField {
+	new_text_field := &TextField{text: []rune(text), style_info: style_info}
+	new_text_field.cursor = len(new_text_field.text)
+	new_text_field.size = size
+	new_text_field.position = position
+	return new_text_field
+}
+
+// OnTextInput implements inputevent.TextReceiver.
+func (text_field *TextField) OnTextInput(event *inputevent.TextEvent) {
+	switch event.Type {
+	case inputevent.TextEventChar:
+		text_field.insert(string(event.Char))
+	case inputevent.TextEventPaste:
+		// a text field has a single line
+		text_field.insert(strings.NewReplacer("\r", "", "\n", " ").Replace(event.Text))
+	case inputevent.TextEventKey:
+		switch event.Key {
+		case rl.KeyBackspace:
+			if text_field.cursor > 0 {
+				text_field.text = append(text_field.text[:text_field.cursor-1], text_field.text[text_field.cursor:]...)
+				text_field.cursor--
+				text_field.changed()
+			}
+		case rl.KeyDelete:
+			if text_field.cursor < len(text_field.text) {
+				text_field.text = append(text_field.text[:text_field.cursor], text_field.text[text_field.cursor+1:]...)
+				text_field.changed()
+			}
+		case rl.KeyLeft:
+			text_field.cursor = max(text_field.cursor-1, 0)
+		case rl.KeyRight:
+			text_field.cursor = min(text_field.cursor+1, len(text_field.text))
+		case rl.KeyHome:
+			text_field.cursor = 0
+		case rl.KeyEnd:
+			text_field.cursor = len(text_field.text)
+		case rl.KeyEnter, rl.KeyKpEnter:
+			if text_field.submit_callback != nil {
+				text_field.submit_callback(text_field.GetText())
+			}
+		case rl.KeyEscape:
+			inputevent.ReleaseTextFocus(text_field)
+		case rl.KeyC:
+			// there is no selection, so copy and cut use the whole text
+			input.SetClipboardText(text_field.GetText())
+		case rl.KeyX:
+			input.SetClipboardText(text_field.GetText())
+			text_field.SetText("")
+		}
+	}
+}
+
+// OnTextFocusChanged implements inputevent.TextFocusListener.
+func (text_field *TextField) OnTextFocusChanged(focused bool) {
+	text_field.focused = focused
+}
+
+// insert inserts the text at the cursor, up to the maximum length.
+func (text_field *TextField) insert(text string) {
+	runes := []rune(text)
+	if text_field.max_length > 0 {
+		runes = runes[:max(min(len(runes), text_field.max_length-len(text_field.text)), 0)]
+	}
+	if len(runes) == 0 {
+		return
+	}
+	tail := append(runes, text_field.text[text_field.cursor:]...)
+	text_field.text = append(text_field.text[:text_field.cursor], tail...)
+	text_field.cursor += len(runes)
+	text_field.changed()
+}
+
+func (text_field *TextField) changed() {
+	if text_field.changed_callback != nil {
+		text_field.changed_callback(text_field.GetText())
+	}
+}
+
+// Focus gives the text focus to the text field, so it receives typed text.
+func (text_field *TextField) Focus() {
+	inputevent.SetTextFocus(text_field)
+}
+
+// IsFocused returns true if the text field has the text focus.
+func (text_field *TextField) IsFocused() bool {
+	return text_field.focused
+}
+
+func (text_field *TextField) GetText() string {
+	return string(text_field.text)
+}
+
+// SetText replaces the text and moves the cursor to its end.
+func (text_field *TextField) SetText(new_text string) {
+	text_field.text = []rune(new_text)
+	text_field.cursor = len(text_field.text)
+	text_field.changed()
+}
+
+// SetMaxLength limits the number of characters of the text. 0 means no limit.
+func (text_field *TextField) SetMaxLength(max_length int) {
+	text_field.max_length = max_length
+}
+
+// SetChangedCallback sets a function that is called every time the text changes.
+func (text_field *TextField) SetChangedCallback(callback func(text string)) {
+	text_field.changed_callback = callback
+}
+
+// SetSubmitCallback sets a function that is called when enter is pressed.
+func (text_field *TextField) SetSubmitCallback(callback func(text string)) {
+	text_field.submit_callback = callback
+}
+
 // ----------------
 //       GUI      |
 // ----------------
@@ -460,6 +611,10 @@ func doRecursiveDraw(container Container) {
 			w.update()
 			backend_slider(*w)
 			backend_slider_finalize(*w)
+		case *TextField:
+			w.update_text_field()
+			backend_text_field(*w)
+			backend_text_field_finalize(*w)
 		default:
 			logging.Error("Attempted to draw GUI widget type with missing draw case: %v", w)
 		}
@@ -59,6 +59,8 @@ func parseStyleDef(style_definition string) map[string]any {
 
 	converters["background-pressed"] = converters["color"]
 
+	converters["background-focused"] = converters["color"]
+
 	converters["font"] = func(value string) any {
 		return value // already a string
 	}
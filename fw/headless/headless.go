@@ -0,0 +1,152 @@
+package headless
+
+import (
+	"gorl/fw/core/clock"
+	"gorl/fw/core/gem"
+	input "gorl/fw/core/input/input_handling"
+	"gorl/fw/core/logging"
+	"gorl/fw/core/render"
+	"gorl/fw/core/settings"
+	"gorl/fw/core/store"
+	"gorl/fw/modules/audio"
+	"gorl/fw/modules/event"
+	"gorl/fw/modules/scenes"
+	"gorl/fw/physics"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Config configures a headless run.
+type Config struct {
+	// Frames is the maximum number of frames to run. If 0, the run only ends
+	// when store.AppState.ShouldQuit is set.
+	Frames int
+
+	// FrameTime is the synthetic duration of a frame in seconds.
+	// Defaults to 1/60.
+	FrameTime float32
+
+	// PhysicsTimestep, Gravity and PhysicsScale are passed to
+	// physics.InitPhysics. The timestep defaults to 1/60, the scale to 1/32.
+	PhysicsTimestep float32
+	Gravity         rl.Vector2
+	PhysicsScale    float32
+
+	// Replay is an input recording to replay, see input.StartReplay. The
+	// recorded events are dispatched and the recorded frame times are used
+	// instead of FrameTime. If Frames is 0, the run ends with the replay.
+	Replay *input.Recording
+
+	// Init is called once after the framework is initialized, to set up the
+	// game, e.g. game.Init.
+	Init func()
+
+	// OnFrame is called at the end of every frame with the index of the frame
+	// (starting at 0). Tests can use it to inspect or drive the game.
+	OnFrame func(frame int)
+}
+
+// Result describes a finished headless run.
+type Result struct {
+	// Frames is the number of frames that were run.
+	Frames int
+	// Time is the synthetic time that passed, in seconds.
+	Time float32
+	// PhysicsSteps is the number of physics steps that were taken.
+	PhysicsSteps int
+	// Quit is true if the run ended because store.AppState.ShouldQuit was set.
+	Quit bool
+}
+
+// Run runs the game loop without a window, GPU or audio device: the framework
+// is initialized with headless rendering and audio, and physics and the Gem
+// graph are driven by a fixed synthetic clock instead of the wall clock.
+//
+// Logging must be initialized before calling Run. If no settings are loaded,
+// the fallback settings are used.
+func Run(config Config) Result {
+	if config.FrameTime <= 0 {
+		config.FrameTime = 1.0 / 60.0
+	}
+	if config.PhysicsTimestep <= 0 {
+		config.PhysicsTimestep = 1.0 / 60.0
+	}
+	if config.PhysicsScale <= 0 {
+		config.PhysicsScale = 1.0 / 32.0
+	}
+	if settings.CurrentSettings() == nil {
+		settings.UseFallbackSettings()
+	}
+
+	// INITIALIZATION
+	// time comes from the synthetic clock, not from raylib.
+	clock.Reset()
+	clock.SetSource(clock.FixedSource(config.FrameTime))
+	defer clock.Reset()
+
+	// the replay starts before the game is initialized, so the random numbers
+	// it uses are reproduced as well.
+	if config.Replay != nil {
+		input.StartReplay(config.Replay)
+		defer input.StopReplay()
+		if config.Frames == 0 {
+			config.Frames = len(config.Replay.Frames)
+		}
+	}
+
+	render.InitHeadless()
+	defer render.Deinit()
+	audio.InitAudioHeadless()
+	defer audio.DeinitAudio()
+	physics.InitPhysics(config.PhysicsTimestep, config.Gravity, config.PhysicsScale)
+	defer physics.DeinitPhysics()
+	gem.Init()
+	defer gem.Deinit()
+
+	// start with a fresh app state, a previous run may have requested to quit.
+	store.Add(store.NewAppState())
+
+	if config.Init != nil {
+		config.Init()
+	}
+	logging.Info("Headless run started.")
+
+	// MAIN LOOP
+	result := Result{}
+	for config.Frames == 0 || result.Frames < config.Frames {
+		// advance the synthetic clock, and run as many fixed steps as fit into
+		// the elapsed time.
+		clock.Tick()
+		result.Time += clock.UnscaledDeltaTime()
+		event.DeliverQueued()
+		scenes.Update()
+		for clock.StepFixed() {
+			physics.Step()
+			gem.FixedTraverse()
+			result.PhysicsSteps++
+		}
+
+		drawables, inputReceivers := gem.Traverse(false)
+		drawn := render.Draw(drawables)
+		// there are no devices, only replayed input is dispatched.
+		if input.IsReplaying() {
+			input.HandleInputEvents(inputReceivers, drawn)
+		}
+		gem.Sync()
+		audio.Update()
+
+		if config.OnFrame != nil {
+			config.OnFrame(result.Frames)
+		}
+		result.Frames++
+
+		appState, ok := store.Get[*store.AppState]()
+		if !ok || appState.ShouldQuit {
+			result.Quit = true
+			break
+		}
+	}
+
+	logging.Info("Headless run finished after %v frames.", result.Frames)
+	return result
+}
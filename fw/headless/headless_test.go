@@ -0,0 +1,69 @@
+package headless
+
+import (
+	"gorl/fw/core/entities"
+	"gorl/fw/core/gem"
+	"gorl/fw/core/store"
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+type countingEntity struct {
+	*entities.Entity
+	updates, fixedUpdates int
+}
+
+func (e *countingEntity) Update()      { e.updates++ }
+func (e *countingEntity) FixedUpdate() { e.fixedUpdates++ }
+
+func TestRunFrames(t *testing.T) {
+	entity := &countingEntity{Entity: entities.NewEntity("counter", rl.Vector2Zero(), 0, rl.Vector2One())}
+	result := Run(Config{
+		Frames:          120,
+		FrameTime:       1.0 / 120.0,
+		PhysicsTimestep: 1.0 / 60.0,
+		Init:            func() { gem.Append(gem.GetRoot(), entity) },
+	})
+
+	if result.Frames != 120 || result.Quit {
+		t.Errorf("expected 120 frames without quitting, got %+v", result)
+	}
+	if entity.updates != 120 {
+		t.Errorf("expected 120 updates, got %d", entity.updates)
+	}
+	if result.PhysicsSteps < 59 || result.PhysicsSteps > 60 || entity.fixedUpdates != result.PhysicsSteps {
+		t.Errorf("expected one physics step every second frame, got %d steps and %d fixed updates",
+			result.PhysicsSteps, entity.fixedUpdates)
+	}
+}
+
+func TestRunUntilQuit(t *testing.T) {
+	result := Run(Config{
+		OnFrame: func(frame int) {
+			if frame == 9 {
+				appState, _ := store.Get[*store.AppState]()
+				appState.ShouldQuit = true
+			}
+		},
+	})
+	if result.Frames != 10 || !result.Quit {
+		t.Errorf("expected to quit after 10 frames, got %+v", result)
+	}
+}
+
+func TestRunCatchUp(t *testing.T) {
+	entity := &countingEntity{Entity: entities.NewEntity("counter", rl.Vector2Zero(), 0, rl.Vector2One())}
+	result := Run(Config{
+		Frames:          20,
+		FrameTime:       1.0 / 20.0,
+		PhysicsTimestep: 1.0 / 60.0,
+		Init:            func() { gem.Append(gem.GetRoot(), entity) },
+	})
+
+	// three fixed steps per frame keep physics in sync with a low frame rate.
+	if result.PhysicsSteps < 59 || result.PhysicsSteps > 60 || entity.fixedUpdates != result.PhysicsSteps {
+		t.Errorf("expected about 60 physics steps in 20 frames, got %d steps and %d fixed updates",
+			result.PhysicsSteps, entity.fixedUpdates)
+	}
+}
@@ -2,7 +2,9 @@ package audio
 
 import (
 	"gorl/fw/core/assets"
+	"gorl/fw/core/clock"
 	"gorl/fw/core/logging"
+	"gorl/fw/core/resources"
 	"gorl/fw/util"
 	"math/rand"
 
@@ -38,6 +40,10 @@ type audio struct {
 
 	force_fade_out     bool
 	force_fade_out_len float32 // this is a helper, replacing GetMusicTimeLength with a shorter time.
+
+	// in headless mode no audio device is used. tracks are registered by name
+	// only, and playback calls are accepted but produce no sound.
+	headless bool
 }
 
 var a audio
@@ -47,7 +53,25 @@ func InitAudio() {
 	if !rl.IsAudioDeviceReady() {
 		logging.Fatal("Failed to InitAudioDevice!")
 	}
-	a = audio{
+	a = newAudio()
+}
+
+// InitAudioHeadless initializes the audio module without an audio device, for
+// example to run game logic on a server or in CI. Tracks are not loaded and
+// nothing is played, but the API can be used as usual.
+func InitAudioHeadless() {
+	a = newAudio()
+	a.headless = true
+	logging.Info("Audio initialized in headless mode.")
+}
+
+// IsHeadless returns true if the audio module runs without an audio device.
+func IsHeadless() bool {
+	return a.headless
+}
+
+func newAudio() audio {
+	return audio{
 		is_mute:            false,
 		global_volume:      1.0,
 		music_volume:       1.0,
@@ -68,22 +92,24 @@ func InitAudio() {
 
 func DeinitAudio() {
 	// unload all audio tracks from memory
-	for _, sound := range a.sfx_tracks {
-		rl.UnloadSound(sound)
+	for name := range a.sfx_tracks {
+		UnregisterSound(name)
 	}
-	for _, music := range a.music_tracks {
-		rl.UnloadMusicStream(music)
+	for name := range a.music_tracks {
+		UnregisterMusic(name)
 	}
 
-	rl.CloseAudioDevice()
+	if !a.headless {
+		rl.CloseAudioDevice()
+	}
 }
 
 func Update() {
-	if !a.is_mute {
+	if !a.is_mute && !a.headless {
 
 		// Handle the delay between tracks
 		if a.is_delaying {
-			a.delay_timer += rl.GetFrameTime()
+			a.delay_timer += clock.UnscaledDeltaTime()
 			if a.delay_timer >= a.delay_secs {
 				a.is_delaying = false
 				a.delay_timer = 0.0
@@ -101,7 +127,7 @@ func Update() {
 		// if were currently playing music...
 		if curr_mus, ok := a.music_tracks[a.curr_playing_music_name]; ok {
 			rl.UpdateMusicStream(curr_mus)
-			a.play_timer += rl.GetFrameTime()
+			a.play_timer += clock.UnscaledDeltaTime()
 
 			// get the remaining playtime
 			time_remaining := rl.GetMusicTimeLength(curr_mus) - a.play_timer
@@ -165,6 +191,9 @@ func getNextMusicTrack() string {
 
 func forceFadeOutNow() {
 	a.force_fade_out = true
+	if a.headless {
+		return
+	}
 	a.force_fade_out_len = rl.GetMusicTimePlayed(a.music_tracks[a.curr_playing_music_name]) + a.music_fade_secs
 }
 
@@ -179,12 +208,25 @@ func setWaitingMusic(name string) {
 
 // LOADING TRACKS & PLAYLISTS
 
+// soundKey and musicKey identify the registered tracks in the resource
+// scopes.
+type soundKey string
+type musicKey string
+
 // Load a music file from the given path, register it with the given name.
+// The track is tracked by the current resource scope, and unregistered when
+// the scope is closed, e.g. when the scene that registered it is disabled.
 func RegisterMusic(name, path string) {
 	if _, ok := a.music_tracks[name]; ok {
 		logging.Warning("Tried to register music track for a name that already exists: %v", name)
 		return
 	}
+	// the track is tracked after its stream, so it is released first
+	defer resources.Track("music track", name, musicKey(name), func() { unregisterMusic(name) })
+	if a.headless {
+		a.music_tracks[name] = rl.Music{}
+		return
+	}
 	m, err := assets.LoadMusicStream(path)
 	if err != nil {
 		logging.Error("Failed to load music audio stream: %v", err)
@@ -194,11 +236,19 @@ func RegisterMusic(name, path string) {
 }
 
 // Load a sound file from the given path, register it with the given name.
+// The track is tracked by the current resource scope, and unregistered when
+// the scope is closed, e.g. when the scene that registered it is disabled.
 func RegisterSound(name, path string) {
 	if _, ok := a.sfx_tracks[name]; ok {
 		logging.Warning("Tried to register sfx track for a name that already exists: %v", name)
 		return
 	}
+	// the track is tracked after its sound, so it is released first
+	defer resources.Track("sfx track", name, soundKey(name), func() { unregisterSound(name) })
+	if a.headless {
+		a.sfx_tracks[name] = rl.Sound{}
+		return
+	}
 	s, err := assets.LoadSound(path)
 	if err != nil {
 		logging.Error("Failed to load sound audio stream: %v", err)
@@ -206,6 +256,57 @@ func RegisterSound(name, path string) {
 	a.sfx_tracks[name] = s
 }
 
+// Unload the music track registered with the given name. If it is playing, it
+// is stopped.
+func UnregisterMusic(name string) {
+	if _, ok := a.music_tracks[name]; !ok {
+		logging.Warning("Tried to unregister music track that doesn't exist: %v", name)
+		return
+	}
+	resources.Forget(musicKey(name))
+	unregisterMusic(name)
+}
+
+// Unload the sound track registered with the given name.
+func UnregisterSound(name string) {
+	if _, ok := a.sfx_tracks[name]; !ok {
+		logging.Warning("Tried to unregister sfx track that doesn't exist: %v", name)
+		return
+	}
+	resources.Forget(soundKey(name))
+	unregisterSound(name)
+}
+
+func unregisterMusic(name string) {
+	music, ok := a.music_tracks[name]
+	if !ok {
+		return
+	}
+	delete(a.music_tracks, name)
+	if a.curr_playing_music_name == name {
+		a.curr_playing_music_name = ""
+	}
+	if a.is_music_waiting && a.waiting_music_name == name {
+		a.is_music_waiting = false
+		a.waiting_music_name = ""
+	}
+	if !a.headless {
+		rl.StopMusicStream(music)
+		assets.UnloadMusicStream(music)
+	}
+}
+
+func unregisterSound(name string) {
+	sound, ok := a.sfx_tracks[name]
+	if !ok {
+		return
+	}
+	delete(a.sfx_tracks, name)
+	if !a.headless {
+		assets.UnloadSound(sound)
+	}
+}
+
 func CreatePlaylist(name string, p []string) {
 	if _, ok := a.music_playlists[name]; ok {
 		logging.Warning("Tried to register playlist for a name that already exists: %v", name)
@@ -294,6 +395,9 @@ PlaySound with extended parameters.
 */
 func PlaySoundEx(name string, volume, pitch, pan float32) {
 	if s, ok := a.sfx_tracks[name]; ok {
+		if a.headless {
+			return
+		}
 		rl.SetSoundPitch(s, pitch)
 		rl.SetSoundPan(s, pan)
 		rl.SetSoundVolume(s, a.sfx_volume*a.global_volume*volume)
@@ -316,6 +420,10 @@ func PlayMusicNow(name string) {
 		logging.Warning("Attempted to play music that is not registered: %v", name)
 		return
 	}
+	if a.headless {
+		a.curr_playing_music_name = name
+		return
+	}
 	// stop the currently playing track
 	rl.StopMusicStream(a.music_tracks[a.curr_playing_music_name])
 	// set the correct volume
@@ -0,0 +1,242 @@
+package event
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// ErrStopPropagation can be returned by a handler to stop the event from
+// reaching the remaining handlers. Publish and Trigger do not return it.
+var ErrStopPropagation = errors.New("stop propagation")
+
+// Bus dispatches typed events. The type of an event identifies it, so the
+// handlers of an event are checked at compile time:
+//
+//	type PlayerDied struct{ Score int }
+//
+//	sub := event.Subscribe(func(e PlayerDied) error { ... })
+//	defer sub.Unsubscribe()
+//	err := event.Publish(PlayerDied{Score: 10})
+//
+// Use NewBus to create a bus of your own, otherwise the functions of the
+// package use the default bus.
+//
+// Publish calls the handlers right away, and must be called from the main
+// thread. Background work, e.g. loading or networking, uses Post instead,
+// which queues the event until Deliver is called.
+type Bus struct {
+	sync.RWMutex
+
+	handlers map[reflect.Type][]*Subscription
+	queue    queue
+}
+
+// NewBus returns a new, empty event bus.
+func NewBus() *Bus {
+	return &Bus{handlers: make(map[reflect.Type][]*Subscription)}
+}
+
+// Subscription is a handler subscribed to a bus.
+type Subscription struct {
+	bus       *Bus
+	eventType reflect.Type
+	handler   func(event any) error
+	// name is the name of the handler function, for tracing.
+	name     string
+	priority int
+	once     bool
+	active   bool
+}
+
+// SubscribeOption changes how a handler is subscribed, see WithPriority and
+// Once.
+type SubscribeOption func(s *Subscription)
+
+// WithPriority sets the priority of the handler. Handlers with a higher
+// priority are called first, handlers of the same priority in the order they
+// subscribed. The default priority is 0.
+func WithPriority(priority int) SubscribeOption {
+	return func(s *Subscription) {
+		s.priority = priority
+	}
+}
+
+// Once unsubscribes the handler before it is called the first time.
+func Once() SubscribeOption {
+	return func(s *Subscription) {
+		s.once = true
+	}
+}
+
+// Unsubscribe removes the handler from its bus. It is not called anymore,
+// even if the event is currently being published. Unsubscribing twice does
+// nothing.
+func (s *Subscription) Unsubscribe() {
+	s.bus.Lock()
+	defer s.bus.Unlock()
+	s.bus.remove(s)
+}
+
+// IsActive returns true until the handler is unsubscribed.
+func (s *Subscription) IsActive() bool {
+	s.bus.RLock()
+	defer s.bus.RUnlock()
+	return s.active
+}
+
+// remove removes the subscription from the bus. The bus must be locked.
+func (b *Bus) remove(s *Subscription) {
+	if !s.active {
+		return
+	}
+	s.active = false
+	list := b.handlers[s.eventType]
+	for i, other := range list {
+		if other == s {
+			b.handlers[s.eventType] = append(list[:i:i], list[i+1:]...)
+			break
+		}
+	}
+	if len(b.handlers[s.eventType]) == 0 {
+		delete(b.handlers, s.eventType)
+	}
+}
+
+// subscribe adds the subscription to the bus, sorted by priority.
+func (b *Bus) subscribe(s *Subscription) {
+	b.Lock()
+	defer b.Unlock()
+	s.bus = b
+	s.active = true
+
+	// the sort is stable, so handlers of the same priority keep the order
+	// they subscribed in
+	list := append(b.handlers[s.eventType], s)
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].priority > list[j].priority
+	})
+	b.handlers[s.eventType] = list
+}
+
+// publish calls the handlers of the event type in order. The handlers are
+// called without holding the lock, so they may subscribe, unsubscribe and
+// publish themselves.
+func (b *Bus) publish(eventType reflect.Type, event any) error {
+	b.RLock()
+	list := append([]*Subscription{}, b.handlers[eventType]...)
+	b.RUnlock()
+
+	trace := beginTrace(eventType.String(), []any{event}, len(list))
+	for _, s := range list {
+		b.Lock()
+		active := s.active
+		if active && s.once {
+			b.remove(s)
+		}
+		b.Unlock()
+		if !active {
+			continue
+		}
+
+		trace.called(s.name)
+		if err := s.handler(event); err != nil {
+			if errors.Is(err, ErrStopPropagation) {
+				trace.end(s.name, nil)
+				return nil
+			}
+			trace.end(s.name, err)
+			return err
+		}
+	}
+	trace.end(nil, nil)
+	return nil
+}
+
+// HasSubscribers returns true if a handler is subscribed to the event type.
+func (b *Bus) HasSubscribers(eventType reflect.Type) bool {
+	b.RLock()
+	defer b.RUnlock()
+	return len(b.handlers[eventType]) > 0
+}
+
+// Deliver publishes the events posted since the last call, in the order they
+// were posted and at most as many as the delivery budget allows. The rest stay
+// queued for the next call. Errors of handlers are logged. Returns the number
+// of delivered events.
+//
+// Deliver must be called from the main thread, the game loop calls it once
+// per frame for the default bus, see DeliverQueued.
+func (b *Bus) Deliver() int {
+	return b.queue.deliver()
+}
+
+// SetDeliveryBudget sets the most events a call of Deliver delivers, to
+// spread bursts of posted events over several frames. 0 means no limit, which
+// is the default.
+func (b *Bus) SetDeliveryBudget(budget int) {
+	b.queue.setBudget(budget)
+}
+
+// QueueLength returns the number of posted events that were not delivered
+// yet.
+func (b *Bus) QueueLength() int {
+	return b.queue.length()
+}
+
+// Clear unsubscribes all handlers and drops the queued events.
+func (b *Bus) Clear() {
+	b.Lock()
+	defer b.Unlock()
+	for _, list := range b.handlers {
+		for _, s := range list {
+			s.active = false
+		}
+	}
+	b.handlers = make(map[reflect.Type][]*Subscription)
+	b.queue.clear()
+}
+
+// SubscribeTo subscribes a handler for events of type T to the bus, and
+// returns the subscription, which is used to unsubscribe it again.
+//
+// If the handler returns an error, the remaining handlers are skipped and
+// Publish returns the error, unless it is ErrStopPropagation.
+func SubscribeTo[T any](bus *Bus, handler func(event T) error, options ...SubscribeOption) *Subscription {
+	s := &Subscription{
+		eventType: reflect.TypeFor[T](),
+		handler: func(event any) error {
+			// the assertion fails for nil interfaces, which are the zero value
+			typed, _ := event.(T)
+			return handler(typed)
+		},
+		name: funcName(handler),
+	}
+	for _, option := range options {
+		option(s)
+	}
+	bus.subscribe(s)
+	return s
+}
+
+// PublishTo calls the handlers of events of type T on the bus with the event,
+// in order of their priority. It returns the first error of a handler, other
+// than ErrStopPropagation.
+func PublishTo[T any](bus *Bus, event T) error {
+	return bus.publish(reflect.TypeFor[T](), event)
+}
+
+// PostTo queues the event on the bus, to be published by the next call of
+// Deliver. It is safe to call from any goroutine.
+func PostTo[T any](bus *Bus, event T) {
+	bus.queue.post(func() error {
+		return PublishTo(bus, event)
+	})
+}
+
+// HasSubscribersTo returns true if a handler for events of type T is
+// subscribed to the bus.
+func HasSubscribersTo[T any](bus *Bus) bool {
+	return bus.HasSubscribers(reflect.TypeFor[T]())
+}
@@ -0,0 +1,150 @@
+package event
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+type testEvent struct {
+	value int
+}
+
+func TestBusPriorityAndOnce(t *testing.T) {
+	bus := NewBus()
+	calls := []string{}
+	record := func(name string) func(testEvent) error {
+		return func(testEvent) error {
+			calls = append(calls, name)
+			return nil
+		}
+	}
+
+	SubscribeTo(bus, record("low"), WithPriority(-1))
+	SubscribeTo(bus, record("first"))
+	SubscribeTo(bus, record("high"), WithPriority(10))
+	SubscribeTo(bus, record("second"))
+	once := SubscribeTo(bus, record("once"), Once(), WithPriority(5))
+
+	for i := 0; i < 2; i++ {
+		if err := PublishTo(bus, testEvent{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []string{"high", "once", "first", "second", "low", "high", "first", "second", "low"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("expected the calls %v, got %v", want, calls)
+	}
+	if once.IsActive() {
+		t.Errorf("a one-shot handler must be unsubscribed after its call")
+	}
+}
+
+func TestBusPropagation(t *testing.T) {
+	bus := NewBus()
+	reached := false
+	SubscribeTo(bus, func(e testEvent) error {
+		if e.value == 1 {
+			return ErrStopPropagation
+		}
+		return errors.New("failed")
+	}, WithPriority(1))
+	sub := SubscribeTo(bus, func(testEvent) error {
+		reached = true
+		return nil
+	})
+
+	if err := PublishTo(bus, testEvent{value: 1}); err != nil || reached {
+		t.Errorf("expected the event to stop without an error, got %v (reached: %v)", err, reached)
+	}
+	if err := PublishTo(bus, testEvent{value: 2}); err == nil || reached {
+		t.Errorf("expected the error of the handler, got %v (reached: %v)", err, reached)
+	}
+
+	// handlers may unsubscribe while the event is published
+	bus.Clear()
+	first := SubscribeTo(bus, func(testEvent) error {
+		sub.Unsubscribe()
+		return nil
+	}, WithPriority(1))
+	sub = SubscribeTo(bus, func(testEvent) error {
+		reached = true
+		return nil
+	})
+	PublishTo(bus, testEvent{})
+	if reached {
+		t.Errorf("an unsubscribed handler must not be called")
+	}
+	first.Unsubscribe()
+	if HasSubscribersTo[testEvent](bus) {
+		t.Errorf("expected no subscribers")
+	}
+}
+
+func TestDispatcherStopPropagation(t *testing.T) {
+	dispatcher := NewDispatcher()
+	calls := 0
+	dispatcher.Listen("test", func(a int) error {
+		calls++
+		return nil
+	})
+	dispatcher.Listen("test", func(a int) error {
+		calls++
+		return ErrStopPropagation
+	})
+	if err := dispatcher.Trigger("test", 1); err != nil || calls != 1 {
+		t.Errorf("expected the last listener to stop the event, got %v after %d calls", err, calls)
+	}
+}
+
+func TestPostAndDeliver(t *testing.T) {
+	bus := NewBus()
+	received := 0
+	SubscribeTo(bus, func(e testEvent) error {
+		received++
+		if e.value == 0 {
+			// posted by a handler, delivered with the next call
+			PostTo(bus, testEvent{value: 1})
+			return errors.New("logged, not returned")
+		}
+		return nil
+	})
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			PostTo(bus, testEvent{value: 2})
+		}()
+	}
+	wg.Wait()
+	PostTo(bus, testEvent{value: 0})
+
+	bus.SetDeliveryBudget(4)
+	if delivered := bus.Deliver(); delivered != 4 || received != 4 || bus.QueueLength() != 7 {
+		t.Fatalf("expected 4 of 11 events to be delivered, got %d (%d queued)", received, bus.QueueLength())
+	}
+	bus.SetDeliveryBudget(0)
+	if delivered := bus.Deliver(); delivered != 7 || received != 11 {
+		t.Fatalf("expected the remaining events to be delivered, got %d", received)
+	}
+	if bus.QueueLength() != 1 || bus.Deliver() != 1 || received != 12 {
+		t.Errorf("expected the event posted by the handler to be delivered with the next call")
+	}
+}
+
+func TestDispatcherListenInsideHandler(t *testing.T) {
+	dispatcher := NewDispatcher()
+	dispatcher.Listen("test", func() error {
+		// must not deadlock
+		dispatcher.Listen("other", func() error { return nil })
+		dispatcher.RemoveEvents("test")
+		return nil
+	})
+	dispatcher.Post("test")
+	if dispatcher.Deliver() != 1 || dispatcher.HasEvent("test") || !dispatcher.HasEvent("other") {
+		t.Errorf("expected the handler to replace the event")
+	}
+}
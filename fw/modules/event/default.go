@@ -3,6 +3,40 @@ package event
 // Default event dispatcher instance
 var defaultDispatcher = NewDispatcher()
 
+// Default event bus instance
+var defaultBus = NewBus()
+
+// Subscribe subscribes a handler for events of type T, see SubscribeTo.
+// (Uses the default bus)
+func Subscribe[T any](handler func(event T) error, options ...SubscribeOption) *Subscription {
+	return SubscribeTo(defaultBus, handler, options...)
+}
+
+// Publish calls the handlers of events of type T with the event, see
+// PublishTo.
+// (Uses the default bus)
+func Publish[T any](event T) error {
+	return PublishTo(defaultBus, event)
+}
+
+// Post queues an event of type T, to be published by DeliverQueued. It is safe
+// to call from any goroutine.
+// (Uses the default bus)
+func Post[T any](event T) {
+	PostTo(defaultBus, event)
+}
+
+// HasSubscribers returns true if a handler for events of type T is subscribed.
+// (Uses the default bus)
+func HasSubscribers[T any]() bool {
+	return HasSubscribersTo[T](defaultBus)
+}
+
+// DefaultBus returns the default bus, which the functions of the package use.
+func DefaultBus() *Bus {
+	return defaultBus
+}
+
 // Listen registers a new event listener to the given event name.
 // (Uses the default dispatcher)
 func Listen(name string, fn EventHandler) error {
@@ -15,6 +49,13 @@ func Trigger(name string, params ...interface{}) error {
 	return defaultDispatcher.Trigger(name, params...)
 }
 
+// PostNamed queues an event by name, to be triggered by DeliverQueued. It is
+// safe to call from any goroutine.
+// (Uses the default dispatcher)
+func PostNamed(name string, params ...interface{}) {
+	defaultDispatcher.Post(name, params...)
+}
+
 // HasEvent returns true if a event with the given name exists.
 // (Uses the default dispatcher)
 func HasEvent(name string) bool {
@@ -32,3 +73,18 @@ func ListEvents() []string {
 func RemoveEvents(names ...string) {
 	defaultDispatcher.RemoveEvents(names...)
 }
+
+// DeliverQueued delivers the events posted to the default bus and the default
+// dispatcher since the last call. The game loop calls it once per frame,
+// before the entities are updated.
+func DeliverQueued() {
+	defaultBus.Deliver()
+	defaultDispatcher.Deliver()
+}
+
+// SetDeliveryBudget sets the most events DeliverQueued delivers per frame,
+// for the default bus and the default dispatcher each. 0 means no limit.
+func SetDeliveryBudget(budget int) {
+	defaultBus.SetDeliveryBudget(budget)
+	defaultDispatcher.SetDeliveryBudget(budget)
+}
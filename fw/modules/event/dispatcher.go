@@ -12,12 +12,17 @@ type dispatcher struct {
 	sync.RWMutex
 
 	events map[string][]EventHandler
+	queue  queue
 }
 
 // EventHandler is a function that can be registered as an event listener.
-// It must be a function that returns an error and nothing else.
+// It must be a function that returns an error and nothing else. Returning
+// ErrStopPropagation stops the event from reaching the remaining listeners.
 type EventHandler any
 
+// Dispatcher dispatches events by name, checking the parameters of the
+// listeners at runtime. It is kept for compatibility, new code should use the
+// typed events of Bus, which are checked at compile time.
 type Dispatcher interface {
 	Listen(name string, fn EventHandler) error
 	Trigger(name string, params ...any) error
@@ -26,11 +31,20 @@ type Dispatcher interface {
 	RemoveEvents(names ...string)
 }
 
+// QueuedDispatcher is a Dispatcher that can also queue events, to trigger them
+// once per frame on the main thread, see PostTo.
+type QueuedDispatcher interface {
+	Dispatcher
+	Post(name string, params ...any)
+	Deliver() int
+	SetDeliveryBudget(budget int)
+}
+
 // NewDispatcher returns a new event dispatcher.
 //
 // Use this to create a custom dispatcher. Otherwise use the default dispatcher
 // by directly calling the functions on the event package.
-func NewDispatcher() Dispatcher {
+func NewDispatcher() QueuedDispatcher {
 	return &dispatcher{
 		events: make(map[string][]EventHandler),
 	}
@@ -68,22 +82,31 @@ func (e *dispatcher) Listen(name string, fn EventHandler) error {
 	return nil
 }
 
-// Trigger fires an event by name and passes the given parameters to the listeners
+// Trigger fires an event by name and passes the given parameters to the listeners.
+// The listeners are called last to first.
 func (e *dispatcher) Trigger(name string, params ...any) error {
+	// the listeners are called without holding the lock, so they may listen
+	// and trigger themselves
 	e.RLock()
-	defer e.RUnlock()
+	fns := append([]EventHandler{}, e.events[name]...)
+	e.RUnlock()
 
-	fns := e.events[name]
+	trace := beginTrace(name, params, len(fns))
 	for i := len(fns) - 1; i >= 0; i-- {
-		stopped, err := e.call(fns[i], params...)
+		listener := fns[i]
+		trace.called(listener)
+		stopped, err := e.call(listener, params...)
 		if err != nil {
+			trace.end(listener, err)
 			return err
 		}
 		if stopped {
-			break
+			trace.end(listener, nil)
+			return nil
 		}
 	}
 
+	trace.end(nil, nil)
 	return nil
 }
 
@@ -109,11 +132,7 @@ func (e *dispatcher) call(fn EventHandler, params ...any) (stopped bool, err err
 		}
 		in = append(in, s)
 
-		result := f.CallSlice(in)[0].Interface()
-		if err, ok := result.(error); ok && err != nil {
-			return stopped, err
-		}
-		return stopped, nil
+		return callResult(f.CallSlice(in))
 	}
 
 	if len(params) != numIn {
@@ -123,11 +142,17 @@ func (e *dispatcher) call(fn EventHandler, params ...any) (stopped bool, err err
 		in = append(in, reflect.ValueOf(param))
 	}
 
-	result := f.Call(in)[0].Interface()
-	if err, ok := result.(error); ok && err != nil {
-		return stopped, err
+	return callResult(f.Call(in))
+}
+
+// callResult converts the result of a listener. ErrStopPropagation stops the
+// event without an error.
+func callResult(results []reflect.Value) (stopped bool, err error) {
+	err, _ = results[0].Interface().(error)
+	if errors.Is(err, ErrStopPropagation) {
+		return true, nil
 	}
-	return stopped, nil
+	return false, err
 }
 
 // Helper function to list expected parameter types
@@ -143,11 +168,34 @@ func paramTypes(t reflect.Type, numIn int) []string {
 func receivedParamTypes(params []any) []string {
 	types := make([]string, len(params))
 	for i, p := range params {
+		if p == nil {
+			types[i] = "nil"
+			continue
+		}
 		types[i] = reflect.TypeOf(p).String()
 	}
 	return types
 }
 
+// Post queues an event, to be triggered by the next call of Deliver. It is
+// safe to call from any goroutine.
+func (e *dispatcher) Post(name string, params ...any) {
+	e.queue.post(func() error {
+		return e.Trigger(name, params...)
+	})
+}
+
+// Deliver triggers the events posted since the last call, see Bus.Deliver.
+func (e *dispatcher) Deliver() int {
+	return e.queue.deliver()
+}
+
+// SetDeliveryBudget sets the most events a call of Deliver delivers, 0 means
+// no limit.
+func (e *dispatcher) SetDeliveryBudget(budget int) {
+	e.queue.setBudget(budget)
+}
+
 // HasEvent returns true if an event with the given name exists .
 func (e *dispatcher) HasEvent(name string) bool {
 	e.RLock()
@@ -0,0 +1,66 @@
+package event
+
+import (
+	"gorl/fw/core/logging"
+	"sync"
+)
+
+// queue holds events posted from any goroutine until they are delivered on
+// the main thread, see Bus.Deliver.
+type queue struct {
+	mutex   sync.Mutex
+	pending []func() error
+	// budget is the most events delivered per call of deliver, 0 means no
+	// limit.
+	budget int
+}
+
+// post adds the delivery of an event to the queue.
+func (q *queue) post(deliver func() error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.pending = append(q.pending, deliver)
+}
+
+// deliver delivers the queued events in the order they were posted, at most
+// the budget. Events posted while delivering, e.g. by handlers, wait for the
+// next call. Errors of handlers are logged, since there is nobody to return
+// them to. Returns the number of delivered events.
+func (q *queue) deliver() int {
+	q.mutex.Lock()
+	count := len(q.pending)
+	if q.budget > 0 && count > q.budget {
+		count = q.budget
+	}
+	batch := q.pending[:count:count]
+	q.pending = q.pending[count:]
+	q.mutex.Unlock()
+
+	for _, deliver := range batch {
+		if err := deliver(); err != nil {
+			logging.Error("Failed to deliver queued event: %v", err)
+		}
+	}
+	return count
+}
+
+// setBudget sets the most events delivered per call of deliver.
+func (q *queue) setBudget(budget int) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.budget = budget
+}
+
+// length returns the number of queued events.
+func (q *queue) length() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return len(q.pending)
+}
+
+// clear drops the queued events.
+func (q *queue) clear() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.pending = nil
+}
@@ -0,0 +1,220 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"gorl/fw/core/clock"
+	"gorl/fw/core/datastructures"
+	"gorl/fw/core/logging"
+	"io"
+	"os"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// DefaultTraceCapacity is the number of events the tracer keeps if
+// EnableTracing is called with a capacity of 0.
+const DefaultTraceCapacity = 256
+
+// TraceEntry describes an event that was triggered or published while tracing
+// was enabled.
+type TraceEntry struct {
+	// Frame is the frame the event was delivered in, see clock.FrameCount.
+	Frame uint64 `json:"frame"`
+	// Name is the name of a named event, or the type of a typed event.
+	Name     string   `json:"name"`
+	ArgTypes []string `json:"argTypes"`
+	// Listeners are the listeners that were called, in order.
+	Listeners []string `json:"listeners"`
+	// StoppedBy is the listener that stopped the event, by returning an error
+	// or ErrStopPropagation.
+	StoppedBy string `json:"stoppedBy,omitempty"`
+	Error     string `json:"error,omitempty"`
+	// Duration is the time all listeners took, in nanoseconds in the dump.
+	Duration time.Duration `json:"duration"`
+}
+
+// tracer records the events of all dispatchers and buses.
+var tracer struct {
+	enabled atomic.Bool
+
+	sync.Mutex
+	entries *datastructures.RingBuffer[TraceEntry]
+	// warned holds the events that were already reported for having no
+	// listeners, to warn only once per event.
+	warned map[string]bool
+}
+
+// EnableTracing starts recording every event that is triggered or published,
+// keeping the last capacity events (DefaultTraceCapacity if 0). Events that
+// have no listeners are logged as a warning, once per event.
+//
+// Tracing costs time on every event, so it is meant for debugging.
+func EnableTracing(capacity int) {
+	if capacity <= 0 {
+		capacity = DefaultTraceCapacity
+	}
+	tracer.Lock()
+	defer tracer.Unlock()
+	tracer.entries = datastructures.NewRingBuffer[TraceEntry](capacity)
+	tracer.warned = map[string]bool{}
+	tracer.enabled.Store(true)
+}
+
+// DisableTracing stops recording events. The recorded events are kept, so
+// they can still be inspected.
+func DisableTracing() {
+	tracer.enabled.Store(false)
+}
+
+// IsTracing returns true while events are recorded.
+func IsTracing() bool {
+	return tracer.enabled.Load()
+}
+
+// GetTrace returns the recorded events, oldest first.
+func GetTrace() []TraceEntry {
+	tracer.Lock()
+	defer tracer.Unlock()
+	if tracer.entries == nil {
+		return []TraceEntry{}
+	}
+	return tracer.entries.Items()
+}
+
+// ClearTrace removes the recorded events.
+func ClearTrace() {
+	tracer.Lock()
+	defer tracer.Unlock()
+	if tracer.entries != nil {
+		tracer.entries.Clear()
+	}
+}
+
+// WriteTrace writes the recorded events as JSON to w.
+func WriteTrace(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "\t")
+	return encoder.Encode(GetTrace())
+}
+
+// DumpTrace writes the recorded events as JSON to a file at the given path.
+func DumpTrace(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteTrace(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// traceRecord is the entry of an event that is being delivered. All of its
+// methods can be called on nil, which is returned while tracing is disabled.
+type traceRecord struct {
+	entry TraceEntry
+	start time.Time
+}
+
+// beginTrace starts the entry of an event, or returns nil if tracing is
+// disabled. listeners is the number of listeners of the event.
+func beginTrace(name string, params []any, listeners int) *traceRecord {
+	if !tracer.enabled.Load() {
+		return nil
+	}
+	if listeners == 0 {
+		tracer.Lock()
+		if !tracer.warned[name] {
+			tracer.warned[name] = true
+			logging.Warning("Event \"%v\" was triggered, but has no listeners.", name)
+		}
+		tracer.Unlock()
+	}
+	return &traceRecord{
+		entry: TraceEntry{
+			Frame:     clock.FrameCount(),
+			Name:      name,
+			ArgTypes:  receivedParamTypes(params),
+			Listeners: make([]string, 0, listeners),
+		},
+		start: time.Now(),
+	}
+}
+
+// called records that the listener, a function or its name, was called.
+func (r *traceRecord) called(listener any) {
+	if r == nil {
+		return
+	}
+	r.entry.Listeners = append(r.entry.Listeners, funcName(listener))
+}
+
+// end finishes the entry. stoppedBy is the listener that stopped the event,
+// if any, err the error it returned.
+func (r *traceRecord) end(stoppedBy any, err error) {
+	if r == nil {
+		return
+	}
+	r.entry.Duration = time.Since(r.start)
+	if stoppedBy != nil {
+		r.entry.StoppedBy = funcName(stoppedBy)
+	}
+	if err != nil {
+		r.entry.Error = err.Error()
+	}
+
+	tracer.Lock()
+	defer tracer.Unlock()
+	if tracer.entries != nil {
+		tracer.entries.Push(r.entry)
+	}
+}
+
+// funcName returns the name of a function, e.g. "gorl/game.onPlayerDied".
+// Names are returned as they are.
+func funcName(fn any) string {
+	if name, ok := fn.(string); ok {
+		return name
+	}
+	value := reflect.ValueOf(fn)
+	if value.Kind() != reflect.Func {
+		return fmt.Sprint(fn)
+	}
+	if f := runtime.FuncForPC(value.Pointer()); f != nil {
+		return f.Name()
+	}
+	return value.Type().String()
+}
+
+// DebugDrawTrace draws the last count recorded events, newest at the top.
+// Events that failed are drawn red, events without listeners yellow.
+func DebugDrawTrace(position rl.Vector2, size int32, count int) {
+	rl.DrawText("Events:", int32(position.X), int32(position.Y), size, rl.Lime)
+	entries := GetTrace()
+	y := int32(position.Y) + size
+	for i := len(entries) - 1; i >= 0 && i >= len(entries)-count; i-- {
+		entry := entries[i]
+		line := fmt.Sprintf("%v  %v(%v)  %d listeners  %v",
+			entry.Frame, entry.Name, strings.Join(entry.ArgTypes, ", "), len(entry.Listeners), entry.Duration)
+		color := rl.Lime
+		switch {
+		case entry.Error != "":
+			line += "  error in " + entry.StoppedBy + ": " + entry.Error
+			color = rl.Red
+		case entry.StoppedBy != "":
+			line += "  stopped by " + entry.StoppedBy
+		case len(entry.Listeners) == 0:
+			color = rl.Yellow
+		}
+		rl.DrawText(line, int32(position.X)+size, y, size, color)
+		y += size
+	}
+}
@@ -0,0 +1,65 @@
+package event
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func stopHandler(testEvent) error { return ErrStopPropagation }
+
+func TestTrace(t *testing.T) {
+	EnableTracing(3)
+	t.Cleanup(func() {
+		DisableTracing()
+		ClearTrace()
+	})
+
+	bus := NewBus()
+	SubscribeTo(bus, stopHandler, WithPriority(1))
+	SubscribeTo(bus, func(testEvent) error { return nil })
+	PublishTo(bus, testEvent{})
+
+	dispatcher := NewDispatcher()
+	dispatcher.Listen("failing", func(a int, b string) error { return errors.New("failed") })
+	dispatcher.Trigger("failing", 1, "a")
+	dispatcher.Trigger("unheard")
+	dispatcher.Trigger("unheard")
+
+	// the capacity is 3, so the first trigger of "unheard" is the oldest
+	trace := GetTrace()
+	if len(trace) != 3 || trace[0].Name != "failing" || trace[1].Name != "unheard" {
+		t.Fatalf("expected the last 3 events, got %+v", trace)
+	}
+	failing := trace[0]
+	if !reflect.DeepEqual(failing.ArgTypes, []string{"int", "string"}) || failing.Error != "failed" ||
+		len(failing.Listeners) != 1 || failing.StoppedBy != failing.Listeners[0] {
+		t.Errorf("unexpected entry of a failing event: %+v", failing)
+	}
+
+	ClearTrace()
+	PublishTo(bus, testEvent{})
+	published := GetTrace()[0]
+	if published.Name != "event.testEvent" || len(published.Listeners) != 1 ||
+		!strings.HasSuffix(published.StoppedBy, ".stopHandler") || published.Error != "" {
+		t.Errorf("unexpected entry of a stopped event: %+v", published)
+	}
+
+	buffer := bytes.Buffer{}
+	if err := WriteTrace(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	dumped := []TraceEntry{}
+	if err := json.Unmarshal(buffer.Bytes(), &dumped); err != nil || len(dumped) != 1 || dumped[0].Name != published.Name {
+		t.Errorf("expected the dump to hold the trace, got %v (%v)", buffer.String(), err)
+	}
+
+	DisableTracing()
+	PublishTo(bus, testEvent{})
+	if len(GetTrace()) != 1 {
+		t.Errorf("events must not be recorded while tracing is disabled")
+	}
+}
@@ -0,0 +1,181 @@
+package scenes
+
+import (
+	"gorl/fw/core/assets"
+	"gorl/fw/core/logging"
+	"gorl/fw/core/resources"
+	"gorl/fw/modules/event"
+	"time"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// IPreloadScene is implemented by scenes that load assets before they are
+// initialized. The assets are loaded every time the scene is enabled, right
+// before its Init() is called.
+//
+// Example:
+//
+//	func (scn *GameScene) Assets() []scenes.Asset {
+//		return []scenes.Asset{
+//			scenes.TextureAsset("sprites/player.png", &scn.playerTexture),
+//			scenes.SoundAsset("audio/sfx/jump.wav", &scn.jumpSound),
+//		}
+//	}
+type IPreloadScene interface {
+	IScene
+	Assets() []Asset
+}
+
+// Asset is an asset a scene loads before it is initialized, see
+// IPreloadScene. The file is read in the background, the asset is then
+// created from the data on the main thread. Textures and sounds are tracked by
+// the resource scope of the scene, so they are unloaded when it is disabled.
+type Asset struct {
+	Path string
+	load func(data []byte) error
+}
+
+// TextureAsset loads the png file at the path into the texture.
+func TextureAsset(path string, texture *rl.Texture2D) Asset {
+	return Asset{Path: path, load: func(data []byte) (err error) {
+		*texture, err = assets.LoadTextureFromMemory(data)
+		return err
+	}}
+}
+
+// SoundAsset loads the sound file at the path into the sound.
+func SoundAsset(path string, sound *rl.Sound) Asset {
+	return Asset{Path: path, load: func(data []byte) (err error) {
+		*sound, err = assets.LoadSoundFromMemory(path, data)
+		return err
+	}}
+}
+
+// FileAsset reads the file at the path into data.
+func FileAsset(path string, data *[]byte) Asset {
+	return Asset{Path: path, load: func(read []byte) error {
+		*data = read
+		return nil
+	}}
+}
+
+// CustomAsset reads the file at the path, and passes its data to load on the
+// main thread.
+func CustomAsset(path string, load func(data []byte) error) Asset {
+	return Asset{Path: path, load: load}
+}
+
+// LoadProgress is published on the event bus while the assets of a scene
+// load, see event.Subscribe.
+type LoadProgress struct {
+	Scene  string
+	Loaded int
+	Total  int
+}
+
+// Progress returns the loaded part of the assets, from 0 to 1.
+func (p LoadProgress) Progress() float32 {
+	if p.Total == 0 {
+		return 1
+	}
+	return float32(p.Loaded) / float32(p.Total)
+}
+
+// readResult is the data of an asset file, read in the background.
+type readResult struct {
+	data []byte
+	err  error
+}
+
+// preloader loads the assets of a scene over several frames.
+type preloader struct {
+	scene  string
+	assets []Asset
+	// read receives the data of the assets in order.
+	read   chan readResult
+	loaded int
+	// scope is the new resource scope of the scene, which tracks the assets.
+	scope *resources.Scope
+}
+
+// loadBudget is the time per frame spent creating assets on the main thread.
+var loadBudget = 8 * time.Millisecond
+
+// SetLoadBudget sets the time per frame spent creating loaded assets on the
+// main thread, e.g. uploading textures. At least one asset is created per
+// frame. The default is 8ms.
+func SetLoadBudget(budget time.Duration) {
+	loadBudget = budget
+}
+
+// newPreloader starts reading the assets of the scene in the background.
+func newPreloader(name string, scene IScene) *preloader {
+	p := &preloader{scene: name, scope: newSceneScope(name)}
+	if preload, ok := scene.(IPreloadScene); ok {
+		p.assets = preload.Assets()
+	}
+
+	p.read = make(chan readResult, len(p.assets))
+	go func(assetList []Asset) {
+		for _, asset := range assetList {
+			data, err := assets.ReadFile(asset.Path)
+			p.read <- readResult{data: data, err: err}
+		}
+	}(p.assets)
+	return p
+}
+
+// update creates the assets that were read, within the load budget, and
+// returns true once all assets are loaded. If wait is true, it waits for all
+// assets instead.
+func (p *preloader) update(wait bool) bool {
+	previous := resources.SetCurrent(p.scope)
+	defer resources.SetCurrent(previous)
+
+	start := time.Now()
+	created := 0
+	for p.loaded < len(p.assets) {
+		if !wait && created > 0 && time.Since(start) >= loadBudget {
+			break
+		}
+		var result readResult
+		if wait {
+			result = <-p.read
+		} else {
+			select {
+			case result = <-p.read:
+			default:
+				p.publishProgress()
+				return false
+			}
+		}
+
+		asset := p.assets[p.loaded]
+		if result.err == nil {
+			result.err = asset.load(result.data)
+		}
+		if result.err != nil {
+			logging.Error("Failed to load asset \"%v\" of scene \"%v\": %v", asset.Path, p.scene, result.err)
+		}
+		p.loaded++
+		created++
+	}
+	p.publishProgress()
+	return p.loaded == len(p.assets)
+}
+
+// progress returns the loading progress.
+func (p *preloader) progress() LoadProgress {
+	return LoadProgress{Scene: p.scene, Loaded: p.loaded, Total: len(p.assets)}
+}
+
+// publishProgress publishes the loading progress on the event bus.
+func (p *preloader) publishProgress() {
+	if !event.HasSubscribers[LoadProgress]() {
+		return
+	}
+	if err := event.Publish(p.progress()); err != nil {
+		logging.Error("Failed to publish load progress: %v", err)
+	}
+}
@@ -3,12 +3,22 @@ package scenes
 import (
 	"gorl/fw/core/gem"
 	"gorl/fw/core/logging"
+	"gorl/fw/core/resources"
 	"gorl/fw/util"
 )
 
 type sceneManager struct {
 	scenes         map[string]IScene
 	enabled_scenes map[string]bool
+
+	// stack holds the scenes pushed with PushScene and PushOverlay, from the
+	// bottom to the top. operations are the pending changes of the stack.
+	stack      []*stackEntry
+	operations []*operation
+
+	// scopes holds the resource scopes of the enabled scenes, and of the
+	// scenes that are loading.
+	scopes map[string]*resources.Scope
 }
 
 // Create a new SceneManager. A SceneManager will automatically take care of
@@ -17,6 +27,7 @@ func newSceneManager() *sceneManager {
 	return &sceneManager{
 		scenes:         make(map[string]IScene),
 		enabled_scenes: make(map[string]bool),
+		scopes:         make(map[string]*resources.Scope),
 	}
 }
 
@@ -31,7 +42,9 @@ func RegisterScene(name string, scene IScene) {
 	sm.scenes[name] = scene
 }
 
-// Enable the Scene. The Scenes Init() function will be called.
+// Enable the Scene instantly. The assets of the Scene are loaded (see
+// IPreloadScene) and its Init() function will be called.
+// To switch scenes with a transition, use the scene stack, see PushScene.
 func EnableScene(name string) {
 	scene, exists := sm.scenes[name]
 	if !exists {
@@ -40,13 +53,18 @@ func EnableScene(name string) {
 
 	// Initialize the scene if it's not already enabled
 	if !sm.enabled_scenes[name] {
-		gem.Append(gem.GetRoot(), scene.GetRoot())
-		scene.Init()
-		sm.enabled_scenes[name] = true
+		var scope *resources.Scope
+		if _, ok := scene.(IPreloadScene); ok {
+			loader := newPreloader(name, scene)
+			loader.update(true)
+			scope = loader.scope
+		}
+		enableScene(name, scene, scope)
 	}
 }
 
-// Disable the Scene. The Scenes Deinit() function will be called.
+// Disable the Scene. The Scenes Deinit() function will be called. If the
+// Scene is on the scene stack, it is removed from it.
 func DisableScene(name string) {
 	scene, exists := sm.scenes[name]
 	if !exists {
@@ -55,21 +73,14 @@ func DisableScene(name string) {
 
 	// De-initialize the scene if it's currently enabled
 	if sm.enabled_scenes[name] {
-		scene.Deinit()
-		gem.Remove(scene.GetRoot())
-		sm.enabled_scenes[name] = false
+		removeFromStack(name)
+		disableScene(name, scene)
 	}
 }
 
 // Disable all Scenes that are currently enabled.
 func DisableAllScenes() {
-	for name, _ := range sm.scenes {
-		if sm.enabled_scenes[name] {
-			sm.scenes[name].Deinit()
-			gem.Remove(sm.scenes[name].GetRoot())
-			sm.enabled_scenes[name] = false
-		}
-	}
+	DisableAllScenesExcept(nil)
 }
 
 // Disable all Scenes that are currently enabled, except for the ones specified
@@ -77,8 +88,51 @@ func DisableAllScenes() {
 func DisableAllScenesExcept(exception_slice []string) {
 	for name, _ := range sm.scenes {
 		if sm.enabled_scenes[name] && !util.SliceContains(exception_slice, name) {
-			sm.scenes[name].Deinit()
-			sm.enabled_scenes[name] = false
+			removeFromStack(name)
+			disableScene(name, sm.scenes[name])
 		}
 	}
 }
+
+// GetResourceScope returns the resource scope of the enabled scene, or nil if
+// the scene is not enabled. See resources.Scope.
+func GetResourceScope(name string) *resources.Scope {
+	return sm.scopes[name]
+}
+
+// newSceneScope opens a resource scope for the scene. The resources the scene
+// creates while it is loading, initializing and running are tracked by the
+// scope, and released when the scene is disabled. Every time a scene is
+// enabled, it gets a new scope.
+func newSceneScope(name string) *resources.Scope {
+	return resources.NewScope("scene "+name, nil)
+}
+
+// enableScene adds the scene to the Gem graph and initializes it. scope is
+// the scope the assets of the scene were preloaded in, if nil a new scope is
+// opened.
+func enableScene(name string, scene IScene, scope *resources.Scope) {
+	if scope == nil {
+		scope = newSceneScope(name)
+	}
+	sm.scopes[name] = scope
+	gem.AppendWithScope(gem.GetRoot(), scene.GetRoot(), scope)
+
+	previous := resources.SetCurrent(scope)
+	scene.Init()
+	resources.SetCurrent(previous)
+	sm.enabled_scenes[name] = true
+}
+
+// disableScene de-initializes the scene and removes it from the Gem graph.
+// Its resources are released once the root of the scene is removed, after the
+// entities of the scene are de-initialized, see gem.Remove.
+func disableScene(name string, scene IScene) {
+	previous := resources.SetCurrent(sm.scopes[name])
+	scene.Deinit()
+	resources.SetCurrent(previous)
+
+	gem.Remove(scene.GetRoot())
+	delete(sm.scopes, name)
+	sm.enabled_scenes[name] = false
+}
@@ -0,0 +1,227 @@
+package scenes
+
+import (
+	"gorl/fw/core/clock"
+	"gorl/fw/core/entities"
+	"gorl/fw/core/gem"
+	"gorl/fw/core/resources"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+type testScene struct {
+	Scene
+	inits, deinits int
+}
+
+func (scn *testScene) Init()   { scn.inits++ }
+func (scn *testScene) Deinit() { scn.deinits++ }
+
+type preloadScene struct {
+	testScene
+	path string
+	data []byte
+	// dataAtInit is the data that was loaded when Init was called.
+	dataAtInit []byte
+}
+
+func (scn *preloadScene) Assets() []Asset {
+	return []Asset{FileAsset(scn.path, &scn.data)}
+}
+
+func (scn *preloadScene) Init() {
+	scn.testScene.Init()
+	scn.dataAtInit = scn.data
+}
+
+// setup resets the scene manager and the Gem graph.
+func setup(t *testing.T) {
+	sm = newSceneManager()
+	gem.Init()
+	clock.Reset()
+	t.Cleanup(func() {
+		gem.Deinit()
+		clock.Reset()
+	})
+}
+
+// frame advances the clock and updates the scenes.
+func frame(delta float32) {
+	clock.Advance(delta)
+	Update()
+}
+
+func TestSceneStack(t *testing.T) {
+	setup(t)
+	game, pause := &testScene{}, &testScene{}
+	RegisterScene("game", game)
+	RegisterScene("pause", pause)
+
+	PushScene("game", NoTransition)
+	frame(0)
+	if !reflect.DeepEqual(GetSceneStack(), []string{"game"}) || game.inits != 1 || IsTransitioning() {
+		t.Fatalf("expected the game to be pushed instantly, got %v", GetSceneStack())
+	}
+
+	// the overlay is pushed halfway through the fade
+	PushOverlay("pause", Fade(1))
+	frame(0.25)
+	if GetTopScene() != "game" || pause.inits != 0 {
+		t.Fatalf("the overlay must not be pushed before the screen is covered")
+	}
+	frame(0.25)
+	root := game.GetRoot()
+	if GetTopScene() != "pause" || pause.inits != 1 || !IsTransitioning() {
+		t.Fatalf("expected the overlay to be pushed, got %v", GetSceneStack())
+	}
+	if !root.IsEnabled() || !gem.IsFrozen(root) {
+		t.Errorf("the scene below an overlay must be visible but frozen")
+	}
+	frame(0.5)
+	if IsTransitioning() {
+		t.Errorf("the transition must end after its duration")
+	}
+
+	PopScene(NoTransition)
+	frame(0)
+	if GetTopScene() != "game" || pause.deinits != 1 || gem.IsFrozen(root) {
+		t.Errorf("expected the game to resume after the overlay was popped")
+	}
+
+	// a pushed scene hides the scenes below, replacing keeps them hidden
+	PushScene("pause", Wipe(0))
+	frame(0)
+	frame(0)
+	if root.IsEnabled() || IsTransitioning() {
+		t.Errorf("the scene below a pushed scene must be hidden")
+	}
+	ReplaceScene("pause", NoTransition)
+	frame(0)
+	if !reflect.DeepEqual(GetSceneStack(), []string{"game", "pause"}) || pause.inits != 3 || root.IsEnabled() {
+		t.Errorf("expected the scene to be replaced, got %v", GetSceneStack())
+	}
+
+	// a scene that replaces itself loads its assets again, and releases the
+	// old ones
+	path := filepath.Join(t.TempDir(), "level.txt")
+	os.WriteFile(path, []byte("level data"), 0644)
+	level := &resourceScene{path: path}
+	RegisterScene("level", level)
+	ReplaceScene("level", NoTransition)
+	waitForTransitions()
+	ReplaceScene("level", NoTransition)
+	waitForTransitions()
+	if level.inits != 2 || !reflect.DeepEqual(level.released, []string{"init", "asset"}) {
+		t.Fatalf("expected the old assets to be released, got %v", level.released)
+	}
+	if got := GetResourceScope("level").Resources(); len(got) != 2 || got[0].Name != "asset" {
+		t.Errorf("expected the assets to be loaded again before Init, got %v", got)
+	}
+}
+
+// waitForTransitions updates the scenes until the scene stack is changed.
+func waitForTransitions() {
+	for i := 0; i < 100 && IsTransitioning(); i++ {
+		frame(0)
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestScenePreload(t *testing.T) {
+	setup(t)
+	path := filepath.Join(t.TempDir(), "level.txt")
+	os.WriteFile(path, []byte("level data"), 0644)
+	level := &preloadScene{path: path}
+	RegisterScene("level", level)
+
+	PushScene("level", NoTransition)
+	waitForTransitions()
+	if string(level.dataAtInit) != "level data" || level.inits != 1 {
+		t.Fatalf("expected the assets to be loaded before Init, got %q", level.dataAtInit)
+	}
+
+	DisableScene("level")
+	level.data = nil
+	EnableScene("level")
+	if string(level.dataAtInit) != "level data" || len(GetSceneStack()) != 0 {
+		t.Errorf("expected EnableScene to load the assets, got %q", level.dataAtInit)
+	}
+}
+
+func TestDisableAllScenesExcept(t *testing.T) {
+	setup(t)
+	kept, disabled := &testScene{}, &testScene{}
+	RegisterScene("kept", kept)
+	RegisterScene("disabled", disabled)
+	EnableScene("kept")
+	EnableScene("disabled")
+
+	DisableAllScenesExcept([]string{"kept"})
+	children := gem.GetChildren(gem.GetRoot())
+	if disabled.deinits != 1 || slices.Contains(children, entities.IEntity(disabled.GetRoot())) {
+		t.Errorf("the root of a disabled scene must be removed from the graph")
+	}
+	if kept.deinits != 0 || !slices.Contains(children, entities.IEntity(kept.GetRoot())) {
+		t.Errorf("the excepted scene must stay enabled")
+	}
+}
+
+// resourceScene tracks a resource while loading and in Init.
+type resourceScene struct {
+	testScene
+	path     string
+	released []string
+}
+
+func (scn *resourceScene) track(name string) {
+	resources.Track("test", name, new(int), func() { scn.released = append(scn.released, name) })
+}
+
+func (scn *resourceScene) Assets() []Asset {
+	return []Asset{CustomAsset(scn.path, func(data []byte) error {
+		scn.track("asset")
+		return nil
+	})}
+}
+
+func (scn *resourceScene) Init() {
+	scn.testScene.Init()
+	scn.track("init")
+}
+
+func TestSceneResources(t *testing.T) {
+	setup(t)
+	path := filepath.Join(t.TempDir(), "level.txt")
+	os.WriteFile(path, []byte("level data"), 0644)
+	level := &resourceScene{path: path}
+	RegisterScene("level", level)
+
+	EnableScene("level")
+	scope := GetResourceScope("level")
+	if scope == nil || len(scope.Resources()) != 2 || gem.GetResourceScope(level.GetRoot()) != scope {
+		t.Fatalf("expected the scene to track its resources")
+	}
+
+	DisableScene("level")
+	if !reflect.DeepEqual(level.released, []string{"init", "asset"}) || GetResourceScope("level") != nil {
+		t.Errorf("expected the resources to be released on disable, got %v", level.released)
+	}
+
+	// disabled during a frame, the resources are released once the scene is
+	// removed from the graph
+	level.released = nil
+	EnableScene("level")
+	gem.Traverse(false)
+	DisableScene("level")
+	if len(level.released) != 0 {
+		t.Errorf("the resources were released before the scene was removed: %v", level.released)
+	}
+	gem.Sync()
+	if len(level.released) != 2 {
+		t.Errorf("expected the resources to be released at Sync, got %v", level.released)
+	}
+}
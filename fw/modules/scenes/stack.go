@@ -0,0 +1,297 @@
+package scenes
+
+import (
+	"gorl/fw/core/clock"
+	"gorl/fw/core/gem"
+	"gorl/fw/core/logging"
+	"gorl/fw/core/render"
+	"gorl/fw/core/resources"
+	"gorl/fw/physics"
+)
+
+// stackEntry is a scene on the scene stack.
+type stackEntry struct {
+	name    string
+	overlay bool
+	// frozen is true while the scene is below the top of the stack, see
+	// setFrozen.
+	frozen bool
+}
+
+// operationType is the change an operation makes to the scene stack.
+type operationType int
+
+const (
+	operationPush operationType = iota
+	operationPop
+	operationReplace
+)
+
+// operationPhase is the phase of a running operation.
+type operationPhase int
+
+const (
+	phaseCovering operationPhase = iota
+	phaseLoading
+	phaseUncovering
+)
+
+// operation is a change of the scene stack with its transition. Operations
+// run one after another, see Update.
+type operation struct {
+	kind    operationType
+	name    string
+	overlay bool
+	phase   operationPhase
+	effect  *transitionEffect
+	loader  *preloader
+	started bool
+}
+
+// PushScene covers the scene stack with the given scene. The scenes below
+// are hidden and frozen until the scene is popped. The scene is enabled
+// halfway through the transition, after its assets are loaded (see
+// IPreloadScene).
+func PushScene(name string, transition Transition) {
+	queueOperation(&operation{kind: operationPush, name: name}, transition)
+}
+
+// PushOverlay pushes the given scene on top of the scene stack, like
+// PushScene. The scenes below stay visible, but are frozen: they receive no
+// updates and no input until the overlay is popped, e.g. gameplay below a
+// pause menu.
+func PushOverlay(name string, transition Transition) {
+	queueOperation(&operation{kind: operationPush, name: name, overlay: true}, transition)
+}
+
+// PopScene disables the scene at the top of the scene stack, and resumes the
+// scene below.
+func PopScene(transition Transition) {
+	queueOperation(&operation{kind: operationPop}, transition)
+}
+
+// ReplaceScene disables the scene at the top of the scene stack and enables
+// the given scene in its place. The new scene is an overlay if the replaced
+// scene was one.
+func ReplaceScene(name string, transition Transition) {
+	queueOperation(&operation{kind: operationReplace, name: name}, transition)
+}
+
+// GetSceneStack returns the names of the scenes on the scene stack, from the
+// bottom to the top.
+func GetSceneStack() []string {
+	names := make([]string, len(sm.stack))
+	for i, entry := range sm.stack {
+		names[i] = entry.name
+	}
+	return names
+}
+
+// GetTopScene returns the name of the scene at the top of the scene stack,
+// or "" if the stack is empty.
+func GetTopScene() string {
+	if len(sm.stack) == 0 {
+		return ""
+	}
+	return sm.stack[len(sm.stack)-1].name
+}
+
+// IsTransitioning returns true while changes of the scene stack are running
+// or waiting.
+func IsTransitioning() bool {
+	return len(sm.operations) > 0
+}
+
+// GetLoadProgress returns the progress of the scene that is loading. The
+// second return value is false if no scene is loading.
+func GetLoadProgress() (LoadProgress, bool) {
+	if len(sm.operations) == 0 || sm.operations[0].phase != phaseLoading || sm.operations[0].loader == nil {
+		return LoadProgress{}, false
+	}
+	return sm.operations[0].loader.progress(), true
+}
+
+// Update runs the changes of the scene stack. The game loop calls it once per
+// frame, before the entities are updated.
+func Update() {
+	delta := clock.UnscaledDeltaTime()
+	for len(sm.operations) > 0 {
+		op := sm.operations[0]
+		if !op.started {
+			op.started = true
+			render.AddScreenEffect(op.effect)
+		}
+		if !op.step(delta) {
+			return
+		}
+		render.RemoveScreenEffect(op.effect)
+		sm.operations = sm.operations[1:]
+		// the next operation starts in the same frame, but its transition
+		// starts from the beginning.
+		delta = 0
+	}
+}
+
+// queueOperation validates the scene of the operation and queues it.
+func queueOperation(op *operation, transition Transition) {
+	if op.name != "" {
+		if _, exists := sm.scenes[op.name]; !exists {
+			logging.Fatal("Scene with name \"%v\" not found.", op.name)
+		}
+	}
+	op.effect = &transitionEffect{transition: transition, covering: true}
+	sm.operations = append(sm.operations, op)
+}
+
+// step advances the operation by a frame, and returns true once it is done.
+func (op *operation) step(delta float32) bool {
+	switch op.phase {
+	case phaseCovering:
+		if !op.effect.advance(delta) {
+			return false
+		}
+		op.phase = phaseLoading
+		if op.enablesScene() {
+			op.loader = newPreloader(op.name, sm.scenes[op.name])
+			op.effect.loader = op.loader
+		}
+		fallthrough
+
+	case phaseLoading:
+		if op.loader != nil && !op.loader.update(false) {
+			return false
+		}
+		op.effect.loader = nil
+		op.apply()
+		op.phase = phaseUncovering
+		op.effect.covering = false
+		// uncovering starts in the next frame, instant transitions end now
+		return op.effect.advance(0)
+
+	case phaseUncovering:
+		return op.effect.advance(delta)
+	}
+	return true
+}
+
+// enablesScene returns true if the operation enables its scene, so its assets
+// have to be loaded. A scene that replaces itself, e.g. to restart a level, is
+// enabled again.
+func (op *operation) enablesScene() bool {
+	switch op.kind {
+	case operationPush:
+		return !sm.enabled_scenes[op.name]
+	case operationReplace:
+		return !sm.enabled_scenes[op.name] || GetTopScene() == op.name
+	}
+	return false
+}
+
+// apply changes the scene stack.
+func (op *operation) apply() {
+	var scope *resources.Scope
+	if op.loader != nil {
+		scope = op.loader.scope
+	}
+
+	switch op.kind {
+	case operationPush:
+		pushScene(op.name, op.overlay, scope)
+	case operationPop:
+		if len(sm.stack) == 0 {
+			logging.Warning("Tried to pop a scene, but the scene stack is empty.")
+			return
+		}
+		popScene()
+	case operationReplace:
+		overlay := false
+		if len(sm.stack) > 0 {
+			overlay = sm.stack[len(sm.stack)-1].overlay
+			popScene()
+		}
+		pushScene(op.name, overlay, scope)
+	}
+	refreshStack()
+}
+
+// pushScene enables the scene and puts it on top of the stack. scope holds
+// the preloaded assets of the scene, if any.
+func pushScene(name string, overlay bool, scope *resources.Scope) {
+	if sm.enabled_scenes[name] {
+		logging.Warning("Tried to push scene \"%v\", but it is already enabled.", name)
+		if scope != nil {
+			scope.Close()
+		}
+		return
+	}
+	// the stack is refreshed first, so the scene below is frozen before the
+	// new scene initializes.
+	sm.stack = append(sm.stack, &stackEntry{name: name, overlay: overlay})
+	refreshStack()
+	enableScene(name, sm.scenes[name], scope)
+}
+
+// popScene disables the scene at the top of the stack.
+func popScene() {
+	entry := sm.stack[len(sm.stack)-1]
+	sm.stack = sm.stack[:len(sm.stack)-1]
+	releaseEntry(entry)
+	disableScene(entry.name, sm.scenes[entry.name])
+}
+
+// releaseEntry restores the root of a scene that leaves the stack, so it
+// starts out as usual the next time it is enabled.
+func releaseEntry(entry *stackEntry) {
+	root := sm.scenes[entry.name].GetRoot()
+	root.SetEnabled(true)
+	setFrozen(entry, false)
+}
+
+// removeFromStack removes the scene from the stack, if it is on it, e.g.
+// because it was disabled directly.
+func removeFromStack(name string) {
+	for i, entry := range sm.stack {
+		if entry.name == name {
+			sm.stack = append(sm.stack[:i], sm.stack[i+1:]...)
+			releaseEntry(entry)
+			refreshStack()
+			return
+		}
+	}
+}
+
+// refreshStack updates the scenes on the stack: the scene at the top is
+// processed, the scenes below are frozen. Scenes are visible as long as only
+// overlays are above them.
+func refreshStack() {
+	visible := true
+	for i := len(sm.stack) - 1; i >= 0; i-- {
+		entry := sm.stack[i]
+		root := sm.scenes[entry.name].GetRoot()
+		root.SetEnabled(visible)
+
+		setFrozen(entry, i != len(sm.stack)-1)
+
+		if !entry.overlay {
+			visible = false
+		}
+	}
+}
+
+// setFrozen freezes or unfreezes the scene. A frozen scene is not updated and
+// receives no input, whatever the process modes of its entities, and its
+// colliders are deactivated.
+func setFrozen(entry *stackEntry, frozen bool) {
+	if entry.frozen == frozen {
+		return
+	}
+	entry.frozen = frozen
+	gem.SetFrozen(sm.scenes[entry.name].GetRoot(), frozen)
+	if scope := sm.scopes[entry.name]; scope != nil {
+		if frozen {
+			physics.FreezeScope(scope)
+		} else {
+			physics.UnfreezeScope(scope)
+		}
+	}
+}
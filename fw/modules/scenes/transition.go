@@ -0,0 +1,103 @@
+package scenes
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// TransitionType is the effect of a Transition.
+type TransitionType int
+
+const (
+	// TransitionNone switches the scenes instantly.
+	TransitionNone TransitionType = iota
+	// TransitionFade fades the screen to the color and back.
+	TransitionFade
+	// TransitionWipe covers the screen with the color from left to right,
+	// and uncovers it in the same direction.
+	TransitionWipe
+)
+
+// Transition describes how the screen changes when the scene stack changes.
+// The screen is covered during the first half of the Duration, the scenes are
+// switched (and loaded, see IPreloadScene) while it is fully covered, and it
+// is uncovered during the second half.
+type Transition struct {
+	Type TransitionType
+	// Duration is the duration of the whole transition in seconds. It is not
+	// affected by the time scale.
+	Duration float32
+	Color    rl.Color
+}
+
+// NoTransition switches the scenes instantly.
+var NoTransition = Transition{}
+
+// Fade returns a transition that fades to black and back in the given time.
+func Fade(duration float32) Transition {
+	return Transition{Type: TransitionFade, Duration: duration, Color: rl.Black}
+}
+
+// Wipe returns a transition that wipes black over the screen and off again in
+// the given time.
+func Wipe(duration float32) Transition {
+	return Transition{Type: TransitionWipe, Duration: duration, Color: rl.Black}
+}
+
+// transitionEffect draws a running transition, see render.AddScreenEffect.
+type transitionEffect struct {
+	transition Transition
+	// cover is how much of the screen is covered, from 0 to 1.
+	cover float32
+	// covering is true while the screen is being covered, false while it is
+	// being uncovered.
+	covering bool
+	// loader loads the next scene while the screen is covered, its progress
+	// is drawn as a bar.
+	loader *preloader
+}
+
+// DrawScreenEffect implements render.ScreenEffect.
+func (t *transitionEffect) DrawScreenEffect(screenSize rl.Vector2) {
+	color := t.transition.Color
+	switch t.transition.Type {
+	case TransitionFade:
+		color.A = uint8(float32(color.A) * t.cover)
+		rl.DrawRectangleV(rl.Vector2Zero(), screenSize, color)
+	case TransitionWipe:
+		width := screenSize.X * t.cover
+		x := float32(0)
+		if !t.covering {
+			x = screenSize.X - width
+		}
+		rl.DrawRectangleV(rl.NewVector2(x, 0), rl.NewVector2(width, screenSize.Y), color)
+	}
+
+	if t.loader != nil && len(t.loader.assets) > 0 {
+		// a progress bar at the bottom of the screen
+		size := rl.NewVector2(screenSize.X/3, 8)
+		position := rl.NewVector2((screenSize.X-size.X)/2, screenSize.Y-4*size.Y)
+		rl.DrawRectangleLinesEx(rl.NewRectangle(position.X-2, position.Y-2, size.X+4, size.Y+4), 1, rl.RayWhite)
+		rl.DrawRectangleV(position, rl.NewVector2(size.X*t.loader.progress().Progress(), size.Y), rl.RayWhite)
+	}
+}
+
+// advance moves the transition on by the given time, and returns true once
+// the screen is fully covered (while covering) or uncovered.
+func (t *transitionEffect) advance(delta float32) bool {
+	half := t.transition.Duration / 2
+	if t.transition.Type == TransitionNone || half <= 0 {
+		if t.covering {
+			t.cover = 1
+		} else {
+			t.cover = 0
+		}
+		return true
+	}
+
+	if t.covering {
+		t.cover = min(t.cover+delta/half, 1)
+		return t.cover == 1
+	}
+	t.cover = max(t.cover-delta/half, 0)
+	return t.cover == 0
+}
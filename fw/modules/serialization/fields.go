@@ -0,0 +1,84 @@
+package serialization
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// fieldTag is the struct tag used to rename or skip entity fields.
+// `scene:"-"` skips a field, `scene:"other_name"` renames it in the file.
+const fieldTag = "scene"
+
+// serializableFields returns the serializable fields of the struct behind the
+// given entity pointer, keyed by their name in the scene file.
+func serializableFields(entity any) map[string]reflect.Value {
+	fields := make(map[string]reflect.Value)
+
+	v := reflect.ValueOf(entity)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return fields
+	}
+	v = v.Elem()
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		// embedded structs (like *entities.Entity) are covered by the base
+		// properties, unexported fields are private state.
+		if f.Anonymous || !f.IsExported() {
+			continue
+		}
+		// references and behaviour can't be described in a file.
+		switch f.Type.Kind() {
+		case reflect.Pointer, reflect.Interface, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+			continue
+		}
+		name := f.Name
+		if tag, ok := f.Tag.Lookup(fieldTag); ok {
+			if tag == "-" {
+				continue
+			}
+			if tag != "" {
+				name = tag
+			}
+		}
+		fields[name] = v.Field(i)
+	}
+	return fields
+}
+
+// readFields encodes the serializable fields of the entity.
+func readFields(entity any) (map[string]json.RawMessage, error) {
+	fields := serializableFields(entity)
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	out := make(map[string]json.RawMessage, len(fields))
+	for name, value := range fields {
+		data, err := json.Marshal(value.Interface())
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode field %q: %w", name, err)
+		}
+		out[name] = data
+	}
+	return out, nil
+}
+
+// applyFields decodes the given field values into the entity.
+func applyFields(fields map[string]json.RawMessage, entity any) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	targets := serializableFields(entity)
+	for name, data := range fields {
+		target, ok := targets[name]
+		if !ok {
+			return fmt.Errorf("unknown field %q on type %T", name, entity)
+		}
+		if err := json.Unmarshal(data, target.Addr().Interface()); err != nil {
+			return fmt.Errorf("failed to decode field %q: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package serialization
+
+import (
+	"encoding/json"
+	"gorl/fw/core/entities"
+	"gorl/fw/core/math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// FormatVersion is the version of the scene file format written by this
+// module. Files with a newer version are rejected when loading.
+const FormatVersion = 1
+
+// SceneFile is the top level structure of a scene file.
+type SceneFile struct {
+	Version int      `json:"version"`
+	Root    NodeData `json:"root"`
+}
+
+// NodeData is the declarative description of a single entity and its subtree.
+//
+// A node with Prefab set and Type empty is a prefab reference: it is replaced
+// by the content of the named prefab, with Overrides applied on top. All other
+// properties of a reference node are ignored, its Children are appended to the
+// instance root.
+type NodeData struct {
+	Type       string     `json:"type,omitempty"`
+	Name       string     `json:"name"`
+	Position   rl.Vector2 `json:"position"`
+	Rotation   float32    `json:"rotation"`
+	Scale      rl.Vector2 `json:"scale"`
+	DrawIndex  int32      `json:"drawIndex"`
+	LayerFlags int64      `json:"layerFlags"`
+	Enabled    bool       `json:"enabled"`
+	Visible    bool       `json:"visible"`
+	Tags       []string   `json:"tags,omitempty"`
+
+	// ProcessMode, VisibilityMode and LayerMode hold the entities.ProcessMode,
+	// entities.VisibilityMode and entities.LayerMode of the entity.
+	ProcessMode    int `json:"processMode,omitempty"`
+	VisibilityMode int `json:"visibilityMode,omitempty"`
+	LayerMode      int `json:"layerMode,omitempty"`
+
+	// Fields holds the exported fields of the concrete entity type, keyed by
+	// field name. See the README for which fields are serialized.
+	Fields map[string]json.RawMessage `json:"fields,omitempty"`
+
+	Children []NodeData `json:"children,omitempty"`
+
+	// Prefab is the name of the prefab this node is an instance of.
+	Prefab    string     `json:"prefab,omitempty"`
+	Overrides []Override `json:"overrides,omitempty"`
+}
+
+// MarshalJSON writes prefab references without the ignored base properties.
+func (node NodeData) MarshalJSON() ([]byte, error) {
+	type plainNode NodeData // avoids recursing into MarshalJSON
+	if !node.isPrefabReference() {
+		return json.Marshal(plainNode(node))
+	}
+	return json.Marshal(struct {
+		Prefab    string     `json:"prefab"`
+		Overrides []Override `json:"overrides,omitempty"`
+		Children  []NodeData `json:"children,omitempty"`
+	}{node.Prefab, node.Overrides, node.Children})
+}
+
+// UnmarshalJSON fills in the defaults of entities.NewEntity for properties
+// that are missing in the file, so hand written files can stay short.
+func (node *NodeData) UnmarshalJSON(data []byte) error {
+	type plainNode NodeData // avoids recursing into UnmarshalJSON
+	decoded := plainNode{
+		Scale:      rl.Vector2One(),
+		LayerFlags: math.Flag0.ToInt64(),
+		Enabled:    true,
+		Visible:    true,
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*node = NodeData(decoded)
+	return nil
+}
+
+// isPrefabReference returns true if the node still has to be resolved from
+// its prefab.
+func (node NodeData) isPrefabReference() bool {
+	return node.Prefab != "" && node.Type == ""
+}
+
+// configurable is implemented by every entity embedding entities.Entity. It
+// provides the setters that are not part of entities.IEntity.
+type configurable interface {
+	SetName(name string)
+	SetEnabled(enabled bool)
+	SetVisible(visible bool)
+	SetLayerFlags(flags math.BitFlag)
+	AddTag(tag string)
+	SetProcessMode(mode entities.ProcessMode)
+	SetVisibilityMode(mode entities.VisibilityMode)
+	SetLayerMode(mode entities.LayerMode)
+	GetPrefab() string
+	SetPrefab(name string)
+}
+
+// readBaseProperties copies the base properties of the entity into the node.
+func readBaseProperties(entity entities.IEntity, node *NodeData) {
+	node.Name = entity.GetName()
+	node.Position = entity.GetPosition()
+	node.Rotation = entity.GetRotation()
+	node.Scale = entity.GetScale()
+	node.DrawIndex = entity.GetDrawIndex()
+	node.LayerFlags = entity.GetLayerFlags().ToInt64()
+	node.Enabled = entity.IsEnabled()
+	node.Visible = entity.IsVisible()
+	node.Tags = entity.GetTags()
+	node.ProcessMode = int(entity.GetProcessMode())
+	node.VisibilityMode = int(entity.GetVisibilityMode())
+	node.LayerMode = int(entity.GetLayerMode())
+}
+
+// applyBaseProperties copies the base properties of the node onto the entity.
+func applyBaseProperties(node NodeData, entity entities.IEntity) {
+	entity.SetPosition(node.Position)
+	entity.SetRotation(node.Rotation)
+	entity.SetScale(node.Scale)
+	entity.SetDrawIndex(node.DrawIndex)
+	if c, ok := entity.(configurable); ok {
+		c.SetName(node.Name)
+		c.SetEnabled(node.Enabled)
+		c.SetVisible(node.Visible)
+		c.SetLayerFlags(math.FromInt64(node.LayerFlags))
+		c.SetProcessMode(entities.ProcessMode(node.ProcessMode))
+		c.SetVisibilityMode(entities.VisibilityMode(node.VisibilityMode))
+		c.SetLayerMode(entities.LayerMode(node.LayerMode))
+		for _, tag := range node.Tags {
+			c.AddTag(tag)
+		}
+		c.SetPrefab(node.Prefab)
+	}
+}
@@ -0,0 +1,421 @@
+package serialization
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gorl/fw/core/assets"
+	"gorl/fw/core/entities"
+	"gorl/fw/core/gem"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// PrefabFile is the top level structure of a prefab file.
+type PrefabFile struct {
+	Version int      `json:"version"`
+	Name    string   `json:"name"`
+	Root    NodeData `json:"root"`
+}
+
+// Override changes a single node inside a prefab instance.
+type Override struct {
+	// Path is the path of the node relative to the instance root, built from
+	// entity names separated by "/". The empty path is the instance root.
+	// If siblings share a name, the first one is used.
+	Path string `json:"path"`
+
+	// Properties replaces properties of the node. Keys are the property names
+	// used in NodeData, e.g. "position" or "enabled". Exported entity fields
+	// are set through the "fields" key and are merged field by field.
+	Properties map[string]json.RawMessage `json:"properties,omitempty"`
+
+	// Added is appended to the children of the node.
+	Added []NodeData `json:"added,omitempty"`
+
+	// Removed removes the node (and its subtree) from the instance.
+	Removed bool `json:"removed,omitempty"`
+}
+
+// prefabs holds all registered prefabs by name.
+var prefabs = make(map[string]NodeData)
+
+// RegisterPrefab registers a prefab under the given name. An existing prefab
+// with the same name is replaced, instances that already exist keep their
+// content.
+func RegisterPrefab(name string, root NodeData) {
+	prefabs[name] = root
+}
+
+// HasPrefab returns true if a prefab with the given name is registered.
+func HasPrefab(name string) bool {
+	_, ok := prefabs[name]
+	return ok
+}
+
+// LoadPrefab loads and registers the prefab file at path. If the file does not
+// specify a name, the file name without the ".prefab.json" extension is used.
+// Returns the name the prefab was registered under.
+func LoadPrefab(path string) (string, error) {
+	file, err := assets.LoadFile(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return "", err
+	}
+
+	var prefab PrefabFile
+	if err := json.Unmarshal(data, &prefab); err != nil {
+		return "", err
+	}
+	if prefab.Version > FormatVersion {
+		return "", fmt.Errorf("unsupported prefab file version %d (supported up to %d)", prefab.Version, FormatVersion)
+	}
+	if prefab.Name == "" {
+		prefab.Name = strings.TrimSuffix(filepath.Base(path), ".prefab.json")
+	}
+
+	RegisterPrefab(prefab.Name, prefab.Root)
+	return prefab.Name, nil
+}
+
+// InstantiatePrefab creates an instance of the named prefab with the given
+// overrides applied, and appends it to parent in the Gem graph.
+// The instance root remembers the prefab it was created from, see Diff.
+func InstantiatePrefab(parent entities.IEntity, name string, overrides ...Override) (entities.IEntity, error) {
+	return Instantiate(parent, NodeData{Prefab: name, Overrides: overrides})
+}
+
+// Diff compares a prefab instance in the Gem graph with the prefab it was
+// created from and returns the overrides that turn the prefab into the
+// instance's current state.
+func Diff(entity entities.IEntity) ([]Override, error) {
+	name := prefabOf(entity)
+	if name == "" {
+		return nil, fmt.Errorf("entity %q is not a prefab instance", entity.GetName())
+	}
+	source, err := resolve(NodeData{Prefab: name}, map[string]bool{})
+	if err != nil {
+		return nil, err
+	}
+
+	overrides := []Override{}
+	if err := diffNode(source, entity, "", &overrides); err != nil {
+		return nil, err
+	}
+	return overrides, nil
+}
+
+// prefabOf returns the name of the prefab the entity was created from.
+func prefabOf(entity entities.IEntity) string {
+	if c, ok := entity.(configurable); ok {
+		return c.GetPrefab()
+	}
+	return ""
+}
+
+// ============================================================================
+// Resolving
+// ============================================================================
+
+// resolve replaces all prefab references in the node's subtree with the
+// content of the referenced prefab. visiting holds the prefabs whose source is
+// currently being expanded, to detect cycles. The children an instance adds to
+// its prefab are not part of the source, so they may contain further
+// instances of the same prefab.
+func resolve(node NodeData, visiting map[string]bool) (NodeData, error) {
+	if node.isPrefabReference() {
+		name := node.Prefab
+		if visiting[name] {
+			return NodeData{}, fmt.Errorf("prefab %q references itself", name)
+		}
+		source, ok := prefabs[name]
+		if !ok {
+			return NodeData{}, fmt.Errorf("prefab %q is not registered", name)
+		}
+		visiting[name] = true
+		expanded, err := resolve(cloneNode(source), visiting)
+		delete(visiting, name)
+		if err != nil {
+			return NodeData{}, err
+		}
+		expanded.Prefab = name
+		for _, override := range node.Overrides {
+			if err := applyOverride(&expanded, override); err != nil {
+				return NodeData{}, fmt.Errorf("prefab %q: %w", name, err)
+			}
+		}
+		expanded.Children = append(expanded.Children, node.Children...)
+		node = expanded
+	}
+
+	for i := range node.Children {
+		child, err := resolve(node.Children[i], visiting)
+		if err != nil {
+			return NodeData{}, err
+		}
+		node.Children[i] = child
+	}
+	return node, nil
+}
+
+// applyOverride applies a single override to the resolved tree below root.
+func applyOverride(root *NodeData, override Override) error {
+	target, parent, index := findNode(root, override.Path)
+	if target == nil {
+		return fmt.Errorf("override path %q not found", override.Path)
+	}
+
+	if override.Removed {
+		if parent == nil {
+			return fmt.Errorf("the instance root can't be removed")
+		}
+		parent.Children = append(parent.Children[:index], parent.Children[index+1:]...)
+		return nil
+	}
+
+	if err := applyProperties(target, override.Properties); err != nil {
+		return fmt.Errorf("override path %q: %w", override.Path, err)
+	}
+	target.Children = append(target.Children, override.Added...)
+	return nil
+}
+
+// applyProperties replaces the given properties of the node.
+func applyProperties(node *NodeData, properties map[string]json.RawMessage) error {
+	if len(properties) == 0 {
+		return nil
+	}
+
+	values, err := toPropertyMap(*node)
+	if err != nil {
+		return err
+	}
+	for key, value := range properties {
+		switch key {
+		case "fields":
+			var fields map[string]json.RawMessage
+			if err := json.Unmarshal(value, &fields); err != nil {
+				return fmt.Errorf("invalid fields override: %w", err)
+			}
+			if node.Fields == nil {
+				node.Fields = make(map[string]json.RawMessage)
+			}
+			for name, field := range fields {
+				node.Fields[name] = field
+			}
+		default:
+			if _, ok := values[key]; !ok {
+				return fmt.Errorf("property %q can't be overridden", key)
+			}
+			values[key] = value
+		}
+	}
+
+	data, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	updated := NodeData{}
+	if err := json.Unmarshal(data, &updated); err != nil {
+		return err
+	}
+	updated.Type = node.Type
+	updated.Fields = node.Fields
+	updated.Children = node.Children
+	updated.Prefab = node.Prefab
+	*node = updated
+	return nil
+}
+
+// findNode finds the node at the given path below root. Returns the node, its
+// parent and its index in the parent's children, or nil if the path does not
+// exist.
+func findNode(root *NodeData, path string) (node *NodeData, parent *NodeData, index int) {
+	node = root
+	if path == "" {
+		return node, nil, 0
+	}
+	for _, name := range strings.Split(path, "/") {
+		found := false
+		for i := range node.Children {
+			if node.Children[i].Name == name {
+				parent, index, node = node, i, &node.Children[i]
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, nil, 0
+		}
+	}
+	return node, parent, index
+}
+
+// ============================================================================
+// Diffing
+// ============================================================================
+
+// diffNode compares the entity with the resolved prefab node and appends the
+// necessary overrides.
+func diffNode(source NodeData, entity entities.IEntity, path string, out *[]Override) error {
+	current, err := encodeNode(entity)
+	if err != nil {
+		return err
+	}
+	if current.Type != source.Type {
+		return fmt.Errorf("node %q changed type from %q to %q", path, source.Type, current.Type)
+	}
+
+	override := Override{Path: path}
+	override.Properties, err = diffProperties(source, current)
+	if err != nil {
+		return err
+	}
+
+	used := make([]bool, len(source.Children))
+	for _, child := range gem.GetChildren(entity) {
+		match := -1
+		for i, sourceChild := range source.Children {
+			if !used[i] && sourceChild.Name == child.GetName() {
+				match = i
+				break
+			}
+		}
+		if match < 0 {
+			added, err := Encode(child)
+			if err != nil {
+				return err
+			}
+			override.Added = append(override.Added, added)
+			continue
+		}
+		used[match] = true
+		if err := diffNode(source.Children[match], child, joinPath(path, child.GetName()), out); err != nil {
+			return err
+		}
+	}
+
+	if len(override.Properties) > 0 || len(override.Added) > 0 {
+		*out = append(*out, override)
+	}
+	for i, sourceChild := range source.Children {
+		if !used[i] {
+			*out = append(*out, Override{Path: joinPath(path, sourceChild.Name), Removed: true})
+		}
+	}
+	return nil
+}
+
+// diffProperties returns the properties of current that differ from source.
+func diffProperties(source, current NodeData) (map[string]json.RawMessage, error) {
+	sourceValues, err := toPropertyMap(source)
+	if err != nil {
+		return nil, err
+	}
+	currentValues, err := toPropertyMap(current)
+	if err != nil {
+		return nil, err
+	}
+
+	properties := make(map[string]json.RawMessage)
+	for key, value := range currentValues {
+		if !jsonEqual(sourceValues[key], value) {
+			properties[key] = value
+		}
+	}
+
+	fields := make(map[string]json.RawMessage)
+	for name, value := range current.Fields {
+		if !jsonEqual(source.Fields[name], value) {
+			fields[name] = value
+		}
+	}
+	if len(fields) > 0 {
+		data, err := json.Marshal(fields)
+		if err != nil {
+			return nil, err
+		}
+		properties["fields"] = data
+	}
+
+	if len(properties) == 0 {
+		return nil, nil
+	}
+	return properties, nil
+}
+
+// toPropertyMap returns the overridable properties of the node as raw JSON
+// values keyed by property name.
+func toPropertyMap(node NodeData) (map[string]json.RawMessage, error) {
+	node.Type = ""
+	node.Fields = nil
+	node.Children = nil
+	node.Prefab = ""
+	node.Overrides = nil
+
+	data, err := json.Marshal(node)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+	// these are omitted when empty, but can still be overridden.
+	for key, empty := range omittedProperties {
+		if _, ok := values[key]; !ok {
+			values[key] = json.RawMessage(empty)
+		}
+	}
+	return values, nil
+}
+
+// omittedProperties are the overridable properties that are omitted from
+// NodeData's JSON when empty, with their empty value.
+var omittedProperties = map[string]string{
+	"tags":           "[]",
+	"processMode":    "0",
+	"visibilityMode": "0",
+	"layerMode":      "0",
+}
+
+// jsonEqual compares two JSON values independent of formatting.
+func jsonEqual(a, b json.RawMessage) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	var va, vb any
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return bytes.Equal(a, b)
+	}
+	ca, _ := json.Marshal(va)
+	cb, _ := json.Marshal(vb)
+	return bytes.Equal(ca, cb)
+}
+
+// joinPath appends a node name to a path.
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "/" + name
+}
+
+// cloneNode returns a deep copy of the node.
+func cloneNode(node NodeData) NodeData {
+	data, err := json.Marshal(node)
+	if err != nil {
+		return node
+	}
+	var clone NodeData
+	if err := json.Unmarshal(data, &clone); err != nil {
+		return node
+	}
+	return clone
+}
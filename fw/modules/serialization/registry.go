@@ -0,0 +1,75 @@
+package serialization
+
+import (
+	"gorl/fw/core/entities"
+	"gorl/fw/core/logging"
+	"reflect"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// EntityFactory creates a fresh instance of a registered entity type. The
+// returned entity is configured from the scene file afterwards, so the
+// factory only has to provide sensible defaults.
+type EntityFactory func() entities.IEntity
+
+// registry maps type names to factories and concrete types back to names.
+type registry struct {
+	factories map[string]EntityFactory
+	names     map[reflect.Type]string
+}
+
+// typeRegistry is the global entity type registry.
+var typeRegistry = &registry{
+	factories: make(map[string]EntityFactory),
+	names:     make(map[reflect.Type]string),
+}
+
+func init() {
+	// The base entity is used for plain grouping nodes such as scene roots, so
+	// it is always available.
+	Register("Entity", func() *entities.Entity {
+		return entities.NewEntity("Entity", rl.Vector2Zero(), 0, rl.Vector2One())
+	})
+}
+
+// Register makes an entity type known to the serialization module under the
+// given type name. The name is what is written to scene files, so it should
+// not change once scene files referencing it exist.
+//
+// Example:
+//
+//	serialization.Register("PlayerEntity", func() *PlayerEntity {
+//		return NewPlayerEntity(rl.Vector2Zero(), 0, rl.Vector2One())
+//	})
+func Register[T entities.IEntity](typeName string, factory func() T) {
+	if _, exists := typeRegistry.factories[typeName]; exists {
+		logging.Fatal("An entity type with name \"%v\" is already registered.", typeName)
+	}
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	typeRegistry.factories[typeName] = func() entities.IEntity { return factory() }
+	typeRegistry.names[t] = typeName
+}
+
+// IsRegistered returns true if an entity type with the given name is known.
+func IsRegistered(typeName string) bool {
+	_, ok := typeRegistry.factories[typeName]
+	return ok
+}
+
+// TypeNameOf returns the registered type name of the given entity.
+// The second return value is false if the entity's type is not registered.
+func TypeNameOf(entity entities.IEntity) (string, bool) {
+	name, ok := typeRegistry.names[reflect.TypeOf(entity)]
+	return name, ok
+}
+
+// newEntityOfType creates a new entity using the factory registered for the
+// given type name.
+func newEntityOfType(typeName string) (entities.IEntity, bool) {
+	factory, ok := typeRegistry.factories[typeName]
+	if !ok {
+		return nil, false
+	}
+	return factory(), true
+}
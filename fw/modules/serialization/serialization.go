@@ -0,0 +1,158 @@
+package serialization
+
+import (
+	"encoding/json"
+	"fmt"
+	"gorl/fw/core/assets"
+	"gorl/fw/core/entities"
+	"gorl/fw/core/gem"
+	"io"
+	"os"
+)
+
+// ============================================================================
+// Saving
+// ============================================================================
+
+// Encode describes the given entity and its whole subtree in the Gem graph as
+// a NodeData. Every entity in the subtree must be of a registered type.
+//
+// Prefab instances are written as a reference to their prefab plus the
+// overrides returned by Diff. If the instance can't be described that way
+// (for example because a node changed its type), it is written out in full.
+func Encode(entity entities.IEntity) (NodeData, error) {
+	if name := prefabOf(entity); name != "" && HasPrefab(name) {
+		if overrides, err := Diff(entity); err == nil {
+			return NodeData{Prefab: name, Overrides: overrides}, nil
+		}
+	}
+
+	node, err := encodeNode(entity)
+	if err != nil {
+		return NodeData{}, err
+	}
+
+	for _, child := range gem.GetChildren(entity) {
+		childNode, err := Encode(child)
+		if err != nil {
+			return NodeData{}, err
+		}
+		node.Children = append(node.Children, childNode)
+	}
+
+	return node, nil
+}
+
+// encodeNode describes a single entity as a NodeData, without its children.
+func encodeNode(entity entities.IEntity) (NodeData, error) {
+	typeName, ok := TypeNameOf(entity)
+	if !ok {
+		return NodeData{}, fmt.Errorf("entity %q has unregistered type %T", entity.GetName(), entity)
+	}
+
+	node := NodeData{Type: typeName, Prefab: prefabOf(entity)}
+	readBaseProperties(entity, &node)
+
+	fields, err := readFields(entity)
+	if err != nil {
+		return NodeData{}, fmt.Errorf("entity %q: %w", entity.GetName(), err)
+	}
+	node.Fields = fields
+	return node, nil
+}
+
+// Marshal encodes the subtree of the given entity into the scene file format.
+func Marshal(entity entities.IEntity) ([]byte, error) {
+	root, err := Encode(entity)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(SceneFile{Version: FormatVersion, Root: root}, "", "    ")
+}
+
+// SaveFile writes the subtree of the given entity to a scene file at path.
+func SaveFile(entity entities.IEntity, path string) error {
+	data, err := Marshal(entity)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// ============================================================================
+// Loading
+// ============================================================================
+
+// Instantiate creates the entity described by node, including its subtree,
+// and appends it to the given parent in the Gem graph. Prefab references in
+// the subtree are resolved first.
+// Properties and fields are applied before the entity is appended, so they
+// are already set when Init() runs.
+func Instantiate(parent entities.IEntity, node NodeData) (entities.IEntity, error) {
+	resolved, err := resolve(node, map[string]bool{})
+	if err != nil {
+		return nil, err
+	}
+	return instantiate(parent, resolved)
+}
+
+// instantiate creates the already resolved subtree described by node.
+func instantiate(parent entities.IEntity, node NodeData) (entities.IEntity, error) {
+	entity, err := build(node)
+	if err != nil {
+		return nil, err
+	}
+	gem.Append(parent, entity)
+
+	for _, childNode := range node.Children {
+		if _, err := instantiate(entity, childNode); err != nil {
+			// don't leave a half built subtree behind.
+			gem.Remove(entity)
+			return nil, err
+		}
+	}
+	return entity, nil
+}
+
+// build creates a single configured entity from the node, without children.
+func build(node NodeData) (entities.IEntity, error) {
+	entity, ok := newEntityOfType(node.Type)
+	if !ok {
+		return nil, fmt.Errorf("node %q has unregistered type %q", node.Name, node.Type)
+	}
+	applyBaseProperties(node, entity)
+	if err := applyFields(node.Fields, entity); err != nil {
+		return nil, fmt.Errorf("node %q: %w", node.Name, err)
+	}
+	return entity, nil
+}
+
+// Unmarshal decodes a scene file and instantiates its content under parent.
+// Returns the root entity of the instantiated subtree.
+func Unmarshal(parent entities.IEntity, data []byte) (entities.IEntity, error) {
+	var file SceneFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	if file.Version > FormatVersion {
+		return nil, fmt.Errorf("unsupported scene file version %d (supported up to %d)", file.Version, FormatVersion)
+	}
+	return Instantiate(parent, file.Root)
+}
+
+// LoadFile loads the scene file at path and instantiates its content under
+// parent. The file is read through the assets module, so packfiles are
+// supported.
+func LoadFile(parent entities.IEntity, path string) (entities.IEntity, error) {
+	file, err := assets.LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	return Unmarshal(parent, data)
+}
@@ -0,0 +1,208 @@
+package serialization
+
+import (
+	"encoding/json"
+	"gorl/fw/core/entities"
+	"gorl/fw/core/gem"
+	"gorl/fw/core/math"
+	"reflect"
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+type testEntity struct {
+	*entities.Entity
+	Health  int
+	Speed   float32
+	Label   string `scene:"label"`
+	Skipped string `scene:"-"`
+}
+
+func newTestEntity() *testEntity {
+	return &testEntity{
+		Entity: entities.NewEntity("testEntity", rl.Vector2Zero(), 0, rl.Vector2One()),
+		Health: 10,
+	}
+}
+
+func init() {
+	Register("testEntity", newTestEntity)
+}
+
+func buildTestTree() entities.IEntity {
+	level := entities.NewEntity("level", rl.NewVector2(10, 20), 0, rl.Vector2One())
+	gem.Append(gem.GetRoot(), level)
+
+	player := newTestEntity()
+	player.SetName("player")
+	player.SetPosition(rl.NewVector2(5, 6))
+	player.SetRotation(45)
+	player.SetScale(rl.NewVector2(2, 3))
+	player.SetDrawIndex(4)
+	player.SetLayerFlags(math.Flag2 | math.Flag5)
+	player.SetVisible(false)
+	player.Health = 99
+	player.Speed = 1.5
+	player.Label = "hero"
+	player.Skipped = "not saved"
+	player.AddTag("player")
+	gem.Append(level, player)
+
+	weapon := newTestEntity()
+	weapon.SetName("weapon")
+	weapon.SetEnabled(false)
+	gem.Append(player, weapon)
+
+	return level
+}
+
+func TestRoundTrip(t *testing.T) {
+	gem.Init()
+	level := buildTestTree()
+
+	data, err := Marshal(level)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	loaded, err := Unmarshal(gem.GetRoot(), data)
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	want, _ := Encode(level)
+	got, err := Encode(loaded)
+	if err != nil {
+		t.Fatalf("Encode of loaded subtree failed: %v", err)
+	}
+	if !reflect.DeepEqual(normalize(t, want), normalize(t, got)) {
+		t.Errorf("round trip mismatch.\nwant: %+v\ngot:  %+v", want, got)
+	}
+
+	player := gem.GetChildren(loaded)[0].(*testEntity)
+	if player.Health != 99 || player.Label != "hero" || player.Skipped != "" {
+		t.Errorf("fields not restored: %+v", player)
+	}
+	if !player.HasTag("player") {
+		t.Errorf("tags not restored")
+	}
+	if player.IsVisible() || gem.GetChildren(player)[0].IsEnabled() {
+		t.Errorf("enabled/visible not restored")
+	}
+}
+
+func TestUnknownFieldIsRejected(t *testing.T) {
+	gem.Init()
+	data := []byte(`{"version":1,"root":{"type":"testEntity","name":"x","fields":{"Nope":1}}}`)
+	if _, err := Unmarshal(gem.GetRoot(), data); err == nil {
+		t.Errorf("expected an error for an unknown field")
+	}
+	if len(gem.GetChildren(gem.GetRoot())) != 0 {
+		t.Errorf("failed load must not leave entities behind")
+	}
+}
+
+func TestUnregisteredTypeIsRejected(t *testing.T) {
+	gem.Init()
+	data := []byte(`{"version":1,"root":{"type":"Entity","name":"root","children":[{"type":"DoesNotExist"}]}}`)
+	if _, err := Unmarshal(gem.GetRoot(), data); err == nil {
+		t.Errorf("expected an error for an unregistered type")
+	}
+	if len(gem.GetChildren(gem.GetRoot())) != 0 {
+		t.Errorf("failed load must not leave entities behind")
+	}
+}
+
+// normalize makes NodeData comparable by re-encoding the raw field values.
+func normalize(t *testing.T, node NodeData) NodeData {
+	for name, raw := range node.Fields {
+		var v any
+		if err := json.Unmarshal(raw, &v); err != nil {
+			t.Fatal(err)
+		}
+		out, _ := json.Marshal(v)
+		node.Fields[name] = out
+	}
+	for i := range node.Children {
+		node.Children[i] = normalize(t, node.Children[i])
+	}
+	return node
+}
+
+func TestPrefabInstantiateAndDiff(t *testing.T) {
+	gem.Init()
+	RegisterPrefab("weapon", NodeData{
+		Type: "testEntity", Name: "weapon", Scale: rl.Vector2One(), Enabled: true, Visible: true,
+		Fields: map[string]json.RawMessage{"Health": []byte("1")},
+	})
+	RegisterPrefab("grunt", NodeData{
+		Type: "testEntity", Name: "grunt", Scale: rl.Vector2One(), Enabled: true, Visible: true,
+		Fields: map[string]json.RawMessage{"Health": []byte("50"), "label": []byte(`"grunt"`)},
+		Children: []NodeData{
+			{Prefab: "weapon"},
+			{Type: "Entity", Name: "eyes", Scale: rl.Vector2One(), Enabled: true, Visible: true},
+		},
+	})
+
+	grunt, err := InstantiatePrefab(gem.GetRoot(), "grunt", Override{
+		Path:       "weapon",
+		Properties: map[string]json.RawMessage{"fields": []byte(`{"Health": 7}`)},
+	})
+	if err != nil {
+		t.Fatalf("InstantiatePrefab failed: %v", err)
+	}
+	if grunt.(*testEntity).GetPrefab() != "grunt" {
+		t.Errorf("instance root does not remember its prefab")
+	}
+	weapon := gem.GetChildren(grunt)[0].(*testEntity)
+	if weapon.GetPrefab() != "weapon" || weapon.Health != 7 {
+		t.Errorf("nested prefab not instantiated correctly: %+v", weapon)
+	}
+
+	// modify the instance and check that the diff captures exactly that.
+	grunt.SetPosition(rl.NewVector2(100, 0))
+	gem.Remove(gem.GetChildren(grunt)[1])
+	overrides, err := Diff(grunt)
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if len(overrides) != 3 {
+		t.Fatalf("expected 3 overrides (weapon field, root position, removed eyes), got %+v", overrides)
+	}
+
+	// a saved and reloaded instance must be identical to the original.
+	data, err := Marshal(grunt)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	loaded, err := Unmarshal(gem.GetRoot(), data)
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if loaded.GetPosition() != rl.NewVector2(100, 0) || len(gem.GetChildren(loaded)) != 1 {
+		t.Errorf("reloaded instance differs from the original")
+	}
+	if gem.GetChildren(loaded)[0].(*testEntity).Health != 7 {
+		t.Errorf("nested override lost on reload")
+	}
+}
+
+func TestPrefabCycleIsRejected(t *testing.T) {
+	gem.Init()
+	RegisterPrefab("a", NodeData{Type: "Entity", Name: "a", Children: []NodeData{{Prefab: "b"}}})
+	RegisterPrefab("b", NodeData{Type: "Entity", Name: "b", Children: []NodeData{{Prefab: "a"}}})
+	if _, err := InstantiatePrefab(gem.GetRoot(), "a"); err == nil {
+		t.Errorf("expected an error for a prefab cycle")
+	}
+
+	// an instance may nest another instance of its own prefab
+	RegisterPrefab("box", NodeData{Type: "Entity", Name: "box", Scale: rl.Vector2One(), Enabled: true, Visible: true})
+	outer, err := Instantiate(gem.GetRoot(), NodeData{Prefab: "box", Children: []NodeData{{Prefab: "box"}}})
+	if err != nil {
+		t.Fatalf("nested instance of the same prefab was rejected: %v", err)
+	}
+	if children := gem.GetChildren(outer); len(children) != 1 || prefabOf(children[0]) != "box" {
+		t.Errorf("expected a nested box instance, got %v", children)
+	}
+}
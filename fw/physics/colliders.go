@@ -1,7 +1,9 @@
 package physics
 
 import (
+	"gorl/fw/core/clock"
 	"gorl/fw/core/logging"
+	"gorl/fw/core/resources"
 
 	"github.com/ByteArena/box2d"
 	rl "github.com/gen2brain/raylib-go/raylib"
@@ -28,6 +30,10 @@ const (
 type Collider struct {
 	body      *box2d.B2Body
 	callbacks map[CollisionCategory]CollisionCallback
+
+	// previousPosition is the position before the last physics step, used
+	// for interpolation.
+	previousPosition rl.Vector2
 }
 
 func newCollider(body *box2d.B2Body) *Collider {
@@ -38,6 +44,11 @@ func newCollider(body *box2d.B2Body) *Collider {
 
 	// link the collider to the body, so we can retrieve it on collisions
 	c.body.SetUserData(c)
+	c.previousPosition = c.GetPosition()
+
+	// the collider is destroyed with the current resource scope, e.g. when
+	// the scene that created it is disabled.
+	resources.Track("collider", "", c, func() { destroyCollider(c) })
 
 	return c
 }
@@ -59,9 +70,19 @@ func (c *Collider) GetPosition() rl.Vector2 {
 	return v
 }
 
+// GetInterpolatedPosition returns the position of the collider interpolated
+// between the last two physics steps using clock.Alpha(). Drawing at this
+// position instead of GetPosition() avoids stutter when the frame rate does
+// not match the physics timestep. The result lags up to one step behind.
+func (c *Collider) GetInterpolatedPosition() rl.Vector2 {
+	return rl.Vector2Lerp(c.previousPosition, c.GetPosition(), clock.Alpha())
+}
+
 // SetPosition sets the position of the given collider. This may cause
 // unexpected behavior if the collider is a dynamic body.
+// The collider is teleported, its position is not interpolated.
 func (c *Collider) SetPosition(position rl.Vector2) {
+	c.previousPosition = position
 	position = pixelToSimulationScaleV(position)
 	c.GetB2Body().SetTransform(box2d.MakeB2Vec2(float64(position.X), float64(position.Y)), c.GetB2Body().GetAngle())
 }
@@ -160,9 +181,47 @@ func (col *Collider) SetIsSensor(is_sensor bool) *Collider {
 
 // DestroyCollider removes the given collider from the physics world.
 func DestroyCollider(collider *Collider) {
+	resources.Forget(collider)
+	destroyCollider(collider)
+}
+
+func destroyCollider(collider *Collider) {
 	State.destructionQueue = append(State.destructionQueue, collider.GetB2Body())
 }
 
+// frozenColliders holds the colliders deactivated by FreezeScope, by scope.
+var frozenColliders = map[*resources.Scope][]*Collider{}
+
+// FreezeScope deactivates the colliders tracked by the resource scope and the
+// scopes below it, e.g. of a scene below a pause menu. They neither move nor
+// collide until UnfreezeScope is called. Colliders that are already inactive
+// are left as they are.
+func FreezeScope(scope *resources.Scope) {
+	if _, ok := frozenColliders[scope]; ok {
+		return
+	}
+	colliders := []*Collider{}
+	for _, key := range scope.Keys("collider") {
+		collider := key.(*Collider)
+		if collider.body.IsActive() {
+			collider.body.SetActive(false)
+			colliders = append(colliders, collider)
+		}
+	}
+	frozenColliders[scope] = colliders
+}
+
+// UnfreezeScope activates the colliders that were deactivated by FreezeScope
+// again, unless they were destroyed in the meantime.
+func UnfreezeScope(scope *resources.Scope) {
+	for _, collider := range frozenColliders[scope] {
+		if resources.IsTracked(collider) {
+			collider.body.SetActive(true)
+		}
+	}
+	delete(frozenColliders, scope)
+}
+
 // ---------------------
 // CHAIN SHAPE COLLIDER
 // ---------------------
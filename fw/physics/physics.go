@@ -1,7 +1,9 @@
 package physics
 
 import (
+	"gorl/fw/core/clock"
 	"gorl/fw/core/logging"
+	"gorl/fw/core/resources"
 	"gorl/fw/util"
 
 	"github.com/ByteArena/box2d"
@@ -16,7 +18,6 @@ type PhysicsState struct {
 	timestep           float64
 	velocityIterations int
 	positionIterations int
-	updateTimer        util.Timer
 
 	physicsWorld     box2d.B2World
 	destructionQueue []*box2d.B2Body
@@ -47,25 +48,52 @@ func InitPhysics(timestep float32, gravity rl.Vector2, simulationScale float32)
 		timestep:           float64(timestep),
 		velocityIterations: 8,
 		positionIterations: 3,
-		updateTimer:        *util.NewTimer(timestep),
 		physicsWorld:       box2d.MakeB2World(box2d.MakeB2Vec2(float64(gravity.X), float64(gravity.Y))),
 		simulationScale:    float64(simulationScale),
 	}
 
 	State.physicsWorld.SetContactListener(&ContactListener{})
+	clock.SetFixedDeltaTime(timestep)
 }
 
 // DeinitPhysics deinitializes the physics state
 func DeinitPhysics() {
+	// the colliders are destroyed with the world, so they are not tracked
+	// anymore.
+	for body := State.physicsWorld.GetBodyList(); body != nil; body = body.GetNext() {
+		if collider, ok := body.GetUserData().(*Collider); ok {
+			resources.Forget(collider)
+		}
+	}
 	State.physicsWorld.Destroy()
 }
 
-// Update the physics world. This must be called every frame, the fixed
-// timestep is managed internally.
-// Returns true if the physics world was updated, false otherwise.
+// Update steps the physics world as often as the fixed steps of the clock
+// allow (see clock.StepFixed), and returns true if it was stepped at least
+// once.
+//
+// Deprecated: Update consumes the fixed steps of the clock, so FixedUpdate of
+// the entities can't run in lockstep. Call Step and gem.FixedTraverse for
+// every clock.StepFixed in the main loop instead.
 func Update() bool {
-	if !State.updateTimer.Check() {
-		return false
+	stepped := false
+	for clock.StepFixed() {
+		Step()
+		stepped = true
+	}
+	return stepped
+}
+
+// Step advances the physics world by exactly one timestep, regardless of the
+// time that has passed. The main loop calls it once per fixed step of the
+// clock, see clock.StepFixed.
+func Step() {
+	// remember where the bodies were before the step, so their positions can
+	// be interpolated when drawing.
+	for body := State.physicsWorld.GetBodyList(); body != nil; body = body.GetNext() {
+		if collider, ok := body.GetUserData().(*Collider); ok {
+			collider.previousPosition = collider.GetPosition()
+		}
 	}
 
 	State.physicsWorld.Step(State.timestep, State.velocityIterations, State.positionIterations)
@@ -78,7 +106,6 @@ func Update() bool {
 		State.physicsWorld.DestroyBody(body)
 	}
 	State.destructionQueue = []*box2d.B2Body{}
-	return true
 }
 
 // ------------------
@@ -1,13 +1,15 @@
 package util
 
 import (
+	"gorl/fw/core/clock"
 	"sync"
-	"time"
 )
 
+// TimeGate is open for a fixed duration of game time after it was started, as
+// measured by the clock package.
 type TimeGate struct {
-	duration  time.Duration
-	startTime time.Time
+	duration  float64
+	startTime float64
 	active    bool
 	mu        sync.Mutex
 }
@@ -15,7 +17,7 @@ type TimeGate struct {
 // NewTimeGate creates a new TimeGate with the given duration (in seconds).
 func NewTimeGate(duration float32) *TimeGate {
 	return &TimeGate{
-		duration: time.Duration(duration * float32(time.Second)),
+		duration: float64(duration),
 	}
 }
 
@@ -24,7 +26,7 @@ func (g *TimeGate) Start() {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
-	g.startTime = time.Now()
+	g.startTime = clock.TotalTime()
 	g.active = true
 }
 
@@ -37,7 +39,7 @@ func (g *TimeGate) Check() bool {
 		return false
 	}
 
-	elapsed := time.Now().Sub(g.startTime)
+	elapsed := clock.TotalTime() - g.startTime
 
 	if elapsed >= g.duration {
 		g.active = false
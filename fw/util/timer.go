@@ -1,15 +1,17 @@
 package util
 
 import (
+	"gorl/fw/core/clock"
 	"sync"
-	"time"
 )
 
+// Timer fires in a fixed interval of game time, as measured by the clock
+// package. It follows the global time scale, so it slows down in slow motion.
 type Timer struct {
-	interval time.Duration
-	lastTime time.Time
-	pauseDur time.Duration
-	pausedAt time.Time
+	interval float64
+	lastTime float64
+	pauseDur float64
+	pausedAt float64
 	mu       sync.Mutex
 	paused   bool
 }
@@ -17,8 +19,8 @@ type Timer struct {
 // New creates a new Timer with the given interval.
 func NewTimer(interval float32) *Timer {
 	return &Timer{
-		interval: time.Duration(interval * float32(time.Second)),
-		lastTime: time.Now(),
+		interval: float64(interval),
+		lastTime: clock.TotalTime(),
 	}
 }
 
@@ -32,8 +34,8 @@ func (t *Timer) Check() bool {
 		return false
 	}
 
-	now := time.Now()
-	elapsed := now.Sub(t.lastTime) - t.pauseDur
+	now := clock.TotalTime()
+	elapsed := now - t.lastTime - t.pauseDur
 
 	if elapsed >= t.interval {
 		t.lastTime = now
@@ -50,7 +52,7 @@ func (t *Timer) Pause() {
 	defer t.mu.Unlock()
 
 	if !t.paused {
-		t.pausedAt = time.Now()
+		t.pausedAt = clock.TotalTime()
 		t.paused = true
 	}
 }
@@ -61,7 +63,7 @@ func (t *Timer) Continue() {
 	defer t.mu.Unlock()
 
 	if t.paused {
-		t.pauseDur += time.Now().Sub(t.pausedAt)
+		t.pauseDur += clock.TotalTime() - t.pausedAt
 		t.paused = false
 	}
 }
@@ -71,7 +73,7 @@ func (t *Timer) ResetTime() {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
-	t.lastTime = time.Now()
+	t.lastTime = clock.TotalTime()
 	t.pauseDur = 0
 	if t.paused {
 		t.pausedAt = t.lastTime
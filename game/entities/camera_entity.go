@@ -1,6 +1,7 @@
 package entities
 
 import (
+	"gorl/fw/core/clock"
 	"gorl/fw/core/datastructures"
 	"gorl/fw/core/entities"
 	"gorl/fw/core/gem"
@@ -113,7 +114,7 @@ func (ent *CameraEntity) Update() {
 
 	// 1. Update the camera shake effect and apply it to the transformation buffer.
 	var decay float32 = 1.0
-	ent.shakeTrauma = util.Clamp(ent.shakeTrauma-rl.GetFrameTime()*decay, 0, 1)
+	ent.shakeTrauma = util.Clamp(ent.shakeTrauma-clock.DeltaTime()*decay, 0, 1)
 	shake := math.Pow(ent.shakeTrauma, 2)
 	const maxShakeAngleDeg = 5.7
 	const maxShakeOffset = 10
@@ -127,7 +128,8 @@ func (ent *CameraEntity) Update() {
 
 	// 2. Split the offset into integer and fractional parts.
 	// And apply the fractional part to the pixel smoothing shader.
-	pos := ent.GetPosition()
+	absTransform := gem.GetGlobalTransform(ent)
+	pos := absTransform.GetPosition()
 	var posX, posY float64 = float64(pos.X), float64(pos.Y)
 	if ent.isPixelSmoothed {
 		var subpixelXFrac, subpixelYFrac float64
@@ -143,7 +145,6 @@ func (ent *CameraEntity) Update() {
 	}
 
 	// 3. Apply the absolute transform of the camera entity to the render camera.
-	absTransform := gem.GetAbsoluteTransform(ent)
 	ent.ctb.Position = datastructures.NewMaybe(rl.NewVector2(float32(posX), float32(posY)))
 	ent.ctb.Offset = datastructures.NewMaybe(ent.offset)
 	ent.ctb.Rotation = datastructures.NewMaybe(absTransform.GetRotation())
@@ -167,23 +168,15 @@ func (ent *CameraEntity) OnInputEvent(event *input.InputEvent) bool {
 	const moveSpeed = 10
 	const zoomSpeed = 0.3
 
-	if event.Action == input.ActionMoveLeft {
-		ent.SetPosition(rl.Vector2Add(ent.GetPosition(), rl.NewVector2(-moveSpeed*rl.GetFrameTime(), 0)))
-	}
-	if event.Action == input.ActionMoveRight {
-		ent.SetPosition(rl.Vector2Add(ent.GetPosition(), rl.NewVector2(moveSpeed*rl.GetFrameTime(), 0)))
-	}
-	if event.Action == input.ActionMoveUp {
-		ent.SetPosition(rl.Vector2Add(ent.GetPosition(), rl.NewVector2(0, -moveSpeed*rl.GetFrameTime())))
-	}
-	if event.Action == input.ActionMoveDown {
-		ent.SetPosition(rl.Vector2Add(ent.GetPosition(), rl.NewVector2(0, moveSpeed*rl.GetFrameTime())))
+	if event.Action == input.ActionMove {
+		// the movement vector is normalized, so diagonal movement is not faster
+		ent.SetPosition(rl.Vector2Add(ent.GetPosition(), rl.Vector2Scale(event.Vector, moveSpeed*clock.DeltaTime())))
 	}
 	if event.Action == input.ActionZoomIn {
-		ent.SetScale(rl.NewVector2(ent.GetScale().X+zoomSpeed*rl.GetFrameTime(), 1))
+		ent.SetScale(rl.NewVector2(ent.GetScale().X+zoomSpeed*clock.DeltaTime(), 1))
 	}
 	if event.Action == input.ActionZoomOut {
-		ent.SetScale(rl.NewVector2(ent.GetScale().X-zoomSpeed*rl.GetFrameTime(), 1))
+		ent.SetScale(rl.NewVector2(ent.GetScale().X-zoomSpeed*clock.DeltaTime(), 1))
 	}
 	if event.Action == input.ActionClickDown {
 		ent.shakeTrauma = math.Clamp(ent.shakeTrauma+0.3, 0, 1)
@@ -12,14 +12,16 @@ import (
 var (
 	entityTemplateFile string
 	sceneTemplateFile  string
+	prefabTemplateFile string
 	entitiesOutputDir  string
 	scenesOutputDir    string
+	prefabsOutputDir   string
 )
 
 // createCmd represents the create command
 var createCmd = &cobra.Command{
 	Use:   "create [some_name_in_snake_case]",
-	Short: "Create a new entity or scene from a template",
+	Short: "Create a new entity, scene or prefab from a template",
 	Long:  `Create a new file based on a template and provided name, placing it in the appropriate directory.`,
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
@@ -28,8 +30,10 @@ var createCmd = &cobra.Command{
 			processTemplate(someName, entityTemplateFile, entitiesOutputDir)
 		} else if cmd.Flags().Changed("scene") {
 			processTemplate(someName, sceneTemplateFile, scenesOutputDir)
+		} else if cmd.Flags().Changed("prefab") {
+			processTemplate(someName, prefabTemplateFile, prefabsOutputDir)
 		} else {
-			fmt.Println("Please specify either --entity or --scene")
+			fmt.Println("Please specify either --entity, --scene or --prefab")
 			os.Exit(1)
 		}
 	},
@@ -43,14 +47,19 @@ func init() {
 		"fw/core/entities/entity.template", "Template file for entity")
 	createCmd.Flags().StringVar(&sceneTemplateFile, "scene-template",
 		"fw/modules/scenes/scene.template", "Template file for scene")
+	createCmd.Flags().StringVar(&prefabTemplateFile, "prefab-template",
+		"fw/modules/serialization/prefab.template", "Template file for prefab")
 	createCmd.Flags().StringVar(&entitiesOutputDir, "entities-output-dir",
 		"game/entities", "Output directory for entities")
 	createCmd.Flags().StringVar(&scenesOutputDir, "scenes-output-dir",
 		"game/scenes", "Output directory for scenes")
+	createCmd.Flags().StringVar(&prefabsOutputDir, "prefabs-output-dir",
+		"assets/prefabs", "Output directory for prefabs")
 
 	// Define flags for the type of file to create
 	createCmd.Flags().BoolP("entity", "e", false, "Create an entity with the given name")
 	createCmd.Flags().BoolP("scene", "s", false, "Create a scene with the given name")
+	createCmd.Flags().BoolP("prefab", "p", false, "Create a prefab with the given name")
 }
 
 func processTemplate(someName, templateFilePath, outputDir string) {
@@ -98,6 +107,8 @@ func processTemplate(someName, templateFilePath, outputDir string) {
 	newFilePath := filepath.Join(outputDir, fmt.Sprintf("%s_entity.go", someName))
 	if strings.Contains(templateFilePath, "scene.template") {
 		newFilePath = filepath.Join(outputDir, fmt.Sprintf("%s_scene.go", someName))
+	} else if strings.Contains(templateFilePath, "prefab.template") {
+		newFilePath = filepath.Join(outputDir, fmt.Sprintf("%s.prefab.json", someName))
 	}
 
 	// Check if the file already exists
@@ -107,6 +118,11 @@ func processTemplate(someName, templateFilePath, outputDir string) {
 	}
 
 	// Write the new content to the new file
+	err = os.MkdirAll(outputDir, os.ModePerm)
+	if err != nil {
+		fmt.Printf("Error creating output directory: %v\n", err)
+		os.Exit(1)
+	}
 	err = os.WriteFile(newFilePath, []byte(newContent), 0644)
 	if err != nil {
 		fmt.Printf("Error writing new file: %v\n", err)